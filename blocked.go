@@ -0,0 +1,19 @@
+package main
+
+import (
+	"io"
+
+	"github.com/liamg/grace/blocked"
+	"github.com/liamg/grace/tracer"
+)
+
+// configureBlocked sets up a report of where each thread spent its time blocked, which is printed when the tracer
+// detaches
+func configureBlocked(t *tracer.Tracer, w io.Writer) {
+	r := blocked.New(w)
+	r.SetMaxRows(flagBlockedRows)
+	t.AddSyscallExitHandler(r.HandleSyscallExit)
+	t.AddDetachHandler(func(int) {
+		r.Print()
+	})
+}
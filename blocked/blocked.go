@@ -0,0 +1,266 @@
+package blocked
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/liamg/grace/futex"
+	"github.com/liamg/grace/iodump"
+	"github.com/liamg/grace/iostat"
+	"github.com/liamg/grace/tracer"
+)
+
+// Report attributes the time each thread spent blocked in syscalls to the resource it was waiting for - a file,
+// socket endpoint, futex, child process or timer - and prints a ranked report of them when the trace ends
+type Report struct {
+	w        io.Writer
+	resolver *iostat.Resolver
+	threads  map[int]*Thread
+	epolls   map[epollKey]map[int]string // name of each fd registered with an epoll instance
+	maxRows  int
+}
+
+// Thread is a thread which was blocked in syscalls
+type Thread struct {
+	Pid   int
+	Tid   int
+	Comm  string
+	Waits map[string]*Wait // by resource
+}
+
+// Wait is the time a thread spent blocked waiting for a single resource
+type Wait struct {
+	Resource string
+	Kind     string
+	Syscalls map[string]int // number of calls made to each syscall while waiting for the resource
+	Count    int
+	Total    time.Duration
+	Max      time.Duration
+}
+
+// Kinds of resource which aren't file descriptors
+const (
+	KindFutex  = "futex"
+	KindChild  = "child"
+	KindTimer  = "timer"
+	KindSignal = "signal"
+	KindPoll   = "poll"
+)
+
+type epollKey struct {
+	pid  int
+	epfd int
+}
+
+// fdCalls are the blocking syscalls which wait for the file descriptor in their first argument
+var fdCalls = map[string]bool{
+	"read":      true,
+	"readv":     true,
+	"pread64":   true,
+	"preadv":    true,
+	"preadv2":   true,
+	"recvfrom":  true,
+	"recvmsg":   true,
+	"recvmmsg":  true,
+	"write":     true,
+	"writev":    true,
+	"pwrite64":  true,
+	"pwritev":   true,
+	"pwritev2":  true,
+	"sendto":    true,
+	"sendmsg":   true,
+	"sendmmsg":  true,
+	"accept":    true,
+	"accept4":   true,
+	"connect":   true,
+	"fsync":     true,
+	"fdatasync": true,
+	"flock":     true,
+	"sendfile":  true,
+	"splice":    true,
+	"tee":       true,
+}
+
+const (
+	epollCtlAdd = 1
+	epollCtlDel = 2
+)
+
+// New creates a report which will be printed to the given writer
+func New(w io.Writer) *Report {
+	return &Report{
+		w:        w,
+		resolver: iostat.NewResolver(),
+		threads:  make(map[int]*Thread),
+		epolls:   make(map[epollKey]map[int]string),
+		maxRows:  10,
+	}
+}
+
+// SetMaxRows limits the number of resources listed for each thread, or lists all of them if max is 0
+func (r *Report) SetMaxRows(max int) {
+	r.maxRows = max
+}
+
+// HandleSyscallExit attributes the time spent in a blocking syscall to the resource it waited for
+func (r *Report) HandleSyscallExit(call *tracer.Syscall) {
+	if call.Name() == "epoll_ctl" {
+		r.trackEpoll(call)
+		return
+	}
+	resource, kind, ok := r.resourceOf(call)
+	if !ok {
+		return
+	}
+	r.record(call.Process(), call.Name(), resource, kind, call.Duration())
+}
+
+func (r *Report) record(proc tracer.Process, syscall string, resource string, kind string, duration time.Duration) {
+	thread, ok := r.threads[proc.Tid]
+	if !ok {
+		thread = &Thread{Pid: proc.Pid, Tid: proc.Tid, Waits: make(map[string]*Wait)}
+		r.threads[proc.Tid] = thread
+	}
+	if proc.Comm != "" {
+		thread.Comm = proc.Comm
+	}
+	wait, ok := thread.Waits[resource]
+	if !ok {
+		wait = &Wait{Resource: resource, Kind: kind, Syscalls: make(map[string]int)}
+		thread.Waits[resource] = wait
+	}
+	wait.Syscalls[syscall]++
+	wait.Count++
+	wait.Total += duration
+	if duration > wait.Max {
+		wait.Max = duration
+	}
+}
+
+// resourceOf returns the name and kind of the resource a syscall waited for, or false if it isn't a blocking syscall
+func (r *Report) resourceOf(call *tracer.Syscall) (string, string, bool) {
+	args := call.Args()
+	pid := call.Pid()
+	name := call.Name()
+	if fdCalls[name] {
+		if len(args) == 0 {
+			return "", "", false
+		}
+		resource, kind := r.resolver.Resolve(pid, args[0].Int(), iodump.Peer(call))
+		return resource, string(kind), true
+	}
+	switch name {
+	case "poll", "ppoll":
+		if len(args) == 0 {
+			return "", "", false
+		}
+		var fds []string
+		for _, item := range args[0].Array() {
+			if obj := item.Object(); obj != nil && len(obj.Properties) > 0 {
+				fds = append(fds, r.name(pid, obj.Properties[0].Int()))
+			}
+		}
+		if len(fds) == 0 {
+			// poll with no file descriptors is commonly used to sleep
+			return "sleep", KindTimer, true
+		}
+		return "poll " + joinNames(fds), KindPoll, true
+	case "select", "pselect6":
+		if len(args) == 0 {
+			return "", "", false
+		}
+		return fmt.Sprintf("select on fds below %d", args[0].Int()), KindPoll, true
+	case "epoll_wait", "epoll_pwait", "epoll_pwait2":
+		if len(args) == 0 {
+			return "", "", false
+		}
+		epfd := args[0].Int()
+		registered := r.epolls[epollKey{pid: pid, epfd: epfd}]
+		if len(registered) == 0 {
+			// the fds were registered before we attached
+			return fmt.Sprintf("epoll fd %d", epfd), KindPoll, true
+		}
+		var fds []string
+		for _, fd := range sortedFds(registered) {
+			fds = append(fds, registered[fd])
+		}
+		return "epoll " + joinNames(fds), KindPoll, true
+	case "futex":
+		// only waits block - wakes and requeues return immediately
+		if len(args) < 2 || !futex.IsWait(args[1].Int()) {
+			return "", "", false
+		}
+		return fmt.Sprintf("futex 0x%x", args[0].Raw()), KindFutex, true
+	case "wait4":
+		if ret := call.Return().Int(); ret > 0 {
+			return fmt.Sprintf("child pid %d", ret), KindChild, true
+		}
+		if len(args) > 0 && args[0].Int() > 0 {
+			return fmt.Sprintf("child pid %d", args[0].Int()), KindChild, true
+		}
+		return "any child", KindChild, true
+	case "waitid":
+		// P_PID
+		if len(args) > 1 && args[0].Int() == 1 {
+			return fmt.Sprintf("child pid %d", args[1].Int()), KindChild, true
+		}
+		return "any child", KindChild, true
+	case "nanosleep":
+		return "sleep", KindTimer, true
+	case "clock_nanosleep":
+		if len(args) > 0 && args[0].Annotation() != "" {
+			return fmt.Sprintf("sleep (%s)", args[0].Annotation()), KindTimer, true
+		}
+		return "sleep", KindTimer, true
+	case "pause", "rt_sigsuspend", "rt_sigtimedwait":
+		return "signal", KindSignal, true
+	}
+	return "", "", false
+}
+
+// trackEpoll keeps track of the file descriptors registered with each epoll instance, so that time spent in
+// epoll_wait can be attributed to them
+func (r *Report) trackEpoll(call *tracer.Syscall) {
+	args := call.Args()
+	if len(args) < 3 || call.Return().Int() < 0 {
+		return
+	}
+	key := epollKey{pid: call.Pid(), epfd: args[0].Int()}
+	fd := args[2].Int()
+	switch args[1].Int() {
+	case epollCtlAdd:
+		if r.epolls[key] == nil {
+			r.epolls[key] = make(map[int]string)
+		}
+		r.epolls[key][fd] = r.name(call.Pid(), fd)
+	case epollCtlDel:
+		delete(r.epolls[key], fd)
+	}
+}
+
+// name returns the name of the file or socket endpoint a file descriptor refers to
+func (r *Report) name(pid int, fd int) string {
+	name, _ := r.resolver.Resolve(pid, fd, nil)
+	return name
+}
+
+// joinNames lists the names of the file descriptors waited for, up to a limit
+func joinNames(names []string) string {
+	const limit = 3
+	if len(names) <= limit {
+		return strings.Join(names, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(names[:limit], ", "), len(names)-limit)
+}
+
+func sortedFds(fds map[int]string) []int {
+	var sorted []int
+	for fd := range fds {
+		sorted = append(sorted, fd)
+	}
+	sort.Ints(sorted)
+	return sorted
+}
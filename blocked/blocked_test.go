@@ -0,0 +1,179 @@
+package blocked
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/liamg/grace/internal/tracertest"
+	"github.com/liamg/grace/tracer"
+	"github.com/liamg/grace/tracer/procfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ThreadsAreRankedByTimeBlocked(t *testing.T) {
+	r := New(&bytes.Buffer{})
+	main := tracer.Process{Pid: 10, Tid: 10, Comm: "server"}
+	worker := tracer.Process{Pid: 10, Tid: 11, Comm: "worker"}
+
+	r.record(main, "epoll_wait", "epoll tcp 0.0.0.0:80 -> 0.0.0.0:0", KindPoll, 100*time.Millisecond)
+	r.record(worker, "futex", "futex 0x7f0000001000", KindFutex, 300*time.Millisecond)
+	r.record(worker, "read", "/var/lib/data.db", "file", 20*time.Millisecond)
+	r.record(worker, "pread64", "/var/lib/data.db", "file", 40*time.Millisecond)
+	r.record(worker, "pread64", "/var/lib/data.db", "file", 10*time.Millisecond)
+
+	threads := r.Threads()
+	require.Len(t, threads, 2)
+	assert.Equal(t, "pid 10 tid 11 (worker)", threads[0].String())
+	assert.Equal(t, 370*time.Millisecond, threads[0].Total())
+	assert.Equal(t, 4, threads[0].Count())
+
+	waits := threads[0].Sorted()
+	require.Len(t, waits, 2)
+	assert.Equal(t, "futex 0x7f0000001000", waits[0].Resource)
+	assert.Equal(t, "/var/lib/data.db", waits[1].Resource)
+	assert.Equal(t, 3, waits[1].Count)
+	assert.Equal(t, 70*time.Millisecond, waits[1].Total)
+	assert.Equal(t, 40*time.Millisecond, waits[1].Max)
+	assert.Equal(t, "pread64, read", waits[1].syscallNames())
+}
+
+func Test_MaxRows(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	r := New(buffer)
+	r.SetMaxRows(2)
+	proc := tracer.Process{Pid: 1, Tid: 1}
+	r.record(proc, "nanosleep", "sleep", KindTimer, 3*time.Second)
+	r.record(proc, "wait4", "child pid 2", KindChild, 2*time.Second)
+	r.record(proc, "read", "pipe:[1]", "pipe", time.Second)
+	r.Print()
+
+	output := buffer.String()
+	assert.True(t, strings.HasPrefix(output, "pid 1 tid 1: 6.000000 seconds blocked in 3 calls\n"))
+	assert.Contains(t, output, "child pid 2")
+	assert.NotContains(t, output, "pipe:[1]")
+	assert.Contains(t, output, "... and 1 more\n")
+}
+
+func Test_JoinNames(t *testing.T) {
+	assert.Equal(t, "a, b", joinNames([]string{"a", "b"}))
+	assert.Equal(t, "a, b, c and 2 more", joinNames([]string{"a", "b", "c", "d", "e"}))
+}
+
+// fakeProc serves descriptor links in place of /proc
+type fakeProc map[string]string
+
+func (f fakeProc) Readlink(path string) (string, error) {
+	if link, ok := f[path]; ok {
+		return link, nil
+	}
+	return "", os.ErrNotExist
+}
+
+func (f fakeProc) ReadFile(path string) ([]byte, error) {
+	return nil, os.ErrNotExist
+}
+
+func (f fakeProc) ReadAt(path string, out []byte, offset int64) (int, error) {
+	return 0, os.ErrNotExist
+}
+
+func Test_ResourceOf(t *testing.T) {
+	procfs.SetSource(fakeProc{"/proc/10/fd/3": "/var/lib/data.db"})
+	defer procfs.SetSource(procfs.OS)
+
+	futexCall := func(op uintptr) tracertest.Syscall {
+		return tracertest.Syscall{
+			Name: "futex",
+			Args: []tracertest.Arg{
+				{Name: "uaddr", Type: tracer.ArgTypeAddress, Raw: 0x7f0000001000},
+				{Name: "op", Type: tracer.ArgTypeInt, Raw: op},
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		call     tracertest.Syscall
+		resource string
+		kind     string
+		blocking bool
+	}{
+		{
+			name:     "read",
+			call:     tracertest.Syscall{Name: "read", Args: []tracertest.Arg{{Name: "fd", Type: tracer.ArgTypeInt, Raw: 3}}},
+			resource: "/var/lib/data.db",
+			kind:     "file",
+			blocking: true,
+		},
+		{
+			name:     "futex wait",
+			call:     futexCall(0),
+			resource: "futex 0x7f0000001000",
+			kind:     KindFutex,
+			blocking: true,
+		},
+		{
+			name:     "private futex wait bitset",
+			call:     futexCall(9 | 128),
+			resource: "futex 0x7f0000001000",
+			kind:     KindFutex,
+			blocking: true,
+		},
+		{
+			name:     "futex lock pi",
+			call:     futexCall(6),
+			resource: "futex 0x7f0000001000",
+			kind:     KindFutex,
+			blocking: true,
+		},
+		{
+			name: "futex wake",
+			call: futexCall(1 | 128),
+		},
+		{
+			name: "futex requeue",
+			call: futexCall(4),
+		},
+		{
+			name: "futex unlock pi",
+			call: futexCall(7),
+		},
+		{
+			name: "poll without fds",
+			call: tracertest.Syscall{Name: "poll", Args: []tracertest.Arg{
+				{Name: "fds", Type: tracer.ArgTypeArray},
+			}},
+			resource: "sleep",
+			kind:     KindTimer,
+			blocking: true,
+		},
+		{
+			name: "wait4 for any child",
+			call: tracertest.Syscall{
+				Name:   "wait4",
+				Args:   []tracertest.Arg{{Name: "upid", Type: tracer.ArgTypeInt, Raw: uintptr(0xffffffffffffffff)}},
+				Return: tracertest.Arg{Type: tracer.ArgTypeInt, Raw: 12},
+			},
+			resource: "child pid 12",
+			kind:     KindChild,
+			blocking: true,
+		},
+		{
+			name: "getpid",
+			call: tracertest.Syscall{Name: "getpid"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.call.Process = tracer.Process{Pid: 10}
+			resource, kind, blocking := New(&bytes.Buffer{}).resourceOf(test.call.Build(t))
+			assert.Equal(t, test.blocking, blocking)
+			assert.Equal(t, test.resource, resource)
+			assert.Equal(t, test.kind, kind)
+		})
+	}
+}
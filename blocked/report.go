@@ -0,0 +1,127 @@
+package blocked
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aquasecurity/table"
+)
+
+// Total returns the total time the thread spent blocked
+func (t *Thread) Total() time.Duration {
+	var total time.Duration
+	for _, wait := range t.Waits {
+		total += wait.Total
+	}
+	return total
+}
+
+// Count returns the number of blocking syscalls the thread made
+func (t *Thread) Count() int {
+	var count int
+	for _, wait := range t.Waits {
+		count += wait.Count
+	}
+	return count
+}
+
+func (t *Thread) String() string {
+	label := fmt.Sprintf("pid %d tid %d", t.Pid, t.Tid)
+	if t.Comm != "" {
+		label += fmt.Sprintf(" (%s)", t.Comm)
+	}
+	return label
+}
+
+// Sorted returns the resources the thread waited for, the longest total wait first
+func (t *Thread) Sorted() []*Wait {
+	var waits []*Wait
+	for _, wait := range t.Waits {
+		waits = append(waits, wait)
+	}
+	sort.Slice(waits, func(i, j int) bool {
+		if waits[i].Total != waits[j].Total {
+			return waits[i].Total > waits[j].Total
+		}
+		return waits[i].Resource < waits[j].Resource
+	})
+	return waits
+}
+
+// Threads returns each thread which made blocking syscalls, the longest total time blocked first
+func (r *Report) Threads() []*Thread {
+	var threads []*Thread
+	for _, thread := range r.threads {
+		threads = append(threads, thread)
+	}
+	sort.Slice(threads, func(i, j int) bool {
+		if threads[i].Total() != threads[j].Total() {
+			return threads[i].Total() > threads[j].Total()
+		}
+		return threads[i].Tid < threads[j].Tid
+	})
+	return threads
+}
+
+// syscallNames lists the syscalls made while waiting for the resource, most frequent first
+func (w *Wait) syscallNames() string {
+	var names []string
+	for name := range w.Syscalls {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if w.Syscalls[names[i]] != w.Syscalls[names[j]] {
+			return w.Syscalls[names[i]] > w.Syscalls[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	return strings.Join(names, ", ")
+}
+
+// Print writes a table for each thread of the resources it spent the most time blocked waiting for
+func (r *Report) Print() {
+	for i, thread := range r.Threads() {
+		if i > 0 {
+			_, _ = fmt.Fprintln(r.w)
+		}
+		total := thread.Total()
+		_, _ = fmt.Fprintf(r.w, "%s: %.6f seconds blocked in %d calls\n", thread, total.Seconds(), thread.Count())
+
+		tab := table.New(r.w)
+		tab.SetRowLines(false)
+		tab.AddHeaders("time %", "seconds", "count", "max", "syscalls", "kind", "resource")
+		tab.SetAlignment(
+			table.AlignRight, table.AlignRight, table.AlignRight, table.AlignRight,
+			table.AlignLeft, table.AlignLeft, table.AlignLeft,
+		)
+		tab.SetLineStyle(table.StyleBlue)
+
+		waits := thread.Sorted()
+		for j, wait := range waits {
+			if r.maxRows > 0 && j == r.maxRows {
+				break
+			}
+			var percent float64
+			if total > 0 {
+				percent = float64(wait.Total) * 100 / float64(total)
+			}
+			tab.AddRow(
+				fmt.Sprintf("%.2f", percent),
+				fmt.Sprintf("%.6f", wait.Total.Seconds()),
+				fmt.Sprintf("%d", wait.Count),
+				fmt.Sprintf("%.6f", wait.Max.Seconds()),
+				wait.syscallNames(),
+				wait.Kind,
+				wait.Resource,
+			)
+		}
+
+		tab.Render()
+
+		if r.maxRows > 0 && len(waits) > r.maxRows {
+			_, _ = fmt.Fprintf(r.w, "... and %d more\n", len(waits)-r.maxRows)
+		}
+	}
+}
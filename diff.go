@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/liamg/grace/diff"
+	"github.com/spf13/cobra"
+)
+
+var flagDiffContext = 3
+
+var diffCmd = &cobra.Command{
+	Use:     "diff [a] [b] [flags]",
+	Example: `grace diff good.grace bad.grace`,
+	Short:   "Compare two traces of the same program and show where they diverge",
+	Long: `Compare two traces of the same program and show where they diverge.
+
+Each trace can be either a recording made with 'grace record' or the output of 'grace --format json'. The calls
+made by each process are aligned, after values which are expected to change between runs have been normalised:
+addresses, pids, timestamps and inode numbers are replaced with placeholders, and file descriptors are renumbered
+in the order they were created.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+
+		a, err := diff.Load(args[0])
+		if err != nil {
+			return err
+		}
+		b, err := diff.Load(args[1])
+		if err != nil {
+			return err
+		}
+		if flagDiffContext < 0 {
+			return fmt.Errorf("context must not be negative")
+		}
+
+		diff.Render(cmd.OutOrStdout(), diff.Compare(a, b), flagDiffContext, !flagDisableColours)
+		return nil
+	},
+}
+
+func init() {
+	diffCmd.Flags().IntVarP(&flagDiffContext, "unified", "U", flagDiffContext, "number of matching calls to show around each difference")
+	rootCmd.AddCommand(diffCmd)
+}
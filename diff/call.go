@@ -0,0 +1,90 @@
+package diff
+
+import (
+	"github.com/liamg/grace/tracer"
+	"github.com/liamg/grace/tracer/annotation"
+)
+
+// Call is a completed syscall loaded from a recording or a JSON trace
+type Call struct {
+	Pid    int
+	Name   string
+	Args   []Value
+	Return Value
+	Errno  string
+}
+
+// Value is an argument, return value, object property or array element
+type Value struct {
+	Name       string
+	Type       string
+	Raw        uint64
+	Int        int64
+	Data       []byte
+	Annotation string
+	Replace    bool
+	Object     string
+	Properties []Value
+	Elements   []Value
+}
+
+// Process is the sequence of calls made by a single process, in the order they completed
+type Process struct {
+	Pid   int
+	Calls []Call
+}
+
+// Trace is a set of processes, in the order they were first seen
+type Trace struct {
+	Processes []*Process
+}
+
+func (t *Trace) add(call Call) {
+	for _, proc := range t.Processes {
+		if proc.Pid == call.Pid {
+			proc.Calls = append(proc.Calls, call)
+			return
+		}
+	}
+	t.Processes = append(t.Processes, &Process{
+		Pid:   call.Pid,
+		Calls: []Call{call},
+	})
+}
+
+func convertSyscall(syscall *tracer.Syscall) Call {
+	call := Call{
+		Pid:    syscall.Pid(),
+		Name:   syscall.Name(),
+		Return: convertArg(syscall.Return()),
+	}
+	for _, arg := range syscall.Args() {
+		call.Args = append(call.Args, convertArg(arg))
+	}
+	if errno := syscall.Errno(); errno != 0 {
+		call.Errno = annotation.ErrNoToString(errno)
+	}
+	return call
+}
+
+func convertArg(arg tracer.Arg) Value {
+	value := Value{
+		Name:       arg.Name(),
+		Type:       arg.Type().String(),
+		Raw:        uint64(arg.Raw()),
+		Int:        int64(arg.Int()),
+		Data:       arg.Data(),
+		Annotation: arg.Annotation(),
+		Replace:    arg.ReplaceValueWithAnnotation(),
+	}
+	if obj := arg.Object(); obj != nil {
+		value.Object = obj.Name
+		for _, prop := range obj.Properties {
+			value.Properties = append(value.Properties, convertArg(prop))
+		}
+	}
+	for _, element := range arg.Array() {
+		value.Elements = append(value.Elements, convertArg(element))
+	}
+	return value
+}
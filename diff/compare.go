@@ -0,0 +1,63 @@
+package diff
+
+// ProcessDiff is the comparison of the calls made by a process in one trace with those of its counterpart in the other
+type ProcessDiff struct {
+	A       *Process // nil if the process only exists in the second trace
+	B       *Process // nil if the process only exists in the first trace
+	Lines   [2][]string
+	Ops     []Op
+	Added   int
+	Removed int
+}
+
+// FirstDivergence returns the index of the first operation which is not a match, or -1 if the calls are identical
+func (d *ProcessDiff) FirstDivergence() int {
+	for i, op := range d.Ops {
+		if op.Type != OpEqual {
+			return i
+		}
+	}
+	return -1
+}
+
+// Compare aligns the calls made by each process in the two traces. Processes are paired in the order in which they
+// were first seen, as their pids will almost certainly differ between runs.
+func Compare(a, b *Trace) []ProcessDiff {
+	count := len(a.Processes)
+	if len(b.Processes) > count {
+		count = len(b.Processes)
+	}
+
+	var diffs []ProcessDiff
+	for i := 0; i < count; i++ {
+		var d ProcessDiff
+		if i < len(a.Processes) {
+			d.A = a.Processes[i]
+			d.Lines[0] = normaliseAll(a, d.A)
+		}
+		if i < len(b.Processes) {
+			d.B = b.Processes[i]
+			d.Lines[1] = normaliseAll(b, d.B)
+		}
+		d.Ops = editScript(d.Lines[0], d.Lines[1])
+		for _, op := range d.Ops {
+			switch op.Type {
+			case OpAdd:
+				d.Added++
+			case OpRemove:
+				d.Removed++
+			}
+		}
+		diffs = append(diffs, d)
+	}
+	return diffs
+}
+
+func normaliseAll(trace *Trace, proc *Process) []string {
+	n := newNormaliser(trace)
+	lines := make([]string, 0, len(proc.Calls))
+	for _, call := range proc.Calls {
+		lines = append(lines, n.normalise(call))
+	}
+	return lines
+}
@@ -0,0 +1,168 @@
+package diff
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEditScript(t *testing.T) {
+	tests := []struct {
+		name    string
+		a       []string
+		b       []string
+		added   int
+		removed int
+	}{
+		{name: "identical", a: []string{"a", "b", "c"}, b: []string{"a", "b", "c"}},
+		{name: "empty", a: nil, b: nil},
+		{name: "all added", a: nil, b: []string{"a", "b"}, added: 2},
+		{name: "all removed", a: []string{"a", "b"}, b: nil, removed: 2},
+		{name: "insertion", a: []string{"a", "c"}, b: []string{"a", "b", "c"}, added: 1},
+		{name: "replacement", a: []string{"a", "b", "c"}, b: []string{"a", "x", "c"}, added: 1, removed: 1},
+		{name: "large and fully divergent", a: numberedLines("a", 2000), b: numberedLines("b", 3000), added: 3000, removed: 2000},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ops := editScript(test.a, test.b)
+			var added, removed int
+			var a, b []string
+			for _, op := range ops {
+				switch op.Type {
+				case OpEqual:
+					a = append(a, test.a[op.A])
+					b = append(b, test.b[op.B])
+				case OpRemove:
+					removed++
+					a = append(a, test.a[op.A])
+				case OpAdd:
+					added++
+					b = append(b, test.b[op.B])
+				}
+			}
+			assert.Equal(t, test.added, added)
+			assert.Equal(t, test.removed, removed)
+			assert.Equal(t, len(test.a), len(a))
+			assert.Equal(t, len(test.b), len(b))
+			for i := range a {
+				assert.Equal(t, test.a[i], a[i])
+			}
+			for i := range b {
+				assert.Equal(t, test.b[i], b[i])
+			}
+		})
+	}
+}
+
+func numberedLines(prefix string, count int) []string {
+	lines := make([]string, count)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("%s%d", prefix, i)
+	}
+	return lines
+}
+
+// lcsLength is the length of the longest common subsequence, found by dynamic programming
+func lcsLength(a, b []string) int {
+	lengths := make([][]int, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			case lengths[i+1][j] > lengths[i][j+1]:
+				lengths[i][j] = lengths[i+1][j]
+			default:
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+	return lengths[0][0]
+}
+
+func TestEditScriptIsShortest(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	randomLines := func() []string {
+		lines := make([]string, random.Intn(30))
+		for i := range lines {
+			lines[i] = string(rune('a' + random.Intn(4)))
+		}
+		return lines
+	}
+	for i := 0; i < 500; i++ {
+		a, b := randomLines(), randomLines()
+		var equal, x, y int
+		for _, op := range editScript(a, b) {
+			switch op.Type {
+			case OpEqual:
+				require.Equal(t, x, op.A)
+				require.Equal(t, y, op.B)
+				require.Equal(t, a[x], b[y])
+				equal++
+				x++
+				y++
+			case OpRemove:
+				require.Equal(t, x, op.A)
+				x++
+			case OpAdd:
+				require.Equal(t, y, op.B)
+				y++
+			}
+		}
+		assert.Equal(t, len(a), x)
+		assert.Equal(t, len(b), y)
+		assert.Equal(t, lcsLength(a, b), equal, "a=%v b=%v", a, b)
+	}
+}
+
+func TestEditScriptMemory(t *testing.T) {
+	a, b := numberedLines("a", 5000), numberedLines("b", 5000)
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	ops := editScript(a, b)
+	runtime.ReadMemStats(&after)
+	assert.Len(t, ops, 10000)
+	// storing the search state for every step would need hundreds of megabytes here
+	assert.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(16*1024*1024))
+}
+
+func TestNormaliseRenumbersFileDescriptors(t *testing.T) {
+	trace := &Trace{Processes: []*Process{{Pid: 100}}}
+	n := newNormaliser(trace)
+
+	open := Call{Pid: 100, Name: "openat", Return: Value{Type: "int", Int: 7}}
+	read := Call{Pid: 100, Name: "read", Args: []Value{
+		{Name: "fd", Type: "int", Int: 7},
+		{Name: "buf", Type: "address", Raw: 0x7ffd1234},
+	}, Return: Value{Type: "long", Int: 0}}
+	kill := Call{Pid: 100, Name: "kill", Args: []Value{
+		{Name: "pid", Type: "int", Int: 100},
+	}, Return: Value{Type: "int", Int: 0}}
+
+	assert.Equal(t, "openat() = fd#3", n.normalise(open))
+	assert.Equal(t, "read(fd#3, <addr>) = 0", n.normalise(read))
+	assert.Equal(t, "kill(<pid>) = 0", n.normalise(kill))
+}
+
+func TestSortFlags(t *testing.T) {
+	assert.Equal(t, "MAP_DENYWRITE|MAP_FIXED|MAP_PRIVATE", sortFlags("MAP_PRIVATE|MAP_FIXED|MAP_DENYWRITE"))
+	assert.Equal(t, "O_RDONLY", sortFlags("O_RDONLY"))
+}
+
+func TestLoadReportsBothErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace")
+	require.NoError(t, os.WriteFile(path, []byte("not a trace\n"), 0o600))
+	_, err := Load(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not a grace recording")
+	assert.Contains(t, err.Error(), "line 1")
+}
@@ -0,0 +1,139 @@
+package diff
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/liamg/grace/tracer"
+)
+
+// Load reads a trace from a recording made with 'grace record', or from the output of 'grace --format json'
+func Load(path string) (*Trace, error) {
+	trace, recordingErr := loadRecording(path)
+	if recordingErr == nil {
+		return trace, nil
+	}
+	trace, jsonErr := loadJSON(path)
+	if jsonErr == nil {
+		return trace, nil
+	}
+	return nil, fmt.Errorf("%s is neither a grace recording (%v) nor a JSON trace (%w)", path, recordingErr, jsonErr)
+}
+
+func loadRecording(path string) (*Trace, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	t, _, err := tracer.Replay(f)
+	if err != nil {
+		return nil, err
+	}
+
+	trace := &Trace{}
+	t.SetSyscallExitHandler(func(syscall *tracer.Syscall) {
+		trace.add(convertSyscall(syscall))
+	})
+	if err := t.Start(); err != nil {
+		return nil, err
+	}
+	return trace, nil
+}
+
+type jsonEvent struct {
+	Type    string `json:"type"`
+	Pid     int    `json:"pid"`
+	Syscall *struct {
+		Name   string      `json:"name"`
+		Args   []jsonValue `json:"args"`
+		Return *jsonValue  `json:"return"`
+		Errno  string      `json:"errno"`
+	} `json:"syscall"`
+}
+
+type jsonValue struct {
+	Name       string      `json:"name"`
+	Type       string      `json:"type"`
+	Raw        uint64      `json:"raw"`
+	Value      *int64      `json:"value"`
+	Data       *string     `json:"data"`
+	DataBase64 string      `json:"data_base64"`
+	Annotation string      `json:"annotation"`
+	Replace    bool        `json:"replace"`
+	Array      []jsonValue `json:"array"`
+	Object     *struct {
+		Name       string      `json:"name"`
+		Properties []jsonValue `json:"properties"`
+	} `json:"object"`
+}
+
+func loadJSON(path string) (*Trace, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	trace := &Trace{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var event jsonEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if event.Type != "syscall_exit" || event.Syscall == nil {
+			continue
+		}
+		call := Call{
+			Pid:   event.Pid,
+			Name:  event.Syscall.Name,
+			Errno: event.Syscall.Errno,
+		}
+		for _, arg := range event.Syscall.Args {
+			call.Args = append(call.Args, convertJSONValue(arg))
+		}
+		if event.Syscall.Return != nil {
+			call.Return = convertJSONValue(*event.Syscall.Return)
+		}
+		trace.add(call)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return trace, nil
+}
+
+func convertJSONValue(input jsonValue) Value {
+	value := Value{
+		Name:       input.Name,
+		Type:       input.Type,
+		Raw:        input.Raw,
+		Int:        int64(input.Raw),
+		Annotation: input.Annotation,
+		Replace:    input.Replace,
+	}
+	if input.Value != nil {
+		value.Int = *input.Value
+	}
+	if input.Data != nil {
+		value.Data = []byte(*input.Data)
+	} else if input.DataBase64 != "" {
+		value.Data, _ = base64.StdEncoding.DecodeString(input.DataBase64)
+	}
+	if input.Object != nil {
+		value.Object = input.Object.Name
+		for _, prop := range input.Object.Properties {
+			value.Properties = append(value.Properties, convertJSONValue(prop))
+		}
+	}
+	for _, element := range input.Array {
+		value.Elements = append(value.Elements, convertJSONValue(element))
+	}
+	return value
+}
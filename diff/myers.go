@@ -0,0 +1,122 @@
+package diff
+
+// OpType describes how a line in one sequence relates to the other
+type OpType int
+
+const (
+	OpEqual OpType = iota
+	OpRemove
+	OpAdd
+)
+
+// Op is a single step of an edit script. A is the index into the first sequence and B the index into the second;
+// only the index relevant to the operation is meaningful for removals and additions.
+type Op struct {
+	Type OpType
+	A    int
+	B    int
+}
+
+// editScript returns the shortest edit script which transforms a into b. It uses the linear space variant of Myers'
+// algorithm, which finds the middle snake of an optimal path and recurses either side of it, so memory use does not
+// grow with the number of differences.
+func editScript(a, b []string) []Op {
+	s := &script{a: a, b: b}
+	s.compare(0, len(a), 0, len(b))
+	return s.ops
+}
+
+type script struct {
+	a   []string
+	b   []string
+	ops []Op
+}
+
+// compare appends the edit script which transforms a[aLo:aHi] into b[bLo:bHi]
+func (s *script) compare(aLo, aHi, bLo, bHi int) {
+	for aLo < aHi && bLo < bHi && s.a[aLo] == s.b[bLo] {
+		s.ops = append(s.ops, Op{Type: OpEqual, A: aLo, B: bLo})
+		aLo++
+		bLo++
+	}
+	var suffix int
+	for aHi > aLo && bHi > bLo && s.a[aHi-1] == s.b[bHi-1] {
+		aHi--
+		bHi--
+		suffix++
+	}
+
+	switch {
+	case aLo == aHi:
+		for y := bLo; y < bHi; y++ {
+			s.ops = append(s.ops, Op{Type: OpAdd, A: aLo, B: y})
+		}
+	case bLo == bHi:
+		for x := aLo; x < aHi; x++ {
+			s.ops = append(s.ops, Op{Type: OpRemove, A: x, B: bLo})
+		}
+	default:
+		x, y := s.middleSnake(aLo, aHi, bLo, bHi)
+		s.compare(aLo, x, bLo, y)
+		s.compare(x, aHi, y, bHi)
+	}
+
+	for i := 0; i < suffix; i++ {
+		s.ops = append(s.ops, Op{Type: OpEqual, A: aHi + i, B: bHi + i})
+	}
+}
+
+// middleSnake searches forwards from the start and backwards from the end of the given ranges at the same time, and
+// returns a point on an optimal path where the two searches meet. Both halves either side of the point need fewer
+// edits than the whole, so recursing on them always terminates.
+func (s *script) middleSnake(aLo, aHi, bLo, bHi int) (int, int) {
+	n, m := aHi-aLo, bHi-bLo
+	delta := n - m
+	odd := delta%2 != 0
+	max := (n + m + 1) / 2
+	offset := max + 1
+	// forward[k] is the furthest x reached from the start on diagonal k (x - y), and backward[k] is the furthest
+	// distance reached back from the end on diagonal k of the reversed sequences
+	forward := make([]int, 2*max+3)
+	backward := make([]int, 2*max+3)
+
+	for d := 0; d <= max; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && forward[offset+k-1] < forward[offset+k+1]) {
+				x = forward[offset+k+1]
+			} else {
+				x = forward[offset+k-1] + 1
+			}
+			y := x - k
+			startX, startY := x, y
+			for x < n && y < m && s.a[aLo+x] == s.b[bLo+y] {
+				x++
+				y++
+			}
+			forward[offset+k] = x
+			if reverse := delta - k; odd && reverse >= -(d-1) && reverse <= d-1 && x+backward[offset+reverse] >= n {
+				return aLo + startX, bLo + startY
+			}
+		}
+		for k := -d; k <= d; k += 2 {
+			var u int
+			if k == -d || (k != d && backward[offset+k-1] < backward[offset+k+1]) {
+				u = backward[offset+k+1]
+			} else {
+				u = backward[offset+k-1] + 1
+			}
+			w := u - k
+			for u < n && w < m && s.a[aHi-1-u] == s.b[bHi-1-w] {
+				u++
+				w++
+			}
+			backward[offset+k] = u
+			if reverse := delta - k; !odd && reverse >= -d && reverse <= d && forward[offset+reverse]+u >= n {
+				return aHi - u, bHi - w
+			}
+		}
+	}
+	// unreachable, as the searches always meet within max steps
+	return aLo, bLo
+}
@@ -0,0 +1,200 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxDataLength is the number of bytes of a data argument which are compared - anything beyond this is elided
+const maxDataLength = 64
+
+// syscalls which return a new file descriptor
+var fdReturningCalls = map[string]bool{
+	"open":            true,
+	"openat":          true,
+	"openat2":         true,
+	"creat":           true,
+	"dup":             true,
+	"dup2":            true,
+	"dup3":            true,
+	"socket":          true,
+	"accept":          true,
+	"accept4":         true,
+	"epoll_create":    true,
+	"epoll_create1":   true,
+	"eventfd":         true,
+	"eventfd2":        true,
+	"signalfd":        true,
+	"signalfd4":       true,
+	"timerfd_create":  true,
+	"inotify_init":    true,
+	"inotify_init1":   true,
+	"memfd_create":    true,
+	"pidfd_open":      true,
+	"fanotify_init":   true,
+	"userfaultfd":     true,
+	"perf_event_open": true,
+	"open_tree":       true,
+	"fsopen":          true,
+	"fsmount":         true,
+	"fspick":          true,
+}
+
+// syscalls which return a process or thread id
+var pidReturningCalls = map[string]bool{
+	"fork":            true,
+	"vfork":           true,
+	"clone":           true,
+	"clone3":          true,
+	"getpid":          true,
+	"getppid":         true,
+	"gettid":          true,
+	"getpgid":         true,
+	"getpgrp":         true,
+	"getsid":          true,
+	"setsid":          true,
+	"wait4":           true,
+	"waitid":          true,
+	"set_tid_address": true,
+}
+
+// syscalls whose output buffers are expected to differ on every run
+var volatileDataCalls = map[string]bool{
+	"getrandom": true,
+}
+
+// object properties which hold timestamps or other values which change from run to run
+var volatileProperties = map[string]bool{
+	"sec":   true,
+	"nsec":  true,
+	"usec":  true,
+	"atime": true,
+	"mtime": true,
+	"ctime": true,
+	"btime": true,
+	"ino":   true,
+	"dev":   true,
+}
+
+// normaliser replaces values which are expected to differ between two runs of the same program with stable
+// placeholders, so that calls can be compared
+type normaliser struct {
+	pids map[int64]bool
+	fds  map[int64]int
+}
+
+func newNormaliser(trace *Trace) *normaliser {
+	n := &normaliser{
+		pids: make(map[int64]bool),
+		fds:  map[int64]int{0: 0, 1: 1, 2: 2},
+	}
+	for _, proc := range trace.Processes {
+		n.pids[int64(proc.Pid)] = true
+	}
+	return n
+}
+
+// fd returns a stable name for a file descriptor, numbering them in the order they were first seen
+func (n *normaliser) fd(fd int64) string {
+	if fd < 0 {
+		return fmt.Sprintf("%d", fd)
+	}
+	id, ok := n.fds[fd]
+	if !ok {
+		id = len(n.fds)
+		n.fds[fd] = id
+	}
+	return fmt.Sprintf("fd#%d", id)
+}
+
+// normalise returns a stable string representation of a call
+func (n *normaliser) normalise(call Call) string {
+	args := make([]string, 0, len(call.Args))
+	for _, arg := range call.Args {
+		if arg.Type == "data" && volatileDataCalls[call.Name] {
+			args = append(args, "<volatile>")
+			continue
+		}
+		args = append(args, n.value(arg, isFdName(arg.Name)))
+	}
+
+	var ret string
+	switch {
+	case call.Errno != "":
+		ret = "-1 " + call.Errno
+	case fdReturningCalls[call.Name]:
+		ret = n.fd(call.Return.Int)
+	case pidReturningCalls[call.Name] && call.Return.Int > 0:
+		ret = "<pid>"
+	default:
+		ret = n.value(call.Return, false)
+	}
+
+	return fmt.Sprintf("%s(%s) = %s", call.Name, strings.Join(args, ", "), ret)
+}
+
+func (n *normaliser) value(value Value, isFd bool) string {
+	if value.Replace && value.Annotation != "" {
+		return sortFlags(value.Annotation)
+	}
+	switch value.Type {
+	case "data":
+		data := value.Data
+		suffix := ""
+		if len(data) > maxDataLength {
+			data = data[:maxDataLength]
+			suffix = "..."
+		}
+		return fmt.Sprintf("%q%s", data, suffix)
+	case "address":
+		if value.Raw == 0 {
+			return "NULL"
+		}
+		return "<addr>"
+	case "object":
+		props := make([]string, 0, len(value.Properties))
+		for _, prop := range value.Properties {
+			if volatileProperties[prop.Name] {
+				props = append(props, prop.Name+"=<volatile>")
+				continue
+			}
+			props = append(props, prop.Name+"="+n.value(prop, isFdName(prop.Name)))
+		}
+		if value.Object == "timespec" || value.Object == "timeval" {
+			return "{<time>}"
+		}
+		return "{" + strings.Join(props, ", ") + "}"
+	case "array":
+		elements := make([]string, 0, len(value.Elements))
+		for _, element := range value.Elements {
+			elements = append(elements, n.value(element, isFd))
+		}
+		return "[" + strings.Join(elements, ", ") + "]"
+	case "int", "long", "uint", "ulong":
+		switch {
+		case isFd:
+			return n.fd(value.Int)
+		case n.pids[value.Int] && value.Int > 0:
+			return "<pid>"
+		}
+	}
+	if value.Annotation != "" {
+		return fmt.Sprintf("%d /* %s */", value.Int, sortFlags(value.Annotation))
+	}
+	return fmt.Sprintf("%d", value.Int)
+}
+
+// sortFlags sorts the members of an annotation such as "O_RDONLY|O_CLOEXEC", as the order is not significant
+func sortFlags(annotation string) string {
+	if !strings.Contains(annotation, "|") {
+		return annotation
+	}
+	flags := strings.Split(annotation, "|")
+	sort.Strings(flags)
+	return strings.Join(flags, "|")
+}
+
+func isFdName(name string) bool {
+	return name == "fd" || strings.HasSuffix(name, "fd") || strings.HasPrefix(name, "fd")
+}
@@ -0,0 +1,83 @@
+package diff
+
+import (
+	"io"
+
+	"github.com/liamg/grace/printer"
+)
+
+// Render writes a unified-style diff of the given comparison, showing the given number of matching calls around
+// each change
+func Render(w io.Writer, diffs []ProcessDiff, context int, useColours bool) {
+	p := printer.New(w)
+	p.SetUseColours(useColours)
+
+	for i, d := range diffs {
+		if i > 0 {
+			p.Print("\n")
+		}
+		renderHeader(p, i, d)
+
+		first := d.FirstDivergence()
+		if first < 0 {
+			p.PrintDim("no differences\n")
+			continue
+		}
+		op := d.Ops[first]
+		p.Print("first divergence at call #%d (a) / #%d (b)\n", op.A+1, op.B+1)
+
+		printed := -1
+		for j, op := range d.Ops {
+			if !nearChange(d.Ops, j, context) {
+				continue
+			}
+			if printed < 0 || j > printed+1 {
+				p.PrintColour(printer.ColourCyan, "@@ a:%d b:%d @@\n", op.A+1, op.B+1)
+			}
+			printed = j
+			switch op.Type {
+			case OpEqual:
+				p.PrintDim("  %s\n", d.Lines[0][op.A])
+			case OpRemove:
+				p.PrintColour(printer.ColourRed, "- %s\n", d.Lines[0][op.A])
+			case OpAdd:
+				p.PrintColour(printer.ColourGreen, "+ %s\n", d.Lines[1][op.B])
+			}
+		}
+
+		p.PrintColour(printer.ColourRed, "%d removed", d.Removed)
+		p.Print(", ")
+		p.PrintColour(printer.ColourGreen, "%d added", d.Added)
+		p.Print("\n")
+	}
+}
+
+func renderHeader(p *printer.Printer, index int, d ProcessDiff) {
+	p.PrintColour(printer.ColourYellow, "process #%d: ", index+1)
+	switch {
+	case d.A == nil:
+		p.Print("only in b (pid %d, %d calls)\n", d.B.Pid, len(d.B.Calls))
+	case d.B == nil:
+		p.Print("only in a (pid %d, %d calls)\n", d.A.Pid, len(d.A.Calls))
+	default:
+		p.Print("a: pid %d, %d calls / b: pid %d, %d calls\n", d.A.Pid, len(d.A.Calls), d.B.Pid, len(d.B.Calls))
+	}
+}
+
+// nearChange reports whether the operation at the given index is a change, or within context operations of one
+func nearChange(ops []Op, index int, context int) bool {
+	start := index - context
+	if start < 0 {
+		start = 0
+	}
+	end := index + context
+	if end >= len(ops) {
+		end = len(ops) - 1
+	}
+	for i := start; i <= end; i++ {
+		if ops[i].Type != OpEqual {
+			return true
+		}
+	}
+	return false
+}
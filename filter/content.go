@@ -0,0 +1,116 @@
+package filter
+
+import (
+	"bytes"
+	"encoding/hex"
+	"regexp"
+	"strings"
+
+	"github.com/liamg/grace/tracer"
+)
+
+// contentPattern matches against buffer contents, e.g. the data passed to read/write/send/recv
+type contentPattern struct {
+	literal []byte
+	regex   *regexp.Regexp
+}
+
+func parseContentPattern(key string, value string) (*contentPattern, error) {
+	switch key {
+	case "content-regex":
+		regex, err := regexp.Compile(value)
+		if err != nil {
+			return nil, err
+		}
+		return &contentPattern{regex: regex}, nil
+	case "content-hex":
+		literal, err := hex.DecodeString(strings.TrimPrefix(strings.ReplaceAll(value, " ", ""), "0x"))
+		if err != nil {
+			return nil, err
+		}
+		return &contentPattern{literal: literal}, nil
+	default:
+		return &contentPattern{literal: []byte(value)}, nil
+	}
+}
+
+func (c *contentPattern) find(data []byte) [][2]int {
+	if c.regex != nil {
+		var ranges [][2]int
+		for _, match := range c.regex.FindAllIndex(data, -1) {
+			if match[1] > match[0] {
+				ranges = append(ranges, [2]int{match[0], match[1]})
+			}
+		}
+		return ranges
+	}
+	if len(c.literal) == 0 {
+		return nil
+	}
+	var ranges [][2]int
+	var offset int
+	for {
+		index := bytes.Index(data[offset:], c.literal)
+		if index < 0 {
+			break
+		}
+		start := offset + index
+		ranges = append(ranges, [2]int{start, start + len(c.literal)})
+		offset = start + len(c.literal)
+	}
+	return ranges
+}
+
+func matchContent(patterns []*contentPattern, args []tracer.Arg) bool {
+	for _, arg := range args {
+		switch arg.Type() {
+		case tracer.ArgTypeData:
+			for _, pattern := range patterns {
+				if len(pattern.find(arg.Data())) > 0 {
+					return true
+				}
+			}
+		case tracer.ArgTypeObject:
+			if obj := arg.Object(); obj != nil && matchContent(patterns, obj.Properties) {
+				return true
+			}
+		case tracer.ArgTypeArray:
+			if matchContent(patterns, arg.Array()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Highlight returns the sorted, non-overlapping ranges of the given data which match content filters
+func (f *Filter) Highlight(data []byte) [][2]int {
+	if len(f.allow.contents) == 0 || len(data) == 0 {
+		return nil
+	}
+	mask := make([]bool, len(data))
+	var found bool
+	for _, pattern := range f.allow.contents {
+		for _, r := range pattern.find(data) {
+			for i := r[0]; i < r[1]; i++ {
+				mask[i] = true
+			}
+			found = true
+		}
+	}
+	if !found {
+		return nil
+	}
+	var ranges [][2]int
+	for i := 0; i < len(mask); i++ {
+		if !mask[i] {
+			continue
+		}
+		start := i
+		for i < len(mask) && mask[i] {
+			i++
+		}
+		ranges = append(ranges, [2]int{start, i})
+	}
+	return ranges
+}
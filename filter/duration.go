@@ -0,0 +1,86 @@
+package filter
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+type durationCondition struct {
+	op    string
+	value time.Duration
+}
+
+func parseDurationCondition(op string, value string) (*durationCondition, error) {
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return nil, err
+	}
+	switch op {
+	case "=", ">", ">=", "<", "<=":
+	default:
+		return nil, fmt.Errorf("unsupported operator: %s", op)
+	}
+	return &durationCondition{
+		op:    op,
+		value: duration,
+	}, nil
+}
+
+// matchDurations returns true if the duration satisfies every condition, so that ranges can be specified for both
+// filters and exclusions e.g. 'duration>1ms&duration<1s'
+func matchDurations(conditions []durationCondition, duration time.Duration) bool {
+	for _, condition := range conditions {
+		if !condition.match(duration) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c durationCondition) match(duration time.Duration) bool {
+	switch c.op {
+	case ">":
+		return duration > c.value
+	case ">=":
+		return duration >= c.value
+	case "<":
+		return duration < c.value
+	case "<=":
+		return duration <= c.value
+	default:
+		return duration == c.value
+	}
+}
+
+// slowestTracker keeps the N longest durations seen so far for each syscall name
+type slowestTracker struct {
+	n       int
+	slowest map[string][]time.Duration // sorted, slowest first
+}
+
+func newSlowestTracker(n int) *slowestTracker {
+	return &slowestTracker{
+		n:       n,
+		slowest: make(map[string][]time.Duration),
+	}
+}
+
+// record returns true if the given duration is one of the N slowest seen so far for the given syscall
+func (s *slowestTracker) record(name string, duration time.Duration) bool {
+	existing := s.slowest[name]
+	if len(existing) >= s.n && duration <= existing[len(existing)-1] {
+		return false
+	}
+	index := sort.Search(len(existing), func(i int) bool {
+		return existing[i] < duration
+	})
+	existing = append(existing, 0)
+	copy(existing[index+1:], existing[index:])
+	existing[index] = duration
+	if len(existing) > s.n {
+		existing = existing[:s.n]
+	}
+	s.slowest[name] = existing
+	return true
+}
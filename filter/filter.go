@@ -8,40 +8,125 @@ import (
 	"github.com/liamg/grace/tracer"
 )
 
+// Filter decides which syscalls are shown. Conditions for the same key are OR'd together, and conditions for
+// different keys are AND'd together. Exclusions are then applied on top: a syscall which matches any exclusion
+// is never shown, regardless of the other conditions.
 type Filter struct {
-	allowNames   []string
-	allowPaths   []string
-	allowReturns []uint64
-	failingOnly  bool
-	passingOnly  bool
+	allow       rules
+	exclude     rules
+	slowest     *slowestTracker
+	failingOnly bool
+	passingOnly bool
 }
 
 func Parse(input string) (*Filter, error) {
 	filter := NewFilter()
+	if err := filter.parse(input, false); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+// Exclude adds the conditions in the given input as exclusions, i.e. as if each one was prefixed with '!'
+func (f *Filter) Exclude(input string) error {
+	return f.parse(input, true)
+}
+
+func (f *Filter) parse(input string, exclude bool) error {
 	parts := strings.Split(input, "&")
 	for _, part := range parts {
 		if part == "" {
 			continue
 		}
-		bits := strings.Split(part, "=")
-		key := bits[0]
-		value := bits[len(bits)-1]
+		negate := exclude
+		if strings.HasPrefix(part, "!") {
+			negate = true
+			part = part[1:]
+		}
+		key, op, value := splitCondition(part)
+		if op == "!=" {
+			negate = true
+			op = "="
+		}
+		if op != "=" && key != "duration" {
+			return fmt.Errorf("invalid operator '%s' for filter key: %s", op, key)
+		}
+		target := &f.allow
+		if negate {
+			target = &f.exclude
+		}
 		switch key {
 		case "syscall", "name", "trace":
-			filter.allowNames = append(filter.allowNames, strings.Split(value, ",")...)
+			target.names = append(target.names, strings.Split(value, ",")...)
 		case "path":
-			filter.allowPaths = append(filter.allowPaths, strings.Split(value, ",")...)
+			for _, pattern := range strings.Split(value, ",") {
+				target.paths = append(target.paths, NewPathPattern(pattern))
+			}
 		case "ret", "retval", "return":
 			ret, err := parseUint64(value)
 			if err != nil {
-				return nil, fmt.Errorf("failed to parse return value filter: %w", err)
+				return fmt.Errorf("failed to parse return value filter: %w", err)
+			}
+			target.returns = append(target.returns, ret)
+		case "pid":
+			pids, err := parseInts(value)
+			if err != nil {
+				return fmt.Errorf("failed to parse pid filter: %w", err)
+			}
+			target.pids = append(target.pids, pids...)
+		case "tid":
+			tids, err := parseInts(value)
+			if err != nil {
+				return fmt.Errorf("failed to parse tid filter: %w", err)
+			}
+			target.tids = append(target.tids, tids...)
+		case "comm":
+			target.comms = append(target.comms, strings.Split(value, ",")...)
+		case "exe":
+			target.exes = append(target.exes, strings.Split(value, ",")...)
+		case "content", "content-regex", "content-hex":
+			pattern, err := parseContentPattern(key, value)
+			if err != nil {
+				return fmt.Errorf("failed to parse %s filter: %w", key, err)
+			}
+			target.contents = append(target.contents, pattern)
+		case "duration":
+			condition, err := parseDurationCondition(op, value)
+			if err != nil {
+				return fmt.Errorf("failed to parse duration filter: %w", err)
+			}
+			target.durations = append(target.durations, *condition)
+		case "slowest":
+			if negate {
+				return fmt.Errorf("the slowest filter cannot be negated")
+			}
+			n, err := strconv.Atoi(value)
+			if err != nil || n <= 0 {
+				return fmt.Errorf("failed to parse slowest filter: '%s' is not a positive integer", value)
 			}
-			filter.allowReturns = append(filter.allowReturns, ret)
+			f.slowest = newSlowestTracker(n)
 		default:
-			return nil, fmt.Errorf("invalid filter key: %s", key)
+			return fmt.Errorf("invalid filter key: %s", key)
 		}
 	}
-	return filter, nil
+	return nil
+}
+
+// splitCondition splits a condition such as "duration>=10ms" into its key, operator and value
+func splitCondition(part string) (string, string, string) {
+	index := strings.IndexAny(part, "<>=")
+	if index < 0 {
+		return part, "=", part
+	}
+	op := part[index : index+1]
+	if op != "=" && strings.HasPrefix(part[index+1:], "=") {
+		op += "="
+	}
+	key := part[:index]
+	if op == "=" && strings.HasSuffix(key, "!") {
+		return key[:len(key)-1], "!=", part[index+1:]
+	}
+	return key, op, part[index+len(op):]
 }
 
 func parseUint64(input string) (uint64, error) {
@@ -51,57 +136,38 @@ func parseUint64(input string) (uint64, error) {
 	return strconv.ParseUint(input, 10, 64)
 }
 
+func parseInts(input string) ([]int, error) {
+	var output []int
+	for _, raw := range strings.Split(input, ",") {
+		i, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, err
+		}
+		output = append(output, i)
+	}
+	return output, nil
+}
+
 func NewFilter() *Filter {
 	return &Filter{}
 }
 
 func (f *Filter) Match(call *tracer.Syscall, exit bool) bool {
 
-	if len(f.allowNames) > 0 {
-		var match bool
-		for _, name := range f.allowNames {
-			if name == call.Name() {
-				match = true
-				break
-			}
-		}
-		if !match {
-			return false
-		}
+	if !f.allow.matchAll(call, exit) {
+		return false
 	}
 
-	if len(f.allowPaths) > 0 {
-		var match bool
-		for _, path := range f.allowPaths {
-			for _, realPath := range call.Paths() {
-				if realPath == path {
-					match = true
-					break
-				}
-			}
-		}
-		if !match {
-			return false
-		}
+	// we can't rule out an exclusion until the return value and output buffers are known
+	if f.exclude.needsExit() && !exit {
+		return false
 	}
 
-	if len(f.allowReturns) > 0 {
-		if !exit {
-			return false
-		}
-		var match bool
-		for _, ret := range f.allowReturns {
-			if uintptr(ret) == call.Return().Raw() {
-				match = true
-				break
-			}
-		}
-		if !match {
-			return false
-		}
+	if f.exclude.matchAny(call, exit) {
+		return false
 	}
 
-	if (f.passingOnly || f.failingOnly) && !exit {
+	if (f.passingOnly || f.failingOnly || f.slowest != nil) && !exit {
 		return false
 	}
 
@@ -115,6 +181,11 @@ func (f *Filter) Match(call *tracer.Syscall, exit bool) bool {
 
 	// TODO check more filters
 
+	// this must come last, as it should only record calls which passed every other filter
+	if f.slowest != nil && !f.slowest.record(call.Name(), call.Duration()) {
+		return false
+	}
+
 	return true
 }
 
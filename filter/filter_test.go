@@ -0,0 +1,299 @@
+package filter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/liamg/grace/internal/tracertest"
+	"github.com/liamg/grace/tracer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ProcessFilters(t *testing.T) {
+
+	proc := tracer.Process{
+		Pid:  100,
+		Tid:  101,
+		Comm: "postgres",
+		Exe:  "/usr/lib/postgresql/bin/postgres",
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  bool
+	}{
+		{
+			name:  "matching pid",
+			input: "pid=100",
+			want:  true,
+		},
+		{
+			name:  "one of several pids",
+			input: "pid=1,2,100",
+			want:  true,
+		},
+		{
+			name:  "mismatched pid",
+			input: "pid=101",
+			want:  false,
+		},
+		{
+			name:  "matching tid",
+			input: "tid=101",
+			want:  true,
+		},
+		{
+			name:  "matching comm",
+			input: "comm=bash&comm=postgres",
+			want:  true,
+		},
+		{
+			name:  "mismatched comm",
+			input: "comm=bash",
+			want:  false,
+		},
+		{
+			name:  "exe by full path",
+			input: "exe=/usr/lib/postgresql/bin/postgres",
+			want:  true,
+		},
+		{
+			name:  "exe by file name",
+			input: "exe=postgres",
+			want:  true,
+		},
+		{
+			name:  "exe with mismatched directory",
+			input: "exe=/usr/bin/postgres",
+			want:  false,
+		},
+		{
+			name:  "different keys are combined",
+			input: "pid=100&comm=bash",
+			want:  false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := Parse(test.input)
+			require.NoError(t, err)
+			assert.Equal(t, test.want, f.allow.matchProcess(proc))
+		})
+	}
+}
+
+func Test_InvalidPidFilter(t *testing.T) {
+	_, err := Parse("pid=abc")
+	assert.Error(t, err)
+}
+
+func Test_SplitCondition(t *testing.T) {
+	tests := []struct {
+		input string
+		key   string
+		op    string
+		value string
+	}{
+		{input: "syscall=openat", key: "syscall", op: "=", value: "openat"},
+		{input: "duration>10ms", key: "duration", op: ">", value: "10ms"},
+		{input: "duration>=1s", key: "duration", op: ">=", value: "1s"},
+		{input: "duration<5us", key: "duration", op: "<", value: "5us"},
+		{input: "duration<=5us", key: "duration", op: "<=", value: "5us"},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			key, op, value := splitCondition(test.input)
+			assert.Equal(t, test.key, key)
+			assert.Equal(t, test.op, op)
+			assert.Equal(t, test.value, value)
+		})
+	}
+}
+
+// call builds an exited syscall which took the given time
+func call(t *testing.T, name string, duration time.Duration) *tracer.Syscall {
+	return tracertest.Syscall{
+		Name:     name,
+		Process:  tracer.Process{Pid: 100},
+		Return:   tracertest.Arg{Type: tracer.ArgTypeInt},
+		Entered:  time.Now(),
+		Duration: duration,
+	}.Build(t)
+}
+
+func Test_DurationFilters(t *testing.T) {
+	f, err := Parse("duration>=10ms&duration<1s")
+	require.NoError(t, err)
+
+	tests := []struct {
+		duration time.Duration
+		want     bool
+	}{
+		{duration: time.Millisecond, want: false},
+		{duration: 10 * time.Millisecond, want: true},
+		{duration: 500 * time.Millisecond, want: true},
+		{duration: time.Second, want: false},
+	}
+	for _, test := range tests {
+		t.Run(test.duration.String(), func(t *testing.T) {
+			assert.Equal(t, test.want, f.Match(call(t, "read", test.duration), true))
+			// durations are only known once the syscall has exited
+			assert.False(t, f.Match(call(t, "read", test.duration), false))
+		})
+	}
+
+	_, err = Parse("syscall>read")
+	assert.Error(t, err)
+}
+
+func Test_SlowestFilter(t *testing.T) {
+	f, err := Parse("syscall=read&slowest=2")
+	require.NoError(t, err)
+
+	assert.True(t, f.Match(call(t, "read", 5*time.Millisecond), true))
+	assert.True(t, f.Match(call(t, "read", time.Millisecond), true))
+	assert.False(t, f.Match(call(t, "read", 500*time.Microsecond), true))
+	assert.True(t, f.Match(call(t, "read", 2*time.Millisecond), true))
+	assert.False(t, f.Match(call(t, "read", time.Millisecond), true))
+	// calls which don't pass the other filters aren't counted
+	assert.False(t, f.Match(call(t, "write", time.Second), true))
+	assert.False(t, f.Match(call(t, "read", time.Second), false))
+	assert.True(t, f.Match(call(t, "read", 3*time.Millisecond), true))
+}
+
+func Test_SlowestTracker(t *testing.T) {
+	tracker := newSlowestTracker(2)
+	assert.True(t, tracker.record("read", 5*time.Millisecond))
+	assert.True(t, tracker.record("read", 1*time.Millisecond))
+	assert.False(t, tracker.record("read", 500*time.Microsecond))
+	assert.True(t, tracker.record("read", 2*time.Millisecond))
+	assert.False(t, tracker.record("read", time.Millisecond))
+	assert.True(t, tracker.record("write", time.Microsecond))
+	assert.Equal(t, []time.Duration{5 * time.Millisecond, 2 * time.Millisecond}, tracker.slowest["read"])
+}
+
+func Test_ContentHighlight(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		data  string
+		want  [][2]int
+	}{
+		{
+			name:  "substring",
+			input: "content=root",
+			data:  "root:x:0:0:root",
+			want:  [][2]int{{0, 4}, {11, 15}},
+		},
+		{
+			name:  "regex",
+			input: "content-regex=[0-9]+",
+			data:  "uid 1000 gid 50",
+			want:  [][2]int{{4, 8}, {13, 15}},
+		},
+		{
+			name:  "hex bytes",
+			input: "content-hex=0x0d0a",
+			data:  "HTTP/1.1 200 OK\r\n",
+			want:  [][2]int{{15, 17}},
+		},
+		{
+			name:  "overlapping patterns are merged",
+			input: "content=abc&content=bcd",
+			data:  "xabcdx",
+			want:  [][2]int{{1, 5}},
+		},
+		{
+			name:  "no match",
+			input: "content=secret",
+			data:  "nothing to see here",
+			want:  nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := Parse(test.input)
+			require.NoError(t, err)
+			assert.Equal(t, test.want, f.Highlight([]byte(test.data)))
+		})
+	}
+}
+
+func Test_Exclusions(t *testing.T) {
+	f, err := Parse("syscall=openat,read&!path=/proc/*&comm!=bash")
+	require.NoError(t, err)
+	require.NoError(t, f.Exclude("syscall=futex,mmap&duration<1us"))
+	assert.Equal(t, []string{"openat", "read"}, f.allow.names)
+	assert.True(t, f.exclude.needsExit())
+	assert.False(t, f.allow.needsExit())
+
+	build := func(name string, comm string, path string, duration time.Duration) *tracer.Syscall {
+		var paths []string
+		if path != "" {
+			paths = []string{path}
+		}
+		return tracertest.Syscall{
+			Name:     name,
+			Process:  tracer.Process{Pid: 100, Comm: comm},
+			Paths:    paths,
+			Return:   tracertest.Arg{Type: tracer.ArgTypeInt},
+			Entered:  time.Now(),
+			Duration: duration,
+		}.Build(t)
+	}
+
+	tests := []struct {
+		name string
+		call *tracer.Syscall
+		want bool
+	}{
+		{name: "allowed", call: build("openat", "cat", "/etc/passwd", time.Millisecond), want: true},
+		{name: "not allowed", call: build("close", "cat", "", time.Millisecond), want: false},
+		{name: "excluded path", call: build("openat", "cat", "/proc/self/status", time.Millisecond), want: false},
+		{name: "excluded comm", call: build("openat", "bash", "/etc/passwd", time.Millisecond), want: false},
+		{name: "fast call is excluded", call: build("read", "cat", "", 100*time.Nanosecond), want: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, f.Match(test.call, true))
+		})
+	}
+
+	// an exclusion which needs the return value or duration can't be ruled out until the syscall has exited
+	assert.False(t, f.Match(build("openat", "cat", "/etc/passwd", 0), false))
+
+	_, err = Parse("!slowest=3")
+	assert.Error(t, err)
+}
+
+func Test_DurationRangeExclusion(t *testing.T) {
+	f := NewFilter()
+	require.NoError(t, f.Exclude("duration>1ms&duration<1s"))
+	assert.True(t, f.Match(call(t, "read", 500*time.Microsecond), true))
+	assert.False(t, f.Match(call(t, "read", 10*time.Millisecond), true))
+	assert.True(t, f.Match(call(t, "read", 2*time.Second), true))
+}
+
+func Test_PathPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{pattern: "/etc/passwd", path: "/etc/passwd", want: true},
+		{pattern: "/etc/passwd", path: "/etc/passwd2", want: false},
+		{pattern: "/proc/*", path: "/proc/self/status", want: true},
+		{pattern: "/proc/*", path: "/procfs", want: false},
+		{pattern: "/var/log/*.log", path: "/var/log/nginx/access.log", want: true},
+		{pattern: "/dev/tty?", path: "/dev/tty1", want: true},
+		{pattern: "/tmp/[a]", path: "/tmp/[a]", want: true},
+	}
+	for _, test := range tests {
+		t.Run(test.pattern+" "+test.path, func(t *testing.T) {
+			assert.Equal(t, test.want, NewPathPattern(test.pattern).Match(test.path))
+		})
+	}
+}
@@ -0,0 +1,246 @@
+package filter
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/liamg/grace/tracer"
+)
+
+// rules holds the conditions for each filter key
+type rules struct {
+	names     []string
+	paths     []*PathPattern
+	returns   []uint64
+	pids      []int
+	tids      []int
+	comms     []string
+	exes      []string
+	contents  []*contentPattern
+	durations []durationCondition
+}
+
+// matchAll returns true if the call satisfies at least one condition for every key which has conditions
+func (r *rules) matchAll(call *tracer.Syscall, exit bool) bool {
+
+	if len(r.names) > 0 && !r.matchName(call) {
+		return false
+	}
+
+	if len(r.paths) > 0 && !r.matchPath(call) {
+		return false
+	}
+
+	if len(r.pids) > 0 || len(r.tids) > 0 || len(r.comms) > 0 || len(r.exes) > 0 {
+		if !r.matchProcess(call.Process()) {
+			return false
+		}
+	}
+
+	if len(r.contents) > 0 && !matchContent(r.contents, call.Args()) {
+		return false
+	}
+
+	if len(r.returns) > 0 {
+		if !exit {
+			return false
+		}
+		if !r.matchReturn(call) {
+			return false
+		}
+	}
+
+	if len(r.durations) > 0 {
+		// durations are only known once the syscall has exited
+		if !exit {
+			return false
+		}
+		if !matchDurations(r.durations, call.Duration()) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchAny returns true if the call satisfies any condition for any key
+func (r *rules) matchAny(call *tracer.Syscall, exit bool) bool {
+
+	if len(r.names) > 0 && r.matchName(call) {
+		return true
+	}
+
+	if len(r.paths) > 0 && r.matchPath(call) {
+		return true
+	}
+
+	proc := call.Process()
+	for _, pid := range r.pids {
+		if pid == proc.Pid {
+			return true
+		}
+	}
+	for _, tid := range r.tids {
+		if tid == proc.Tid {
+			return true
+		}
+	}
+	for _, comm := range r.comms {
+		if comm == proc.Comm {
+			return true
+		}
+	}
+	for _, exe := range r.exes {
+		if matchExe(exe, proc.Exe) {
+			return true
+		}
+	}
+
+	if len(r.contents) > 0 && matchContent(r.contents, call.Args()) {
+		return true
+	}
+
+	if exit {
+		if len(r.returns) > 0 && r.matchReturn(call) {
+			return true
+		}
+		if len(r.durations) > 0 && matchDurations(r.durations, call.Duration()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// needsExit returns true if the rules refer to anything which is only known once a syscall has exited
+func (r *rules) needsExit() bool {
+	return len(r.returns) > 0 || len(r.durations) > 0 || len(r.contents) > 0
+}
+
+func (r *rules) matchName(call *tracer.Syscall) bool {
+	for _, name := range r.names {
+		if name == call.Name() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *rules) matchPath(call *tracer.Syscall) bool {
+	for _, pattern := range r.paths {
+		for _, realPath := range call.Paths() {
+			if pattern.Match(realPath) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (r *rules) matchReturn(call *tracer.Syscall) bool {
+	for _, ret := range r.returns {
+		if uintptr(ret) == call.Return().Raw() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *rules) matchProcess(proc tracer.Process) bool {
+
+	if len(r.pids) > 0 {
+		var match bool
+		for _, pid := range r.pids {
+			if pid == proc.Pid {
+				match = true
+				break
+			}
+		}
+		if !match {
+			return false
+		}
+	}
+
+	if len(r.tids) > 0 {
+		var match bool
+		for _, tid := range r.tids {
+			if tid == proc.Tid {
+				match = true
+				break
+			}
+		}
+		if !match {
+			return false
+		}
+	}
+
+	if len(r.comms) > 0 {
+		var match bool
+		for _, comm := range r.comms {
+			if comm == proc.Comm {
+				match = true
+				break
+			}
+		}
+		if !match {
+			return false
+		}
+	}
+
+	if len(r.exes) > 0 {
+		var match bool
+		for _, exe := range r.exes {
+			if matchExe(exe, proc.Exe) {
+				match = true
+				break
+			}
+		}
+		if !match {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchExe matches on the full path, or just the file name if no directory was given
+func matchExe(pattern string, exe string) bool {
+	return pattern == exe || (!strings.Contains(pattern, "/") && pattern == filepath.Base(exe))
+}
+
+// PathPattern matches a path exactly, or as a glob if the pattern contains wildcards. Unlike filepath.Match,
+// '*' also matches '/', so e.g. '/proc/*' matches everything under /proc.
+type PathPattern struct {
+	path string
+	glob *regexp.Regexp // nil if the pattern has no wildcards
+}
+
+// NewPathPattern compiles the given pattern, so that it can be matched against many paths
+func NewPathPattern(pattern string) *PathPattern {
+	if !strings.ContainsAny(pattern, "*?") {
+		return &PathPattern{path: pattern}
+	}
+	var expr strings.Builder
+	expr.WriteString("^")
+	for _, c := range pattern {
+		switch c {
+		case '*':
+			expr.WriteString(".*")
+		case '?':
+			expr.WriteString(".")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	expr.WriteString("$")
+	return &PathPattern{path: pattern, glob: regexp.MustCompile(expr.String())}
+}
+
+// Match returns true if the path matches the pattern
+func (p *PathPattern) Match(path string) bool {
+	if p.glob == nil {
+		return p.path == path
+	}
+	return p.glob.MatchString(path)
+}
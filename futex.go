@@ -0,0 +1,18 @@
+package main
+
+import (
+	"io"
+
+	"github.com/liamg/grace/futex"
+	"github.com/liamg/grace/tracer"
+)
+
+// configureFutex sets up a report of the most contended futexes, which is printed when the tracer detaches
+func configureFutex(t *tracer.Tracer, w io.Writer) {
+	a := futex.New(w)
+	a.SetMaxRows(flagFutexRows)
+	t.AddSyscallExitHandler(a.HandleSyscallExit)
+	t.AddDetachHandler(func(int) {
+		a.Print()
+	})
+}
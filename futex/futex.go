@@ -0,0 +1,251 @@
+package futex
+
+import (
+	"io"
+	"syscall"
+	"time"
+
+	"github.com/liamg/grace/tracer"
+)
+
+// Analysis tracks waits on and wakes of each futex address, and which threads woke which, and prints a report of the
+// most contended addresses when the trace ends
+type Analysis struct {
+	w       io.Writer
+	futexes map[futexKey]*Futex
+	comms   map[int]string // name of each thread, by tid
+	maps    map[int][]region
+	symbols map[string]*symbolTable
+	maxRows int
+}
+
+// Futex holds the statistics for a single futex address
+type Futex struct {
+	Pid      int
+	Addr     uint64
+	Location string // the memory region or symbol the address is in
+	Waits    int
+	Timeouts int
+	Total    time.Duration // total time spent waiting
+	Max      time.Duration
+	Wakes    int          // number of calls which tried to wake waiters
+	Woken    int          // number of waiters the kernel reported as woken
+	Requeued int          // number of waiters moved to this address from another one
+	Waiters  map[int]int  // number of waits made by each thread
+	Pairs    map[Pair]int // number of times each waker woke each waiter
+
+	wakes   []wakeEvent // recent wakes which haven't been matched to all of the waiters they woke
+	waiting []waitEvent // recently woken waits which haven't been matched to a waker
+}
+
+// Pair is a thread which woke another thread waiting on a futex
+type Pair struct {
+	Waker  int
+	Waiter int
+}
+
+type futexKey struct {
+	pid  int
+	addr uint64
+}
+
+type wakeEvent struct {
+	tid       int
+	at        time.Time
+	remaining int
+}
+
+type waitEvent struct {
+	tid     int
+	entered time.Time
+	exited  time.Time
+}
+
+// maxPending limits the number of unmatched wakes and waits kept for each address
+const maxPending = 32
+
+// futex operations, without the FUTEX_PRIVATE_FLAG and FUTEX_CLOCK_REALTIME flags
+const (
+	opWait          = 0
+	opWake          = 1
+	opRequeue       = 3
+	opCmpRequeue    = 4
+	opWakeOp        = 5
+	opLockPI        = 6
+	opUnlockPI      = 7
+	opWaitBitset    = 9
+	opWakeBitset    = 10
+	opWaitRequeuePI = 11
+	opCmpRequeuePI  = 12
+	opLockPI2       = 13
+
+	opFlags = 128 | 256
+)
+
+var waitOps = map[int]bool{
+	opWait:          true,
+	opLockPI:        true,
+	opWaitBitset:    true,
+	opWaitRequeuePI: true,
+	opLockPI2:       true,
+}
+
+var wakeOps = map[int]bool{
+	opWake:         true,
+	opRequeue:      true,
+	opCmpRequeue:   true,
+	opWakeOp:       true,
+	opUnlockPI:     true,
+	opWakeBitset:   true,
+	opCmpRequeuePI: true,
+}
+
+// IsWait returns true if the given futex op argument, which may include flags, is an operation which waits
+func IsWait(op int) bool {
+	return waitOps[op&^opFlags]
+}
+
+// IsWake returns true if the given futex op argument, which may include flags, is an operation which wakes waiters
+func IsWake(op int) bool {
+	return wakeOps[op&^opFlags]
+}
+
+// New creates a futex analysis which will be printed to the given writer
+func New(w io.Writer) *Analysis {
+	return &Analysis{
+		w:       w,
+		futexes: make(map[futexKey]*Futex),
+		comms:   make(map[int]string),
+		maps:    make(map[int][]region),
+		symbols: make(map[string]*symbolTable),
+		maxRows: 10,
+	}
+}
+
+// SetMaxRows limits the number of addresses listed in the report, or lists all of them if max is 0
+func (a *Analysis) SetMaxRows(max int) {
+	a.maxRows = max
+}
+
+// HandleSyscallExit records a futex wait or wake
+func (a *Analysis) HandleSyscallExit(call *tracer.Syscall) {
+	if call.Name() != "futex" {
+		return
+	}
+	args := call.Args()
+	if len(args) < 2 {
+		return
+	}
+	op := args[1].Int()
+	if !IsWait(op) && !IsWake(op) {
+		return
+	}
+	proc := call.Process()
+	if proc.Comm != "" {
+		a.comms[proc.Tid] = proc.Comm
+	}
+	f := a.futex(proc.Pid, uint64(args[0].Raw()))
+	ret := call.Return().Int()
+	if IsWait(op) {
+		a.wait(f, proc.Tid, call.EnterTime(), call.ExitTime(), ret)
+		return
+	}
+	if ret < 0 {
+		return
+	}
+	switch op &^ opFlags {
+	case opUnlockPI:
+		// the lock is handed over to the highest priority waiter, if there is one
+		ret = 1
+	case opRequeue, opCmpRequeue, opCmpRequeuePI:
+		ret = a.requeue(proc.Pid, args, ret)
+	}
+	a.wake(f, proc.Tid, call.EnterTime(), ret)
+}
+
+// requeue records the waiters a requeue moved to uaddr2, and returns the number it woke. The kernel returns the
+// number of waiters woken plus the number requeued, and wakes up to val of them before requeueing the rest.
+func (a *Analysis) requeue(pid int, args []tracer.Arg, ret int) int {
+	woken := ret
+	if len(args) > 2 && args[2].Int() < woken {
+		woken = args[2].Int()
+	}
+	if woken < 0 {
+		woken = 0
+	}
+	if requeued := ret - woken; requeued > 0 && len(args) > 4 {
+		a.futex(pid, uint64(args[4].Raw())).Requeued += requeued
+	}
+	return woken
+}
+
+func (a *Analysis) futex(pid int, addr uint64) *Futex {
+	key := futexKey{pid: pid, addr: addr}
+	f, ok := a.futexes[key]
+	if !ok {
+		f = &Futex{
+			Pid:      pid,
+			Addr:     addr,
+			Location: a.locate(pid, addr),
+			Waiters:  make(map[int]int),
+			Pairs:    make(map[Pair]int),
+		}
+		a.futexes[key] = f
+	}
+	return f
+}
+
+// wait records a thread waiting on a futex. If it was woken, the thread which woke it is found by matching it with a
+// wake which was made while it was waiting. The exits of the waker and waiter can be seen in either order, so
+// whichever is seen first is kept until the other arrives.
+func (a *Analysis) wait(f *Futex, tid int, entered time.Time, exited time.Time, ret int) {
+	duration := exited.Sub(entered)
+	f.Waits++
+	f.Waiters[tid]++
+	f.Total += duration
+	if duration > f.Max {
+		f.Max = duration
+	}
+	switch {
+	case ret == -int(syscall.ETIMEDOUT):
+		f.Timeouts++
+		return
+	case ret < 0:
+		return
+	}
+	for i := len(f.wakes) - 1; i >= 0; i-- {
+		wake := &f.wakes[i]
+		if wake.remaining > 0 && wake.tid != tid && !wake.at.Before(entered) && !wake.at.After(exited) {
+			f.Pairs[Pair{Waker: wake.tid, Waiter: tid}]++
+			wake.remaining--
+			return
+		}
+	}
+	f.waiting = append(f.waiting, waitEvent{tid: tid, entered: entered, exited: exited})
+	if len(f.waiting) > maxPending {
+		f.waiting = f.waiting[1:]
+	}
+}
+
+// wake records a thread waking n waiters on a futex
+func (a *Analysis) wake(f *Futex, tid int, at time.Time, n int) {
+	f.Wakes++
+	f.Woken += n
+	var unmatched []waitEvent
+	for _, wait := range f.waiting {
+		if n > 0 && wait.tid != tid && !at.Before(wait.entered) && !at.After(wait.exited) {
+			f.Pairs[Pair{Waker: tid, Waiter: wait.tid}]++
+			n--
+			continue
+		}
+		unmatched = append(unmatched, wait)
+	}
+	f.waiting = unmatched
+	if n == 0 {
+		return
+	}
+	f.wakes = append(f.wakes, wakeEvent{tid: tid, at: at, remaining: n})
+	if len(f.wakes) > maxPending {
+		f.wakes = f.wakes[1:]
+	}
+}
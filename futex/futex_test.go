@@ -0,0 +1,153 @@
+package futex
+
+import (
+	"bytes"
+	"debug/elf"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/liamg/grace/internal/tracertest"
+	"github.com/liamg/grace/tracer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WakersAreMatchedWithWaiters(t *testing.T) {
+	a := New(&bytes.Buffer{})
+	f := a.futex(1, 0x1000)
+	start := time.Now()
+	at := func(ms int) time.Time {
+		return start.Add(time.Duration(ms) * time.Millisecond)
+	}
+
+	// the waiter's exit is seen before the waker's
+	a.wait(f, 2, at(0), at(10), 0)
+	a.wake(f, 3, at(9), 1)
+
+	// the waker's exit is seen before the waiter's
+	a.wake(f, 2, at(20), 1)
+	a.wait(f, 3, at(15), at(21), 0)
+
+	// a wake which didn't happen while the thread was waiting doesn't count
+	a.wake(f, 4, at(30), 1)
+	a.wait(f, 3, at(40), at(50), 0)
+
+	// timeouts aren't woken by anyone
+	a.wait(f, 2, at(60), at(70), -int(syscall.ETIMEDOUT))
+
+	assert.Equal(t, 4, f.Waits)
+	assert.Equal(t, 1, f.Timeouts)
+	assert.Equal(t, 3, f.Wakes)
+	assert.Equal(t, 3, f.Woken)
+	assert.Equal(t, 36*time.Millisecond, f.Total)
+	assert.Equal(t, 10*time.Millisecond, f.Max)
+	assert.Equal(t, map[int]int{2: 2, 3: 2}, f.Waiters)
+	assert.Equal(t, map[Pair]int{
+		{Waker: 3, Waiter: 2}: 1,
+		{Waker: 2, Waiter: 3}: 1,
+	}, f.Pairs)
+}
+
+func Test_RequeuedWaitersAreCountedOnTheTarget(t *testing.T) {
+	a := New(&bytes.Buffer{})
+	// FUTEX_CMP_REQUEUE|FUTEX_PRIVATE_FLAG waking 1 waiter and moving the other 3 to 0x2000
+	call := tracertest.Syscall{
+		Name:    "futex",
+		Process: tracer.Process{Pid: 1, Tid: 2},
+		Args: []tracertest.Arg{
+			{Name: "uaddr", Type: tracer.ArgTypeAddress, Raw: 0x1000},
+			{Name: "op", Type: tracer.ArgTypeInt, Raw: 4 | 128},
+			{Name: "val", Type: tracer.ArgTypeInt, Raw: 1},
+			{Name: "val2", Type: tracer.ArgTypeInt, Raw: 100},
+			{Name: "uaddr2", Type: tracer.ArgTypeAddress, Raw: 0x2000},
+		},
+		Return: tracertest.Arg{Type: tracer.ArgTypeInt, Raw: 4},
+	}.Build(t)
+	a.HandleSyscallExit(call)
+
+	source := a.futexes[futexKey{pid: 1, addr: 0x1000}]
+	require.NotNil(t, source)
+	assert.Equal(t, 1, source.Wakes)
+	assert.Equal(t, 1, source.Woken)
+	assert.Equal(t, 0, source.Requeued)
+
+	target := a.futexes[futexKey{pid: 1, addr: 0x2000}]
+	require.NotNil(t, target)
+	assert.Equal(t, 0, target.Wakes)
+	assert.Equal(t, 0, target.Woken)
+	assert.Equal(t, 3, target.Requeued)
+}
+
+func Test_FutexesAreRankedByWaitTime(t *testing.T) {
+	a := New(&bytes.Buffer{})
+	now := time.Now()
+	a.wait(a.futex(1, 0x1000), 2, now, now.Add(time.Millisecond), 0)
+	a.wait(a.futex(1, 0x2000), 2, now, now.Add(time.Second), 0)
+	a.wake(a.futex(1, 0x3000), 2, now, 0)
+
+	var addrs []uint64
+	for _, f := range a.Futexes() {
+		addrs = append(addrs, f.Addr)
+	}
+	assert.Equal(t, []uint64{0x2000, 0x1000, 0x3000}, addrs)
+}
+
+func Test_ParseMaps(t *testing.T) {
+	regions := parseMaps([]byte(`55d0c4a00000-55d0c4a21000 rw-p 00000000 00:00 0                          [heap]
+7f2a1e3d5000-7f2a1e3fb000 r--p 00000000 08:01 1054123                    /usr/lib/x86_64-linux-gnu/libc.so.6
+7f2a1e3fb000-7f2a1e550000 r-xp 00026000 08:01 1054123                    /usr/lib/x86_64-linux-gnu/libc.so.6
+7f2a1e600000-7f2a1e610000 rw-p 00000000 00:00 0
+`))
+	require.Len(t, regions, 4)
+	assert.Equal(t, region{start: 0x55d0c4a00000, end: 0x55d0c4a21000, path: "[heap]"}, regions[0])
+	assert.Equal(t, region{start: 0x7f2a1e3fb000, end: 0x7f2a1e550000, offset: 0x26000, path: "/usr/lib/x86_64-linux-gnu/libc.so.6"}, regions[2])
+	assert.Equal(t, "", regions[3].path)
+}
+
+func Test_Locate(t *testing.T) {
+	a := New(&bytes.Buffer{})
+	a.maps[1] = []region{
+		{start: 0x1000, end: 0x2000, path: "[heap]"},
+		{start: 0x10000, end: 0x11000, path: "/nonexistent/libfoo.so"},
+		{start: 0x11000, end: 0x12000, offset: 0x1000, path: "/nonexistent/libfoo.so"},
+		{start: 0x12000, end: 0x13000},
+		{start: 0x20000, end: 0x21000},
+	}
+
+	tests := []struct {
+		addr     uint64
+		expected string
+	}{
+		{addr: 0x1100, expected: "[heap]"},
+		{addr: 0x11234, expected: "libfoo.so+0x1234"},
+		{addr: 0x12010, expected: "libfoo.so+0x2010"},
+		{addr: 0x20010, expected: "anonymous"},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expected, a.locate(1, test.addr))
+	}
+}
+
+func Test_SymbolLookup(t *testing.T) {
+	table := &symbolTable{
+		symbols: []elf.Symbol{
+			{Name: "global_lock", Value: 0x4000, Size: 40},
+			{Name: "other_lock", Value: 0x4100, Size: 40},
+		},
+	}
+
+	name, ok := table.lookup(0x4000)
+	require.True(t, ok)
+	assert.Equal(t, "global_lock", name)
+
+	name, ok = table.lookup(0x4110)
+	require.True(t, ok)
+	assert.Equal(t, "other_lock+0x10", name)
+
+	_, ok = table.lookup(0x4050)
+	assert.False(t, ok)
+
+	_, ok = table.lookup(0x100)
+	assert.False(t, ok)
+}
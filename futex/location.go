@@ -0,0 +1,193 @@
+package futex
+
+import (
+	"bufio"
+	"bytes"
+	"debug/elf"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/liamg/grace/tracer/procfs"
+)
+
+// region is a memory mapping from /proc/<pid>/maps
+type region struct {
+	start  uint64
+	end    uint64
+	offset uint64
+	path   string
+}
+
+// symbolTable holds the data symbols of an ELF file, ordered by address
+type symbolTable struct {
+	symbols []elf.Symbol
+	dynamic bool // whether the addresses are relative to where the file is loaded
+	bias    uint64
+}
+
+// locate describes where a futex address is, using the memory mappings of the process. Addresses in the data of an
+// executable or library are resolved to a symbol if it has one, e.g. a global mutex.
+func (a *Analysis) locate(pid int, addr uint64) string {
+	regions := a.maps[pid]
+	index := findRegion(regions, addr)
+	if index < 0 {
+		// the address may have been mapped since the maps were last read
+		var err error
+		if regions, err = readMaps(pid); err != nil {
+			return ""
+		}
+		a.maps[pid] = regions
+		if index = findRegion(regions, addr); index < 0 {
+			return ""
+		}
+	}
+	r := regions[index]
+	path := r.path
+	// the .bss section of a file follows its last mapping, but is anonymous
+	if path == "" && index > 0 && regions[index-1].end == r.start && strings.HasPrefix(regions[index-1].path, "/") {
+		path = regions[index-1].path
+	}
+	if !strings.HasPrefix(path, "/") {
+		if path == "" {
+			return "anonymous"
+		}
+		return path
+	}
+	base := loadBase(regions, path)
+	if symbol, ok := a.symbolize(path, base, addr); ok {
+		return fmt.Sprintf("%s (%s)", symbol, filepath.Base(path))
+	}
+	return fmt.Sprintf("%s+0x%x", filepath.Base(path), addr-base)
+}
+
+func findRegion(regions []region, addr uint64) int {
+	for i, r := range regions {
+		if addr >= r.start && addr < r.end {
+			return i
+		}
+	}
+	return -1
+}
+
+// loadBase returns the address the first mapping of the given file is loaded at
+func loadBase(regions []region, path string) uint64 {
+	for _, r := range regions {
+		if r.path == path && r.offset == 0 {
+			return r.start
+		}
+	}
+	return 0
+}
+
+func readMaps(pid int) ([]region, error) {
+	data, err := procfs.ReadFile(fmt.Sprintf("/proc/%d/maps", pid))
+	if err != nil {
+		return nil, err
+	}
+	return parseMaps(data), nil
+}
+
+// parseMaps parses the contents of /proc/<pid>/maps e.g.
+// 7f2a1c000000-7f2a1c021000 rw-p 00000000 00:00 0
+// 7f2a1e3d5000-7f2a1e3fb000 r--p 00000000 08:01 1054123    /usr/lib/x86_64-linux-gnu/libc.so.6
+func parseMaps(data []byte) []region {
+	var regions []region
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 {
+			continue
+		}
+		bounds := strings.SplitN(fields[0], "-", 2)
+		if len(bounds) != 2 {
+			continue
+		}
+		start, err := strconv.ParseUint(bounds[0], 16, 64)
+		if err != nil {
+			continue
+		}
+		end, err := strconv.ParseUint(bounds[1], 16, 64)
+		if err != nil {
+			continue
+		}
+		offset, err := strconv.ParseUint(fields[2], 16, 64)
+		if err != nil {
+			continue
+		}
+		r := region{start: start, end: end, offset: offset}
+		if len(fields) > 5 {
+			r.path = strings.Join(fields[5:], " ")
+		}
+		regions = append(regions, r)
+	}
+	return regions
+}
+
+// symbolize returns the symbol containing an address in a file loaded at the given base address
+func (a *Analysis) symbolize(path string, base uint64, addr uint64) (string, bool) {
+	table, ok := a.symbols[path]
+	if !ok {
+		table = readSymbols(path)
+		a.symbols[path] = table
+	}
+	if table == nil {
+		return "", false
+	}
+	vaddr := addr
+	if table.dynamic {
+		vaddr = addr - base + table.bias
+	}
+	return table.lookup(vaddr)
+}
+
+func readSymbols(path string) *symbolTable {
+	f, err := elf.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer func() { _ = f.Close() }()
+
+	table := &symbolTable{dynamic: f.Type == elf.ET_DYN}
+	for _, prog := range f.Progs {
+		if prog.Type == elf.PT_LOAD {
+			table.bias = prog.Vaddr &^ (prog.Align - 1)
+			break
+		}
+	}
+
+	seen := make(map[uint64]bool)
+	symbols, _ := f.Symbols()
+	dynamic, _ := f.DynamicSymbols()
+	for _, symbol := range append(symbols, dynamic...) {
+		if elf.ST_TYPE(symbol.Info) != elf.STT_OBJECT || symbol.Size == 0 || seen[symbol.Value] {
+			continue
+		}
+		seen[symbol.Value] = true
+		table.symbols = append(table.symbols, symbol)
+	}
+	sort.Slice(table.symbols, func(i, j int) bool {
+		return table.symbols[i].Value < table.symbols[j].Value
+	})
+	return table
+}
+
+// lookup returns the name of the symbol containing the address, with the offset into it if there is one
+func (t *symbolTable) lookup(vaddr uint64) (string, bool) {
+	i := sort.Search(len(t.symbols), func(i int) bool {
+		return t.symbols[i].Value > vaddr
+	}) - 1
+	if i < 0 {
+		return "", false
+	}
+	symbol := t.symbols[i]
+	if vaddr >= symbol.Value+symbol.Size {
+		return "", false
+	}
+	if offset := vaddr - symbol.Value; offset > 0 {
+		return fmt.Sprintf("%s+0x%x", symbol.Name, offset), true
+	}
+	return symbol.Name, true
+}
@@ -0,0 +1,127 @@
+package futex
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/aquasecurity/table"
+)
+
+// Futexes returns each futex which was waited on or woken, the longest total wait first
+func (a *Analysis) Futexes() []*Futex {
+	var all []*Futex
+	for _, f := range a.futexes {
+		all = append(all, f)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Total != all[j].Total {
+			return all[i].Total > all[j].Total
+		}
+		if all[i].Waits != all[j].Waits {
+			return all[i].Waits > all[j].Waits
+		}
+		if all[i].Pid != all[j].Pid {
+			return all[i].Pid < all[j].Pid
+		}
+		return all[i].Addr < all[j].Addr
+	})
+	return all
+}
+
+// SortedPairs returns the threads which woke other threads waiting on the futex, the most frequent first
+func (f *Futex) SortedPairs() []Pair {
+	var pairs []Pair
+	for pair := range f.Pairs {
+		pairs = append(pairs, pair)
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if f.Pairs[pairs[i]] != f.Pairs[pairs[j]] {
+			return f.Pairs[pairs[i]] > f.Pairs[pairs[j]]
+		}
+		if pairs[i].Waker != pairs[j].Waker {
+			return pairs[i].Waker < pairs[j].Waker
+		}
+		return pairs[i].Waiter < pairs[j].Waiter
+	})
+	return pairs
+}
+
+// Print writes a table of the most contended futexes, followed by which threads woke which for each of them
+func (a *Analysis) Print() {
+
+	futexes := a.Futexes()
+	if a.maxRows > 0 && len(futexes) > a.maxRows {
+		futexes = futexes[:a.maxRows]
+	}
+
+	tab := table.New(a.w)
+	tab.SetRowLines(false)
+	tab.AddHeaders("seconds", "waits", "timeouts", "max", "wakes", "woken", "requeued", "threads", "pid", "address", "location")
+	tab.SetAlignment(
+		table.AlignRight, table.AlignRight, table.AlignRight, table.AlignRight, table.AlignRight,
+		table.AlignRight, table.AlignRight, table.AlignRight, table.AlignRight, table.AlignRight, table.AlignLeft,
+	)
+	tab.SetLineStyle(table.StyleBlue)
+
+	for _, f := range futexes {
+		tab.AddRow(
+			fmt.Sprintf("%.6f", f.Total.Seconds()),
+			fmt.Sprintf("%d", f.Waits),
+			fmt.Sprintf("%d", f.Timeouts),
+			fmt.Sprintf("%.6f", f.Max.Seconds()),
+			fmt.Sprintf("%d", f.Wakes),
+			fmt.Sprintf("%d", f.Woken),
+			fmt.Sprintf("%d", f.Requeued),
+			fmt.Sprintf("%d", len(f.Waiters)),
+			fmt.Sprintf("%d", f.Pid),
+			fmt.Sprintf("0x%x", f.Addr),
+			f.Location,
+		)
+	}
+
+	tab.Render()
+
+	if len(a.futexes) > len(futexes) {
+		_, _ = fmt.Fprintf(a.w, "... and %d more\n", len(a.futexes)-len(futexes))
+	}
+
+	for _, f := range futexes {
+		if len(f.Pairs) == 0 {
+			continue
+		}
+		label := fmt.Sprintf("0x%x in pid %d", f.Addr, f.Pid)
+		if f.Location != "" {
+			label += ", " + f.Location
+		}
+		_, _ = fmt.Fprintf(a.w, "\nwakeups on %s\n", label)
+
+		wakeups := table.New(a.w)
+		wakeups.SetRowLines(false)
+		wakeups.AddHeaders("count", "waker", "woken")
+		wakeups.SetAlignment(table.AlignRight, table.AlignLeft, table.AlignLeft)
+		wakeups.SetLineStyle(table.StyleBlue)
+		pairs := f.SortedPairs()
+		for i, pair := range pairs {
+			if a.maxRows > 0 && i == a.maxRows {
+				break
+			}
+			wakeups.AddRow(
+				fmt.Sprintf("%d", f.Pairs[pair]),
+				a.thread(pair.Waker),
+				a.thread(pair.Waiter),
+			)
+		}
+		wakeups.Render()
+		if a.maxRows > 0 && len(pairs) > a.maxRows {
+			_, _ = fmt.Fprintf(a.w, "... and %d more\n", len(pairs)-a.maxRows)
+		}
+	}
+}
+
+// thread returns a label for a thread, including its name if it's known
+func (a *Analysis) thread(tid int) string {
+	if comm, ok := a.comms[tid]; ok {
+		return fmt.Sprintf("tid %d (%s)", tid, comm)
+	}
+	return fmt.Sprintf("tid %d", tid)
+}
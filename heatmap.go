@@ -0,0 +1,39 @@
+package main
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/liamg/grace/heatmap"
+	"github.com/liamg/grace/tracer"
+	"golang.org/x/term"
+)
+
+// configureHeatmap sets up a heatmap of syscalls over time, which is printed when the tracer detaches
+func configureHeatmap(t *tracer.Tracer, w io.Writer, started time.Time) error {
+	h := heatmap.New(w)
+	h.SetStartTime(started)
+	if err := h.SetGroupBy(heatmap.GroupBy(flagHeatmapBy)); err != nil {
+		return err
+	}
+	if err := h.SetInterval(flagHeatmapInterval); err != nil {
+		return err
+	}
+	h.SetMaxRows(flagHeatmapRows)
+	if f, ok := w.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		if width, _, err := term.GetSize(int(f.Fd())); err == nil {
+			h.SetWidth(width)
+		}
+	}
+	t.AddSyscallExitHandler(h.HandleSyscallExit)
+	t.AddDetachHandler(func(int) {
+		h.Print()
+	})
+	return nil
+}
+
+// heatmapEnabled returns true if any of the flags which configure the heatmap were given
+func heatmapEnabled() bool {
+	return flagHeatmap || flagHeatmapBy != string(heatmap.GroupBySyscall) || flagHeatmapInterval != 0
+}
@@ -0,0 +1,70 @@
+package heatmap
+
+// classes groups syscalls by what they operate on, in a similar way to the syscall classes of strace
+var classes = map[string][]string{
+	"file": {
+		"open", "openat", "openat2", "creat", "stat", "lstat", "fstat", "newfstatat", "statx", "statfs", "fstatfs",
+		"access", "faccessat", "faccessat2", "readlink", "readlinkat", "getdents", "getdents64", "mkdir", "mkdirat",
+		"rmdir", "unlink", "unlinkat", "rename", "renameat", "renameat2", "link", "linkat", "symlink", "symlinkat",
+		"chmod", "fchmod", "fchmodat", "chown", "fchown", "lchown", "fchownat", "truncate", "ftruncate", "chdir",
+		"fchdir", "getcwd", "utime", "utimes", "utimensat", "futimesat", "mknod", "mknodat", "getxattr", "lgetxattr",
+		"fgetxattr", "setxattr", "lsetxattr", "fsetxattr", "listxattr", "removexattr", "fsync", "fdatasync", "sync",
+		"syncfs", "sync_file_range", "fallocate", "fadvise64", "flock", "inotify_add_watch", "inotify_rm_watch",
+		"name_to_handle_at", "open_by_handle_at", "chroot", "mount", "umount2",
+	},
+	"desc": {
+		"read", "write", "pread64", "pwrite64", "readv", "writev", "preadv", "pwritev", "preadv2", "pwritev2",
+		"close", "close_range", "dup", "dup2", "dup3", "fcntl", "ioctl", "lseek", "pipe", "pipe2", "sendfile",
+		"splice", "tee", "vmsplice", "copy_file_range", "poll", "ppoll", "select", "pselect6", "epoll_create",
+		"epoll_create1", "epoll_ctl", "epoll_wait", "epoll_pwait", "epoll_pwait2", "eventfd", "eventfd2",
+		"timerfd_create", "timerfd_settime", "timerfd_gettime", "signalfd", "signalfd4", "inotify_init",
+		"inotify_init1", "memfd_create", "io_uring_setup", "io_uring_enter", "io_uring_register", "io_setup",
+		"io_submit", "io_getevents", "io_destroy", "io_cancel",
+	},
+	"network": {
+		"socket", "socketpair", "bind", "listen", "accept", "accept4", "connect", "getsockname", "getpeername",
+		"sendto", "recvfrom", "sendmsg", "recvmsg", "sendmmsg", "recvmmsg", "shutdown", "setsockopt", "getsockopt",
+	},
+	"process": {
+		"clone", "clone3", "fork", "vfork", "execve", "execveat", "exit", "exit_group", "wait4", "waitid", "kill",
+		"tkill", "tgkill", "getpid", "getppid", "gettid", "getuid", "geteuid", "getgid", "getegid", "setuid",
+		"setgid", "setsid", "getpgid", "setpgid", "getpgrp", "prctl", "arch_prctl", "set_tid_address",
+		"set_robust_list", "get_robust_list", "sched_yield", "sched_getaffinity", "sched_setaffinity", "getrlimit",
+		"setrlimit", "prlimit64", "getrusage", "getpriority", "setpriority", "capget", "capset", "unshare", "setns",
+		"pidfd_open", "pidfd_send_signal", "ptrace", "seccomp", "rseq", "uname", "sysinfo", "getrandom",
+	},
+	"signal": {
+		"rt_sigaction", "rt_sigprocmask", "rt_sigreturn", "rt_sigsuspend", "rt_sigtimedwait", "rt_sigqueueinfo",
+		"rt_sigpending", "sigaltstack", "pause", "alarm",
+	},
+	"memory": {
+		"mmap", "munmap", "mprotect", "mremap", "brk", "madvise", "mlock", "munlock", "mlockall", "munlockall",
+		"mincore", "msync", "membarrier", "pkey_mprotect", "mbind", "set_mempolicy", "get_mempolicy",
+	},
+	"ipc": {
+		"futex", "futex_waitv", "semget", "semop", "semtimedop", "semctl", "msgget", "msgsnd", "msgrcv", "msgctl",
+		"shmget", "shmat", "shmdt", "shmctl", "mq_open", "mq_timedsend", "mq_timedreceive", "mq_unlink",
+	},
+	"time": {
+		"nanosleep", "clock_nanosleep", "clock_gettime", "clock_getres", "clock_settime", "gettimeofday", "time",
+		"times", "getitimer", "setitimer", "timer_create", "timer_settime", "timer_gettime", "timer_delete",
+	},
+}
+
+var classOfSyscall = make(map[string]string)
+
+func init() {
+	for class, names := range classes {
+		for _, name := range names {
+			classOfSyscall[name] = class
+		}
+	}
+}
+
+// classOf returns the class of a syscall, or "other" if it isn't in one
+func classOf(name string) string {
+	if class, ok := classOfSyscall[name]; ok {
+		return class
+	}
+	return "other"
+}
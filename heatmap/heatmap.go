@@ -0,0 +1,174 @@
+package heatmap
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/liamg/grace/tracer"
+)
+
+// Heatmap counts syscalls in slices of time, and prints them as a grid with a row for each syscall or class of
+// syscall and a column for each slice, shaded by the number of calls. This shows bursts and stalls which are hidden in
+// a summary of the whole trace.
+type Heatmap struct {
+	w          io.Writer
+	by         GroupBy
+	interval   time.Duration // width of each column, or zero to fit the columns to the width
+	width      int
+	maxRows    int
+	start      time.Time
+	end        time.Time
+	resolution time.Duration // width of each bucket the counts are kept in
+	rows       map[string]*row
+}
+
+type row struct {
+	name   string
+	total  int
+	counts []int // number of calls in each bucket
+}
+
+// GroupBy determines whether each row is a syscall or a class of syscalls
+type GroupBy string
+
+const (
+	GroupBySyscall GroupBy = "syscall"
+	GroupByClass   GroupBy = "class"
+)
+
+// maxBuckets is the number of buckets kept for each row before they are merged into wider buckets, to bound memory use
+// for long traces. If the interval is fixed, the oldest buckets are dropped instead.
+const maxBuckets = 10000
+
+// New creates a heatmap which will be printed to the given writer
+func New(w io.Writer) *Heatmap {
+	return &Heatmap{
+		w:          w,
+		by:         GroupBySyscall,
+		width:      80,
+		maxRows:    20,
+		resolution: time.Millisecond,
+		rows:       make(map[string]*row),
+	}
+}
+
+// SetGroupBy sets whether each row is a syscall or a class of syscalls
+func (h *Heatmap) SetGroupBy(by GroupBy) error {
+	switch by {
+	case GroupBySyscall, GroupByClass:
+	default:
+		return fmt.Errorf("invalid heatmap grouping '%s': must be one of %s, %s", by, GroupBySyscall, GroupByClass)
+	}
+	h.by = by
+	return nil
+}
+
+// SetStartTime sets the time the first column starts at, which should be when the trace started. By default, this is
+// the time of the first syscall.
+func (h *Heatmap) SetStartTime(start time.Time) {
+	h.start = start
+}
+
+// SetInterval sets the length of time each column covers. By default, this is chosen to fit the trace to the width.
+// With a fixed interval, only the most recent columns are kept for long traces.
+func (h *Heatmap) SetInterval(interval time.Duration) error {
+	if interval < 0 {
+		return fmt.Errorf("invalid heatmap interval '%s': must be positive", interval)
+	}
+	if interval > 0 {
+		h.resolution = interval
+	}
+	h.interval = interval
+	return nil
+}
+
+// SetWidth sets the number of characters the heatmap should fit into
+func (h *Heatmap) SetWidth(width int) {
+	h.width = width
+}
+
+// SetMaxRows limits the number of rows to the busiest ones, or shows all of them if max is 0
+func (h *Heatmap) SetMaxRows(max int) {
+	h.maxRows = max
+}
+
+// HandleSyscallExit counts a syscall in the slice of time it was made in
+func (h *Heatmap) HandleSyscallExit(call *tracer.Syscall) {
+	name := call.Name()
+	if h.by == GroupByClass {
+		name = classOf(name)
+	}
+	h.add(name, call.EnterTime())
+}
+
+func (h *Heatmap) add(name string, at time.Time) {
+	if h.start.IsZero() {
+		h.start = at
+	}
+	// syscalls from different threads can exit out of order
+	if at.Before(h.start) {
+		at = h.start
+	}
+	if at.After(h.end) {
+		h.end = at
+	}
+	bucket := int(at.Sub(h.start) / h.resolution)
+	// the width of the columns is chosen when the heatmap is printed, so buckets are only merged if it's automatic
+	for h.interval == 0 && bucket >= maxBuckets {
+		h.merge(10)
+		bucket = int(at.Sub(h.start) / h.resolution)
+	}
+	if bucket >= maxBuckets {
+		// drop a tenth more than needed so that this doesn't happen for every new bucket
+		dropped := bucket - maxBuckets + 1 + maxBuckets/10
+		h.drop(dropped)
+		bucket -= dropped
+	}
+	r, ok := h.rows[name]
+	if !ok {
+		r = &row{name: name}
+		h.rows[name] = r
+	}
+	for len(r.counts) <= bucket {
+		r.counts = append(r.counts, 0)
+	}
+	r.counts[bucket]++
+	r.total++
+}
+
+// merge combines every n buckets into one
+func (h *Heatmap) merge(n int) {
+	h.resolution *= time.Duration(n)
+	for _, r := range h.rows {
+		r.counts = mergeCounts(r.counts, n)
+	}
+}
+
+// drop discards the oldest n buckets, and starts the heatmap after them. The calls in them are no longer counted.
+func (h *Heatmap) drop(n int) {
+	h.start = h.start.Add(h.resolution * time.Duration(n))
+	for name, r := range h.rows {
+		dropped := r.counts
+		if n < len(r.counts) {
+			dropped = r.counts[:n]
+		}
+		for _, count := range dropped {
+			r.total -= count
+		}
+		if r.total == 0 {
+			// rows are ranked and labelled by the calls which are still shown
+			delete(h.rows, name)
+			continue
+		}
+		r.counts = append(r.counts[:0], r.counts[len(dropped):]...)
+	}
+}
+
+func mergeCounts(counts []int, n int) []int {
+	merged := make([]int, (len(counts)+n-1)/n)
+	for i, count := range counts {
+		merged[i/n] += count
+	}
+	return merged
+}
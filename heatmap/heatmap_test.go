@@ -0,0 +1,115 @@
+package heatmap
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_BucketsAreMergedForLongTraces(t *testing.T) {
+	h := New(&bytes.Buffer{})
+	start := time.Now()
+	h.add("read", start)
+	h.add("read", start.Add(5*time.Millisecond))
+	h.add("write", start.Add(15*time.Millisecond))
+	assert.Equal(t, time.Millisecond, h.resolution)
+	assert.Equal(t, []int{1, 0, 0, 0, 0, 1}, h.rows["read"].counts)
+
+	h.add("read", start.Add(maxBuckets*time.Millisecond))
+	assert.Equal(t, 10*time.Millisecond, h.resolution)
+	assert.Equal(t, 3, h.rows["read"].total)
+	assert.Equal(t, []int{2}, h.rows["read"].counts[:1])
+	assert.Equal(t, 1, h.rows["read"].counts[maxBuckets/10])
+	assert.Equal(t, []int{0, 1}, h.rows["write"].counts)
+}
+
+func Test_FixedInterval(t *testing.T) {
+	h := New(&bytes.Buffer{})
+	require.NoError(t, h.SetInterval(time.Second))
+	start := time.Now()
+	h.add("read", start)
+	h.add("read", start.Add(time.Hour))
+	assert.Equal(t, time.Second, h.resolution)
+	assert.Len(t, h.rows["read"].counts, 3600+1)
+
+	// the oldest columns are dropped rather than merged, as the interval was chosen, and their calls aren't counted
+	h.add("open", start.Add(time.Hour))
+	h.add("open", start.Add(time.Hour*4))
+	h.add("write", start.Add(time.Hour*5))
+	assert.Equal(t, time.Second, h.resolution)
+	dropped := 5*3600 - maxBuckets + 1 + maxBuckets/10
+	assert.Equal(t, start.Add(time.Duration(dropped)*time.Second), h.start)
+	assert.NotContains(t, h.rows, "read")
+	assert.Equal(t, 1, h.rows["open"].total)
+	assert.Equal(t, 1, h.rows["write"].total)
+	require.Len(t, h.rows["write"].counts, 5*3600-dropped+1)
+	assert.Equal(t, 1, h.rows["write"].counts[5*3600-dropped])
+
+	assert.Error(t, h.SetInterval(-time.Second))
+}
+
+func Test_ColumnsStartWhenTheTraceStarted(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	h := New(buffer)
+	require.NoError(t, h.SetInterval(time.Second))
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local)
+	h.SetStartTime(start)
+	h.add("read", start.Add(2*time.Second))
+	assert.Equal(t, []int{0, 0, 1}, h.rows["read"].counts)
+
+	h.Print()
+	assert.Contains(t, buffer.String(), "syscalls per 1s from 12:00:00.000\nread 1│  █│\n")
+}
+
+func Test_ColumnFactor(t *testing.T) {
+	assert.Equal(t, 1, columnFactor(50, 60))
+	assert.Equal(t, 2, columnFactor(100, 60))
+	assert.Equal(t, 30, columnFactor(1600, 60))
+	assert.Equal(t, 100, columnFactor(6000, 60))
+}
+
+func Test_Shading(t *testing.T) {
+	assert.Equal(t, ' ', shade(0, 100))
+	assert.Equal(t, '░', shade(1, 100))
+	assert.Equal(t, '█', shade(100, 100))
+	assert.Equal(t, '█', shade(1, 1))
+	assert.Equal(t, "░ 1-3  ▒ 4-15  ▓ 16-63  █ 64-256 calls", legend(256))
+	assert.Equal(t, "█ 1 calls", legend(1))
+}
+
+func Test_Print(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	h := New(buffer)
+	require.NoError(t, h.SetInterval(time.Second))
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local)
+	for i := 0; i < 16; i++ {
+		h.add("futex", start)
+	}
+	h.add("futex", start.Add(2*time.Second))
+	h.add("epoll_wait", start.Add(time.Second))
+	h.Print()
+
+	assert.Equal(t, strings.Join([]string{
+		"syscalls per 1s from 12:00:00.000",
+		"futex      17│█ ░│",
+		"epoll_wait  1│ ░ │",
+		"              ^0s",
+		"░ 1  ▒ 2-3  ▓ 4-7  █ 8-16 calls",
+		"",
+	}, "\n"), buffer.String())
+}
+
+func Test_Classes(t *testing.T) {
+	assert.Equal(t, "file", classOf("openat"))
+	assert.Equal(t, "network", classOf("connect"))
+	assert.Equal(t, "ipc", classOf("futex"))
+	assert.Equal(t, "other", classOf("bpf"))
+
+	h := New(&bytes.Buffer{})
+	assert.NoError(t, h.SetGroupBy(GroupByClass))
+	assert.Error(t, h.SetGroupBy("colour"))
+}
@@ -0,0 +1,156 @@
+package heatmap
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// shades are used to draw each cell, from no calls to the most calls made in a single slice of time
+var shades = []rune{' ', '░', '▒', '▓', '█'}
+
+// sorted returns the rows with the most calls first
+func (h *Heatmap) sorted() []*row {
+	var rows []*row
+	for _, r := range h.rows {
+		rows = append(rows, r)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].total != rows[j].total {
+			return rows[i].total > rows[j].total
+		}
+		return rows[i].name < rows[j].name
+	})
+	return rows
+}
+
+// columnFactor returns the number of buckets to draw in each column so that the heatmap fits into the available width.
+// Columns are a round multiple of the bucket width e.g. 20ms or 400ms.
+func columnFactor(buckets int, available int) int {
+	for factor := 1; ; factor *= 10 {
+		for _, m := range []int{1, 2, 3, 4, 5, 6, 8} {
+			if (buckets+factor*m-1)/(factor*m) <= available {
+				return factor * m
+			}
+		}
+	}
+}
+
+// Print draws the heatmap
+func (h *Heatmap) Print() {
+
+	if len(h.rows) == 0 {
+		_, _ = fmt.Fprintln(h.w, "no syscalls were made")
+		return
+	}
+
+	rows := h.sorted()
+	hidden := 0
+	if h.maxRows > 0 && len(rows) > h.maxRows {
+		hidden = len(rows) - h.maxRows
+		rows = rows[:h.maxRows]
+	}
+
+	var labelWidth int
+	for _, r := range rows {
+		if len(r.name) > labelWidth {
+			labelWidth = len(r.name)
+		}
+	}
+	countWidth := len(strconv.Itoa(rows[0].total))
+	indent := labelWidth + countWidth + 2
+
+	buckets := int(h.end.Sub(h.start)/h.resolution) + 1
+	factor := 1
+	if h.interval == 0 {
+		available := h.width - indent - 1
+		if available < 10 {
+			available = 10
+		}
+		factor = columnFactor(buckets, available)
+	}
+	column := h.resolution * time.Duration(factor)
+
+	var grid [][]int
+	var max int
+	for _, r := range rows {
+		counts := make([]int, buckets)
+		copy(counts, r.counts)
+		cells := mergeCounts(counts, factor)
+		for _, count := range cells {
+			if count > max {
+				max = count
+			}
+		}
+		grid = append(grid, cells)
+	}
+
+	_, _ = fmt.Fprintf(h.w, "syscalls per %s from %s\n", column, h.start.Format("15:04:05.000"))
+	for i, r := range rows {
+		var line strings.Builder
+		for _, count := range grid[i] {
+			line.WriteRune(shade(count, max))
+		}
+		_, _ = fmt.Fprintf(h.w, "%-*s %*d│%s│\n", labelWidth, r.name, countWidth, r.total, line.String())
+	}
+	_, _ = fmt.Fprintf(h.w, "%s%s\n", strings.Repeat(" ", indent), axis(len(grid[0]), column))
+	if hidden > 0 {
+		_, _ = fmt.Fprintf(h.w, "... and %d more\n", hidden)
+	}
+	_, _ = fmt.Fprintln(h.w, legend(max))
+}
+
+// shade returns the character to draw a cell with, on a logarithmic scale so that quiet rows are still visible
+func shade(count int, max int) rune {
+	return shades[level(count, max)]
+}
+
+func level(count int, max int) int {
+	switch {
+	case count <= 0:
+		return 0
+	case max <= 1:
+		return len(shades) - 1
+	}
+	l := 1 + int(math.Log(float64(count))/math.Log(float64(max))*float64(len(shades)-1))
+	if l > len(shades)-1 {
+		return len(shades) - 1
+	}
+	return l
+}
+
+// axis labels the start time of every 10th column, relative to the start of the heatmap
+func axis(columns int, column time.Duration) string {
+	line := []rune(strings.Repeat(" ", columns+1))
+	next := 0
+	for i := 0; i < columns; i += 10 {
+		label := "^" + (column * time.Duration(i)).String()
+		if i < next || i+len(label) > len(line) {
+			continue
+		}
+		copy(line[i:], []rune(label))
+		next = i + len(label) + 1
+	}
+	return strings.TrimRight(string(line), " ")
+}
+
+// legend describes the range of calls each shade represents
+func legend(max int) string {
+	var parts []string
+	for l := 1; l < len(shades); l++ {
+		lower := sort.Search(max, func(c int) bool { return level(c+1, max) >= l }) + 1
+		upper := sort.Search(max, func(c int) bool { return level(c+1, max) > l })
+		if lower > upper {
+			continue
+		}
+		if lower == upper {
+			parts = append(parts, fmt.Sprintf("%c %d", shades[l], lower))
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%c %d-%d", shades[l], lower, upper))
+	}
+	return strings.Join(parts, "  ") + " calls"
+}
@@ -0,0 +1,13 @@
+// Package testhooks lets the tracer hand constructors for its types to the test helpers in this module, without making
+// them part of the tracer's API. The values are set by the tracer package when it is initialised, and are typed by
+// the helpers which use them, as this package cannot import the tracer.
+package testhooks
+
+var (
+	// NewSyscall builds a *tracer.Syscall from its decoded parts
+	NewSyscall interface{}
+	// NewArg builds a tracer.Arg from its decoded parts
+	NewArg interface{}
+	// SyscallNumber looks up the number of a syscall by name
+	SyscallNumber interface{}
+)
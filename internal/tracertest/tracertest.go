@@ -0,0 +1,88 @@
+// Package tracertest builds syscalls without tracing a process, so that the packages which handle them can be tested
+package tracertest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/liamg/grace/internal/testhooks"
+	"github.com/liamg/grace/tracer"
+)
+
+// Syscall describes a syscall made by a traced thread
+type Syscall struct {
+	Name     string
+	Process  tracer.Process
+	Args     []Arg
+	Return   Arg
+	Paths    []string
+	Entered  time.Time
+	Duration time.Duration
+	Running  bool // the syscall has been entered but has not exited yet
+	// Incomplete means some arguments are not known yet, as they are written by the syscall
+	Incomplete bool
+}
+
+// Arg describes an argument or return value of a Syscall
+type Arg struct {
+	Name       string
+	Type       tracer.ArgType
+	Raw        uintptr
+	Data       []byte
+	Annotation string
+	Array      []Arg
+	Object     *Object
+}
+
+// Object describes a struct argument of a Syscall
+type Object struct {
+	Name       string
+	Properties []Arg
+}
+
+// Build creates the syscall, failing the test if the syscall name is unknown
+func (s Syscall) Build(t testing.TB) *tracer.Syscall {
+	t.Helper()
+	number, ok := testhooks.SyscallNumber.(func(string) (int, bool))(s.Name)
+	if !ok {
+		t.Fatalf("unknown syscall '%s'", s.Name)
+	}
+	proc := s.Process
+	if proc.Tid == 0 {
+		proc.Tid = proc.Pid
+	}
+	var args []tracer.Arg
+	for _, arg := range s.Args {
+		args = append(args, arg.build())
+	}
+	var ret *tracer.Arg
+	var exited time.Time
+	if !s.Running {
+		built := s.Return.build()
+		ret = &built
+		exited = s.Entered.Add(s.Duration)
+	}
+	newSyscall := testhooks.NewSyscall.(func(int, tracer.Process, []tracer.Arg, *tracer.Arg, []string, bool, time.Time, time.Time) *tracer.Syscall)
+	return newSyscall(number, proc, args, ret, s.Paths, !s.Incomplete, s.Entered, exited)
+}
+
+func (a Arg) build() tracer.Arg {
+	var array []tracer.Arg
+	for _, item := range a.Array {
+		array = append(array, item.build())
+	}
+	var obj *tracer.Object
+	if a.Object != nil {
+		obj = &tracer.Object{Name: a.Object.Name}
+		for _, property := range a.Object.Properties {
+			obj.Properties = append(obj.Properties, property.build())
+		}
+	}
+	newArg := testhooks.NewArg.(func(string, tracer.ArgType, uintptr, []byte, string, []tracer.Arg, *tracer.Object) tracer.Arg)
+	return newArg(a.Name, a.Type, a.Raw, a.Data, a.Annotation, array, obj)
+}
+
+// Errno returns the raw return value of a syscall which failed with the given error number
+func Errno(errno int) uintptr {
+	return uintptr(-errno)
+}
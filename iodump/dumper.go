@@ -0,0 +1,166 @@
+package iodump
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/liamg/grace/tracer"
+	"github.com/liamg/grace/tracer/netw"
+)
+
+// Direction is the direction in which data moved, from the point of view of the traced process
+type Direction string
+
+const (
+	DirectionRead  Direction = "read"
+	DirectionWrite Direction = "write"
+)
+
+// Dumper appends the data read from and written to matching file descriptors to a file per descriptor. A new file is
+// started whenever a descriptor is closed or reused for something else. Each chunk of data is preceded by a header
+// line of the form:
+//
+//	@ <timestamp> <direction> <syscall> <length>
+//
+// followed by exactly <length> bytes of raw data and a newline.
+type Dumper struct {
+	dir      string
+	rules    *rules
+	resolver *netw.Resolver
+	files    map[fdKey]*dumpFile
+	opened   map[fdKey]int // number of files started for each descriptor
+	err      error         // the first error which prevented data from being dumped
+}
+
+// dumpFile is an open dump file, along with the target of the descriptor it was started for
+type dumpFile struct {
+	*os.File
+	target string
+}
+
+type fdKey struct {
+	pid int
+	fd  int
+}
+
+// target is the file or socket which a file descriptor refers to
+type target struct {
+	path   string
+	socket *netw.Connection
+}
+
+// New creates a dumper which writes files to the given directory, for file descriptors matching the given spec
+func New(dir string, spec string) (*Dumper, error) {
+	r, err := parseRules(spec)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &Dumper{
+		dir:      dir,
+		rules:    r,
+		resolver: netw.NewResolver(),
+		files:    make(map[fdKey]*dumpFile),
+		opened:   make(map[fdKey]int),
+	}, nil
+}
+
+// HandleSyscallExit dumps any data transferred by the given syscall
+func (d *Dumper) HandleSyscallExit(call *tracer.Syscall) {
+	if call.Name() == "close" {
+		if args := call.Args(); len(args) > 0 {
+			d.closeFile(call.Pid(), args[0].Int())
+		}
+		return
+	}
+	transfers, err := Transfers(call)
+	if err != nil {
+		d.fail(err)
+	}
+	for _, transfer := range transfers {
+		d.dump(call, transfer.Fd, transfer.Direction, transfer.Data)
+	}
+}
+
+func (d *Dumper) fail(err error) {
+	if d.err == nil {
+		d.err = err
+	}
+}
+
+func (d *Dumper) dump(call *tracer.Syscall, fd int, direction Direction, data []byte) {
+	if len(data) == 0 {
+		return
+	}
+	t := d.resolve(call.Pid(), fd)
+	if !d.rules.match(fd, t) {
+		return
+	}
+	f, err := d.file(call.Pid(), fd, t)
+	if err != nil {
+		d.fail(err)
+		return
+	}
+	_, _ = fmt.Fprintf(f, "@ %s %s %s %d\n", call.ExitTime().Format(time.RFC3339Nano), direction, call.Name(), len(data))
+	_, _ = f.Write(data)
+	_, _ = f.Write([]byte{'\n'})
+}
+
+// file returns the dump file for the given descriptor, starting a new one if the descriptor now refers to something
+// else. The first file for a descriptor is named grace-<pid>-fd<fd>.io, and later ones grace-<pid>-fd<fd>.<n>.io.
+func (d *Dumper) file(pid int, fd int, t *target) (*dumpFile, error) {
+	key := fdKey{pid: pid, fd: fd}
+	var link string
+	if t != nil {
+		link = t.path
+	}
+	if f, ok := d.files[key]; ok {
+		if link == "" || link == f.target {
+			return f, nil
+		}
+		d.closeFile(pid, fd)
+	}
+	name := fmt.Sprintf("grace-%d-fd%d.io", pid, fd)
+	if count := d.opened[key]; count > 0 {
+		name = fmt.Sprintf("grace-%d-fd%d.%d.io", pid, fd, count)
+	}
+	f, err := os.OpenFile(filepath.Join(d.dir, name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	d.opened[key]++
+	d.files[key] = &dumpFile{File: f, target: link}
+	return d.files[key], nil
+}
+
+func (d *Dumper) closeFile(pid int, fd int) {
+	key := fdKey{pid: pid, fd: fd}
+	if f, ok := d.files[key]; ok {
+		_ = f.Close()
+		delete(d.files, key)
+	}
+}
+
+// Close closes all open dump files. It returns the first error which prevented data from being dumped, if any.
+func (d *Dumper) Close() error {
+	for key, f := range d.files {
+		if err := f.Close(); err != nil {
+			d.fail(err)
+		}
+		delete(d.files, key)
+	}
+	return d.err
+}
+
+// resolve returns the file or socket which the given descriptor refers to
+func (d *Dumper) resolve(pid int, fd int) *target {
+	link, conn, err := d.resolver.Resolve(pid, fd)
+	if err != nil {
+		return nil
+	}
+	return &target{path: link, socket: conn}
+}
@@ -0,0 +1,147 @@
+package iodump
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/liamg/grace/internal/tracertest"
+	"github.com/liamg/grace/tracer"
+	"github.com/liamg/grace/tracer/procfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProc serves descriptor links and file contents in place of /proc
+type fakeProc struct {
+	links map[string]string
+	files map[string][]byte
+}
+
+func (f *fakeProc) Readlink(path string) (string, error) {
+	link, ok := f.links[path]
+	if !ok {
+		return "", os.ErrNotExist
+	}
+	return link, nil
+}
+
+func (f *fakeProc) ReadFile(path string) ([]byte, error) {
+	data, ok := f.files[path]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return data, nil
+}
+
+func (f *fakeProc) ReadAt(path string, out []byte, offset int64) (int, error) {
+	data, ok := f.files[path]
+	if !ok {
+		return 0, os.ErrNotExist
+	}
+	return copy(out, data[offset:]), nil
+}
+
+func useFakeProc(t *testing.T) *fakeProc {
+	proc := &fakeProc{links: make(map[string]string), files: make(map[string][]byte)}
+	procfs.SetSource(proc)
+	t.Cleanup(func() { procfs.SetSource(procfs.OS) })
+	return proc
+}
+
+var exited = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func writeCall(t *testing.T, fd int, data string) *tracer.Syscall {
+	return tracertest.Syscall{
+		Name:    "write",
+		Process: tracer.Process{Pid: 100},
+		Args: []tracertest.Arg{
+			{Name: "fd", Type: tracer.ArgTypeInt, Raw: uintptr(fd)},
+			{Name: "buf", Type: tracer.ArgTypeData, Data: []byte(data)},
+			{Name: "count", Type: tracer.ArgTypeUnsignedLong, Raw: uintptr(len(data))},
+		},
+		Return:  tracertest.Arg{Type: tracer.ArgTypeInt, Raw: uintptr(len(data))},
+		Entered: exited,
+	}.Build(t)
+}
+
+func closeCall(t *testing.T, fd int) *tracer.Syscall {
+	return tracertest.Syscall{
+		Name:    "close",
+		Process: tracer.Process{Pid: 100},
+		Args:    []tracertest.Arg{{Name: "fd", Type: tracer.ArgTypeInt, Raw: uintptr(fd)}},
+		Return:  tracertest.Arg{Type: tracer.ArgTypeInt},
+	}.Build(t)
+}
+
+func sendfileCall(t *testing.T, outFd int, inFd int, offset int, count int) *tracer.Syscall {
+	return tracertest.Syscall{
+		Name:    "sendfile",
+		Process: tracer.Process{Pid: 100},
+		Args: []tracertest.Arg{
+			{Name: "out_fd", Type: tracer.ArgTypeInt, Raw: uintptr(outFd)},
+			{Name: "in_fd", Type: tracer.ArgTypeInt, Raw: uintptr(inFd)},
+			{Name: "offset", Type: tracer.ArgTypeUnsignedInt, Raw: uintptr(offset)},
+			{Name: "count", Type: tracer.ArgTypeUnsignedLong, Raw: uintptr(count)},
+		},
+		Return:  tracertest.Arg{Type: tracer.ArgTypeInt, Raw: uintptr(count)},
+		Entered: exited,
+	}.Build(t)
+}
+
+func readDump(t *testing.T, dir string, name string) string {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	require.NoError(t, err)
+	return string(data)
+}
+
+func Test_DumperStartsNewFileWhenDescriptorIsReused(t *testing.T) {
+	proc := useFakeProc(t)
+	dir := t.TempDir()
+	dumper, err := New(dir, "fd=3")
+	require.NoError(t, err)
+
+	proc.links["/proc/100/fd/3"] = "/tmp/first"
+	dumper.HandleSyscallExit(writeCall(t, 3, "one"))
+	dumper.HandleSyscallExit(writeCall(t, 3, "two"))
+	dumper.HandleSyscallExit(closeCall(t, 3))
+
+	proc.links["/proc/100/fd/3"] = "/tmp/second"
+	dumper.HandleSyscallExit(writeCall(t, 3, "three"))
+
+	// replaced with dup2, without being closed
+	proc.links["/proc/100/fd/3"] = "/tmp/third"
+	dumper.HandleSyscallExit(writeCall(t, 3, "four"))
+	require.NoError(t, dumper.Close())
+
+	assert.Equal(t, "@ 2024-01-02T03:04:05Z write write 3\none\n@ 2024-01-02T03:04:05Z write write 3\ntwo\n", readDump(t, dir, "grace-100-fd3.io"))
+	assert.Equal(t, "@ 2024-01-02T03:04:05Z write write 5\nthree\n", readDump(t, dir, "grace-100-fd3.1.io"))
+	assert.Equal(t, "@ 2024-01-02T03:04:05Z write write 4\nfour\n", readDump(t, dir, "grace-100-fd3.2.io"))
+}
+
+func Test_DumperReadsSendfileDataThroughProcfs(t *testing.T) {
+	proc := useFakeProc(t)
+	dir := t.TempDir()
+	dumper, err := New(dir, "fd=5")
+	require.NoError(t, err)
+
+	proc.links["/proc/100/fd/4"] = "/srv/index.html"
+	proc.links["/proc/100/fd/5"] = "socket:[1]"
+	proc.files["/proc/100/fd/4"] = []byte("<html></html>")
+	dumper.HandleSyscallExit(sendfileCall(t, 5, 4, 6, 7))
+	require.NoError(t, dumper.Close())
+
+	assert.Equal(t, "@ 2024-01-02T03:04:05Z write sendfile 7\n</html>\n", readDump(t, dir, "grace-100-fd5.io"))
+}
+
+func Test_DumperReportsUnreadableSendfileData(t *testing.T) {
+	useFakeProc(t)
+	dumper, err := New(t.TempDir(), "fd=5")
+	require.NoError(t, err)
+
+	dumper.HandleSyscallExit(sendfileCall(t, 5, 4, 0, 7))
+	err = dumper.Close()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "fd 4 of pid 100")
+}
@@ -0,0 +1,79 @@
+package iodump
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/liamg/grace/filter"
+)
+
+// rules decide which file descriptors have their I/O dumped. A descriptor is dumped if it matches any rule.
+type rules struct {
+	fds     []int
+	paths   []*filter.PathPattern
+	sockets []*filter.PathPattern
+}
+
+// parseRules parses a comma separated list of conditions such as "fd=3,path=/var/log/*,socket=*:5432"
+func parseRules(input string) (*rules, error) {
+	r := &rules{}
+	for _, part := range strings.Split(input, ",") {
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid condition '%s': expected key=value", part)
+		}
+		switch key {
+		case "fd":
+			fd, err := strconv.Atoi(value)
+			if err != nil || fd < 0 {
+				return nil, fmt.Errorf("invalid fd '%s'", value)
+			}
+			r.fds = append(r.fds, fd)
+		case "path":
+			r.paths = append(r.paths, filter.NewPathPattern(value))
+		case "socket":
+			r.sockets = append(r.sockets, filter.NewPathPattern(value))
+		default:
+			return nil, fmt.Errorf("invalid key '%s': expected fd, path or socket", key)
+		}
+	}
+	if len(r.fds)+len(r.paths)+len(r.sockets) == 0 {
+		return nil, fmt.Errorf("no conditions specified")
+	}
+	return r, nil
+}
+
+func (r *rules) match(fd int, t *target) bool {
+	for _, want := range r.fds {
+		if want == fd {
+			return true
+		}
+	}
+	if t == nil {
+		return false
+	}
+	for _, pattern := range r.paths {
+		if t.path != "" && pattern.Match(t.path) {
+			return true
+		}
+	}
+	if t.socket == nil {
+		return false
+	}
+	for _, pattern := range r.sockets {
+		if pattern.Match(endpoint(t.socket.LocalAddress, t.socket.LocalPort)) ||
+			pattern.Match(endpoint(t.socket.RemoteAddress, t.socket.RemotePort)) {
+			return true
+		}
+	}
+	return false
+}
+
+func endpoint(ip net.IP, port int) string {
+	return fmt.Sprintf("%s:%d", ip, port)
+}
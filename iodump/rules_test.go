@@ -0,0 +1,63 @@
+package iodump
+
+import (
+	"net"
+	"testing"
+
+	"github.com/liamg/grace/filter"
+	"github.com/liamg/grace/tracer/netw"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRules(t *testing.T) {
+	r, err := parseRules("fd=3,path=/var/log/*,socket=*:5432,fd=4")
+	require.NoError(t, err)
+	assert.Equal(t, []int{3, 4}, r.fds)
+	assert.Equal(t, []*filter.PathPattern{filter.NewPathPattern("/var/log/*")}, r.paths)
+	assert.Equal(t, []*filter.PathPattern{filter.NewPathPattern("*:5432")}, r.sockets)
+
+	for _, input := range []string{"", "fd", "fd=x", "fd=-1", "inode=3"} {
+		_, err := parseRules(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestRulesMatch(t *testing.T) {
+	r, err := parseRules("fd=3,path=/var/log/*,socket=*:5432")
+	require.NoError(t, err)
+
+	postgres := &netw.Connection{
+		Protocol:      "tcp",
+		LocalAddress:  net.ParseIP("127.0.0.1"),
+		LocalPort:     41234,
+		RemoteAddress: net.ParseIP("10.0.0.1"),
+		RemotePort:    5432,
+	}
+	other := &netw.Connection{
+		Protocol:      "tcp",
+		LocalAddress:  net.ParseIP("127.0.0.1"),
+		LocalPort:     41235,
+		RemoteAddress: net.ParseIP("10.0.0.1"),
+		RemotePort:    443,
+	}
+
+	tests := []struct {
+		name   string
+		fd     int
+		target *target
+		want   bool
+	}{
+		{name: "fd", fd: 3, want: true},
+		{name: "other fd", fd: 5},
+		{name: "path", fd: 5, target: &target{path: "/var/log/app/out.log"}, want: true},
+		{name: "other path", fd: 5, target: &target{path: "/etc/passwd"}},
+		{name: "remote endpoint", fd: 5, target: &target{path: "socket:[1]", socket: postgres}, want: true},
+		{name: "other endpoint", fd: 5, target: &target{path: "socket:[2]", socket: other}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, r.match(test.fd, test.target))
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package iodump
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/liamg/grace/tracer"
+	"github.com/liamg/grace/tracer/procfs"
+)
+
+// Transfer is a chunk of data which was read from or written to a file descriptor by a syscall
+type Transfer struct {
+	Fd        int
+	Direction Direction
+	Data      []byte
+	Peer      *net.UDPAddr // the address passed to or returned by sendto/recvfrom/sendmsg/recvmsg, if any
+}
+
+// transferCall describes where the data moved by a syscall can be found
+type transferCall struct {
+	direction Direction
+	extract   func(arg tracer.Arg) []byte
+	peer      func(args []tracer.Arg) *net.UDPAddr
+}
+
+// the syscalls whose data can be extracted - in each case the file descriptor is the first argument, and the data
+// is described by the second
+var transferCalls = map[string]transferCall{
+	"read":     {DirectionRead, bufferData, nil},
+	"pread64":  {DirectionRead, bufferData, nil},
+	"recvfrom": {DirectionRead, bufferData, argPeer(4)},
+	"readv":    {DirectionRead, iovecData, nil},
+	"preadv":   {DirectionRead, iovecData, nil},
+	"preadv2":  {DirectionRead, iovecData, nil},
+	"recvmsg":  {DirectionRead, msghdrData, msghdrPeer},
+	"write":    {DirectionWrite, bufferData, nil},
+	"pwrite64": {DirectionWrite, bufferData, nil},
+	"sendto":   {DirectionWrite, bufferData, argPeer(4)},
+	"writev":   {DirectionWrite, iovecData, nil},
+	"pwritev":  {DirectionWrite, iovecData, nil},
+	"pwritev2": {DirectionWrite, iovecData, nil},
+	"sendmsg":  {DirectionWrite, msghdrData, msghdrPeer},
+}
+
+// Transfers returns the data moved by the given syscall, which must have exited. sendfile moves data between two
+// descriptors without it passing through the memory of the traced process, so it is read back from the input file,
+// and an error is returned if that isn't possible.
+func Transfers(call *tracer.Syscall) ([]Transfer, error) {
+	args := call.Args()
+	n := call.Return().Int()
+	if len(args) < 2 || n <= 0 {
+		return nil, nil
+	}
+
+	if call.Name() == "sendfile" {
+		return sendfileTransfers(call)
+	}
+
+	c, ok := transferCalls[call.Name()]
+	if !ok {
+		return nil, nil
+	}
+	data := c.extract(args[1])
+	if len(data) > n {
+		data = data[:n]
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	transfer := Transfer{
+		Fd:        args[0].Int(),
+		Direction: c.direction,
+		Data:      data,
+	}
+	if c.peer != nil {
+		transfer.Peer = c.peer(args)
+	}
+	return []Transfer{transfer}, nil
+}
+
+// Peer returns the address passed to or returned by sendto/recvfrom/sendmsg/recvmsg, if any. This identifies the
+// remote end of an unconnected socket.
+func Peer(call *tracer.Syscall) *net.UDPAddr {
+	c, ok := transferCalls[call.Name()]
+	if !ok || c.peer == nil || len(call.Args()) < 2 {
+		return nil
+	}
+	return c.peer(call.Args())
+}
+
+func sendfileTransfers(call *tracer.Syscall) ([]Transfer, error) {
+	args := call.Args()
+	n := call.Return().Int()
+	if len(args) < 3 {
+		return nil, nil
+	}
+	outFd, inFd := args[0].Int(), args[1].Int()
+
+	var offset int64
+	if args[2].Type() == tracer.ArgTypeUnsignedInt {
+		offset = int64(args[2].Raw())
+	} else if pos, err := readPosition(call.Pid(), inFd); err == nil {
+		// the file position has already been advanced past the data
+		offset = pos - int64(n)
+	} else {
+		return nil, fmt.Errorf("failed to find the data sent by sendfile from fd %d of pid %d: %w", inFd, call.Pid(), err)
+	}
+
+	data := make([]byte, n)
+	count, err := procfs.ReadAt(fmt.Sprintf("/proc/%d/fd/%d", call.Pid(), inFd), data, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the data sent by sendfile from fd %d of pid %d: %w", inFd, call.Pid(), err)
+	}
+	if count == 0 {
+		return nil, nil
+	}
+	data = data[:count]
+
+	return []Transfer{
+		{Fd: inFd, Direction: DirectionRead, Data: data},
+		{Fd: outFd, Direction: DirectionWrite, Data: data},
+	}, nil
+}
+
+// readPosition reads the current file position of a file descriptor
+func readPosition(pid int, fd int) (int64, error) {
+	info, err := procfs.ReadFile(fmt.Sprintf("/proc/%d/fdinfo/%d", pid, fd))
+	if err != nil {
+		return 0, err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(info))
+	for scanner.Scan() {
+		if line := scanner.Text(); strings.HasPrefix(line, "pos:") {
+			return strconv.ParseInt(strings.TrimSpace(strings.TrimPrefix(line, "pos:")), 10, 64)
+		}
+	}
+	return 0, fmt.Errorf("no position found for fd %d", fd)
+}
+
+func bufferData(arg tracer.Arg) []byte {
+	return arg.Data()
+}
+
+// iovecData concatenates the buffers of an array of iovec structs
+func iovecData(arg tracer.Arg) []byte {
+	var data []byte
+	for _, element := range arg.Array() {
+		obj := element.Object()
+		if obj == nil {
+			continue
+		}
+		for _, prop := range obj.Properties {
+			if prop.Name() == "base" {
+				data = append(data, prop.Data()...)
+			}
+		}
+	}
+	return data
+}
+
+// msghdrData concatenates the buffers referenced by a msghdr struct
+func msghdrData(arg tracer.Arg) []byte {
+	if prop := property(arg, "iovec"); prop != nil {
+		return iovecData(*prop)
+	}
+	return nil
+}
+
+func msghdrPeer(args []tracer.Arg) *net.UDPAddr {
+	if prop := property(args[1], "name"); prop != nil {
+		return sockaddrPeer(*prop)
+	}
+	return nil
+}
+
+func argPeer(index int) func(args []tracer.Arg) *net.UDPAddr {
+	return func(args []tracer.Arg) *net.UDPAddr {
+		if index >= len(args) {
+			return nil
+		}
+		return sockaddrPeer(args[index])
+	}
+}
+
+// sockaddrPeer converts a decoded AF_INET/AF_INET6 sockaddr struct into an address
+func sockaddrPeer(arg tracer.Arg) *net.UDPAddr {
+	addr, port := property(arg, "addr"), property(arg, "port")
+	if addr == nil || port == nil {
+		return nil
+	}
+	ip := net.ParseIP(string(addr.Data()))
+	if ip == nil {
+		return nil
+	}
+	return &net.UDPAddr{IP: ip, Port: port.Int()}
+}
+
+func property(arg tracer.Arg, name string) *tracer.Arg {
+	obj := arg.Object()
+	if obj == nil {
+		return nil
+	}
+	for _, prop := range obj.Properties {
+		if prop.Name() == name {
+			prop := prop
+			return &prop
+		}
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"io"
+
+	"github.com/liamg/grace/iostat"
+	"github.com/liamg/grace/tracer"
+)
+
+// configureIOStat sets up a table of the data moved through each file and socket, which is printed when the tracer
+// detaches
+func configureIOStat(t *tracer.Tracer, w io.Writer) error {
+	a := iostat.New(w)
+	if err := a.SetSortKey(flagIOSortKey); err != nil {
+		return err
+	}
+	t.AddSyscallExitHandler(a.HandleSyscallExit)
+	t.AddDetachHandler(func(int) {
+		a.Print()
+	})
+	return nil
+}
+
+func ioStatEnabled() bool {
+	return flagIOSummary || flagIOSortKey != ""
+}
@@ -0,0 +1,147 @@
+package iostat
+
+import (
+	"io"
+	"net"
+	"time"
+
+	"github.com/liamg/grace/iodump"
+	"github.com/liamg/grace/tracer"
+)
+
+// Accounting tracks how much data was read from and written to each file and socket endpoint, and prints a table of
+// them when the trace ends
+type Accounting struct {
+	w         io.Writer
+	resources map[string]*Resource
+	resolver  *Resolver
+	sortKey   string
+}
+
+// Resource is a file, socket endpoint or other file descriptor target which data was moved through
+type Resource struct {
+	Name       string
+	Kind       Kind
+	ReadBytes  int64
+	WriteBytes int64
+	Reads      int // number of calls which read from the resource, including those which returned no data
+	Writes     int
+	Errors     int
+	Time       time.Duration // total time spent in calls which moved data through the resource
+}
+
+// Kind is the type of resource a file descriptor refers to
+type Kind string
+
+const (
+	KindFile   Kind = "file"
+	KindSocket Kind = "socket"
+	KindPipe   Kind = "pipe"
+	KindOther  Kind = "other"
+)
+
+// ioCall describes which arguments of a syscall are the file descriptors data is read from and written to
+type ioCall struct {
+	in  int // index of the fd data is read from, or -1
+	out int // index of the fd data is written to, or -1
+}
+
+var ioCalls = map[string]ioCall{
+	"read":            {in: 0, out: -1},
+	"pread64":         {in: 0, out: -1},
+	"readv":           {in: 0, out: -1},
+	"preadv":          {in: 0, out: -1},
+	"preadv2":         {in: 0, out: -1},
+	"recvfrom":        {in: 0, out: -1},
+	"recvmsg":         {in: 0, out: -1},
+	"write":           {in: -1, out: 0},
+	"pwrite64":        {in: -1, out: 0},
+	"writev":          {in: -1, out: 0},
+	"pwritev":         {in: -1, out: 0},
+	"pwritev2":        {in: -1, out: 0},
+	"sendto":          {in: -1, out: 0},
+	"sendmsg":         {in: -1, out: 0},
+	"sendfile":        {in: 1, out: 0},
+	"splice":          {in: 0, out: 2},
+	"copy_file_range": {in: 0, out: 2},
+}
+
+// New creates an I/O accounting table which will be printed to the given writer
+func New(w io.Writer) *Accounting {
+	return &Accounting{
+		w:         w,
+		resources: make(map[string]*Resource),
+		resolver:  NewResolver(),
+	}
+}
+
+// HandleSyscallExit records the data moved by a syscall
+func (a *Accounting) HandleSyscallExit(call *tracer.Syscall) {
+	c, ok := ioCalls[call.Name()]
+	if !ok {
+		return
+	}
+	args := call.Args()
+	n := int64(call.Return().Int())
+	failed := n < 0
+	if failed {
+		n = 0
+	}
+	var peer *net.UDPAddr
+	if !failed {
+		peer = iodump.Peer(call)
+	}
+	if c.in >= 0 && c.in < len(args) {
+		name, kind := a.resolver.Resolve(call.Pid(), args[c.in].Int(), peer)
+		a.record(name, kind, n, false, failed, call.Duration())
+	}
+	if c.out >= 0 && c.out < len(args) {
+		name, kind := a.resolver.Resolve(call.Pid(), args[c.out].Int(), peer)
+		a.record(name, kind, n, true, failed, call.Duration())
+	}
+}
+
+func (a *Accounting) record(name string, kind Kind, n int64, write bool, failed bool, duration time.Duration) {
+	resource, ok := a.resources[name]
+	if !ok {
+		resource = &Resource{Name: name, Kind: kind}
+		a.resources[name] = resource
+	}
+	if write {
+		resource.Writes++
+		resource.WriteBytes += n
+	} else {
+		resource.Reads++
+		resource.ReadBytes += n
+	}
+	if failed {
+		resource.Errors++
+	}
+	resource.Time += duration
+}
+
+// Calls returns the number of calls which moved data through the resource
+func (r *Resource) Calls() int {
+	return r.Reads + r.Writes
+}
+
+// Bytes returns the total number of bytes read from and written to the resource
+func (r *Resource) Bytes() int64 {
+	return r.ReadBytes + r.WriteBytes
+}
+
+// AvgRead returns the average number of bytes returned by each read
+func (r *Resource) AvgRead() int64 {
+	if r.Reads == 0 {
+		return 0
+	}
+	return r.ReadBytes / int64(r.Reads)
+}
+
+// AvgWrite returns the average number of bytes written by each write
+func (r *Resource) AvgWrite() int64 {
+	if r.Writes == 0 {
+		return 0
+	}
+	return r.WriteBytes / int64(r.Writes)
+}
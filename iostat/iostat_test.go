@@ -0,0 +1,91 @@
+package iostat
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Record(t *testing.T) {
+	a := New(&bytes.Buffer{})
+	a.record("/etc/passwd", KindFile, 4096, false, false, time.Millisecond)
+	a.record("/etc/passwd", KindFile, 100, false, false, time.Millisecond)
+	a.record("/etc/passwd", KindFile, 0, false, true, time.Millisecond)
+	a.record("/etc/passwd", KindFile, 10, true, false, time.Millisecond)
+
+	resource := a.resources["/etc/passwd"]
+	require.NotNil(t, resource)
+	assert.Equal(t, KindFile, resource.Kind)
+	assert.Equal(t, int64(4196), resource.ReadBytes)
+	assert.Equal(t, int64(10), resource.WriteBytes)
+	assert.Equal(t, 3, resource.Reads)
+	assert.Equal(t, 1, resource.Writes)
+	assert.Equal(t, 1, resource.Errors)
+	assert.Equal(t, 4, resource.Calls())
+	assert.Equal(t, int64(4206), resource.Bytes())
+	assert.Equal(t, int64(1398), resource.AvgRead())
+	assert.Equal(t, int64(10), resource.AvgWrite())
+	assert.Equal(t, 4*time.Millisecond, resource.Time)
+}
+
+func Test_SortKeys(t *testing.T) {
+	a := New(&bytes.Buffer{})
+	// lots of tiny reads
+	for i := 0; i < 100; i++ {
+		a.record("pipe:[1]", KindPipe, 1, false, false, time.Microsecond)
+	}
+	a.record("/var/log/big", KindFile, 65536, true, false, time.Millisecond)
+	a.record("tcp 127.0.0.1:1234 -> 127.0.0.1:80", KindSocket, 1000, false, false, 2*time.Millisecond)
+	a.record("tcp 127.0.0.1:1234 -> 127.0.0.1:80", KindSocket, 0, false, true, 0)
+
+	tests := []struct {
+		key      string
+		expected []string
+	}{
+		{key: "", expected: []string{"/var/log/big", "tcp 127.0.0.1:1234 -> 127.0.0.1:80", "pipe:[1]"}},
+		{key: "name", expected: []string{"/var/log/big", "pipe:[1]", "tcp 127.0.0.1:1234 -> 127.0.0.1:80"}},
+		{key: "calls", expected: []string{"pipe:[1]", "tcp 127.0.0.1:1234 -> 127.0.0.1:80", "/var/log/big"}},
+		{key: "avg-read", expected: []string{"tcp 127.0.0.1:1234 -> 127.0.0.1:80", "pipe:[1]", "/var/log/big"}},
+		{key: "written", expected: []string{"/var/log/big", "pipe:[1]", "tcp 127.0.0.1:1234 -> 127.0.0.1:80"}},
+		{key: "errors", expected: []string{"tcp 127.0.0.1:1234 -> 127.0.0.1:80", "/var/log/big", "pipe:[1]"}},
+	}
+	for _, test := range tests {
+		t.Run(test.key, func(t *testing.T) {
+			require.NoError(t, a.SetSortKey(test.key))
+			var names []string
+			for _, resource := range a.Resources() {
+				names = append(names, resource.Name)
+			}
+			assert.Equal(t, test.expected, names)
+		})
+	}
+
+	assert.Error(t, a.SetSortKey("colour"))
+}
+
+func Test_KindOf(t *testing.T) {
+	assert.Equal(t, KindFile, kindOf("/etc/passwd"))
+	assert.Equal(t, KindPipe, kindOf("pipe:[1234]"))
+	assert.Equal(t, KindOther, kindOf("anon_inode:[eventfd]"))
+}
+
+func Test_FormatBytes(t *testing.T) {
+	tests := []struct {
+		n        int64
+		expected string
+	}{
+		{n: 0, expected: "0 B"},
+		{n: 1023, expected: "1023 B"},
+		{n: 1024, expected: "1.00 KiB"},
+		{n: 1536, expected: "1.50 KiB"},
+		{n: 20 * 1024, expected: "20.0 KiB"},
+		{n: 300 * 1024 * 1024, expected: "300 MiB"},
+		{n: 5 * 1024 * 1024 * 1024, expected: "5.00 GiB"},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expected, formatBytes(test.n))
+	}
+}
@@ -0,0 +1,59 @@
+package iostat
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/liamg/grace/tracer/netw"
+)
+
+// Resolver names the file or socket endpoint a file descriptor refers to
+type Resolver struct {
+	resolver *netw.Resolver
+}
+
+// NewResolver creates a resolver with an empty socket cache
+func NewResolver() *Resolver {
+	return &Resolver{
+		resolver: netw.NewResolver(),
+	}
+}
+
+// Resolve returns a name for the file or socket endpoint a file descriptor refers to. Sockets are named after their
+// protocol, local and remote addresses, and the remote address of an unconnected socket is taken from the peer
+// address passed to the syscall, if any.
+func (r *Resolver) Resolve(pid int, fd int, peer *net.UDPAddr) (string, Kind) {
+	link, conn, err := r.resolver.Resolve(pid, fd)
+	if err != nil {
+		return fmt.Sprintf("fd %d of pid %d", fd, pid), KindOther
+	}
+	if _, ok := netw.ParseSocketLink(link); !ok {
+		return link, kindOf(link)
+	}
+	if conn == nil {
+		// unix sockets and other families don't have a connection table entry with addresses
+		return link, KindSocket
+	}
+	remote, remotePort := conn.RemoteAddress, conn.RemotePort
+	if remotePort == 0 && peer != nil {
+		remote, remotePort = peer.IP, peer.Port
+	}
+	return fmt.Sprintf("%s %s -> %s", conn.Protocol, endpoint(conn.LocalAddress, conn.LocalPort), endpoint(remote, remotePort)), KindSocket
+}
+
+func kindOf(link string) Kind {
+	switch {
+	case strings.HasPrefix(link, "/"):
+		return KindFile
+	case strings.HasPrefix(link, "pipe:"):
+		return KindPipe
+	default:
+		return KindOther
+	}
+}
+
+func endpoint(ip net.IP, port int) string {
+	return net.JoinHostPort(ip.String(), strconv.Itoa(port))
+}
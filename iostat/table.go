@@ -0,0 +1,111 @@
+package iostat
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aquasecurity/table"
+)
+
+// sortKeys maps each sort key to a function which returns the value to sort by (largest first)
+var sortKeys = map[string]func(*Resource) int64{
+	"bytes":     func(r *Resource) int64 { return r.Bytes() },
+	"read":      func(r *Resource) int64 { return r.ReadBytes },
+	"written":   func(r *Resource) int64 { return r.WriteBytes },
+	"calls":     func(r *Resource) int64 { return int64(r.Calls()) },
+	"reads":     func(r *Resource) int64 { return int64(r.Reads) },
+	"writes":    func(r *Resource) int64 { return int64(r.Writes) },
+	"avg-read":  func(r *Resource) int64 { return r.AvgRead() },
+	"avg-write": func(r *Resource) int64 { return r.AvgWrite() },
+	"errors":    func(r *Resource) int64 { return int64(r.Errors) },
+	"time":      func(r *Resource) int64 { return int64(r.Time) },
+}
+
+// SetSortKey sets the column used to order the rows of the table. Rows are ordered by total bytes moved by default,
+// or by name if the key is "name".
+func (a *Accounting) SetSortKey(key string) error {
+	if _, ok := sortKeys[key]; !ok && key != "" && key != "name" {
+		var names []string
+		for name := range sortKeys {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("invalid I/O sort key '%s': must be one of %s, name", key, strings.Join(names, ", "))
+	}
+	a.sortKey = key
+	return nil
+}
+
+// Resources returns each resource which data was moved through, ordered by the sort key
+func (a *Accounting) Resources() []*Resource {
+	var all []*Resource
+	for _, resource := range a.resources {
+		all = append(all, resource)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Name < all[j].Name
+	})
+	if a.sortKey == "name" {
+		return all
+	}
+	key, ok := sortKeys[a.sortKey]
+	if !ok {
+		key = sortKeys["bytes"]
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		return key(all[i]) > key(all[j])
+	})
+	return all
+}
+
+// Print writes a table of the data read from and written to each resource
+func (a *Accounting) Print() {
+
+	tab := table.New(a.w)
+	tab.SetRowLines(false)
+	tab.AddHeaders("read", "written", "reads", "writes", "avg read", "avg write", "errors", "seconds", "kind", "file/endpoint")
+	tab.SetAlignment(
+		table.AlignRight, table.AlignRight, table.AlignRight, table.AlignRight, table.AlignRight,
+		table.AlignRight, table.AlignRight, table.AlignRight, table.AlignLeft, table.AlignLeft,
+	)
+	tab.SetLineStyle(table.StyleBlue)
+
+	for _, resource := range a.Resources() {
+		tab.AddRow(
+			formatBytes(resource.ReadBytes),
+			formatBytes(resource.WriteBytes),
+			fmt.Sprintf("%d", resource.Reads),
+			fmt.Sprintf("%d", resource.Writes),
+			formatBytes(resource.AvgRead()),
+			formatBytes(resource.AvgWrite()),
+			fmt.Sprintf("%d", resource.Errors),
+			fmt.Sprintf("%.6f", resource.Time.Seconds()),
+			string(resource.Kind),
+			resource.Name,
+		)
+	}
+
+	tab.Render()
+}
+
+// formatBytes formats a number of bytes with a binary unit e.g. 1.5 KiB
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	value := float64(n)
+	var prefix int
+	for value >= unit && prefix < 4 {
+		value /= unit
+		prefix++
+	}
+	format := "%.2f %ciB"
+	if value >= 100 {
+		format = "%.0f %ciB"
+	} else if value >= 10 {
+		format = "%.1f %ciB"
+	}
+	return fmt.Sprintf(format, value, "KMGT"[prefix-1])
+}
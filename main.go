@@ -2,11 +2,18 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"os/exec"
+	"time"
 
 	"github.com/liamg/grace/filter"
+	"github.com/liamg/grace/heatmap"
+	"github.com/liamg/grace/iodump"
+	"github.com/liamg/grace/pcap"
 
 	"github.com/liamg/grace/printer"
+	"github.com/liamg/grace/summary"
 
 	"github.com/liamg/grace/tracer"
 	"github.com/spf13/cobra"
@@ -24,15 +31,41 @@ var (
 	flagExtraNewLine        = false
 	flagMultiline           = false
 	flagFilter              = ""
+	flagExclude             = ""
 	flagAbsoluteTimestamps  = false
 	flagRelativeTimestamps  = false
 	flagSummarise           = false
 	flagSortKey             = ""
+	flagSummaryHistogram    = false
+	flagSummaryErrors       = false
+	flagSummaryErrorsBy     = ""
+	flagSummaryFormat       = string(summary.FormatTable)
+	flagSummaryBy           = ""
+	flagSummaryLive         = 0
+	flagWithTrace           = false
+	flagIOSummary           = false
+	flagIOSortKey           = ""
+	flagBlocked             = false
+	flagBlockedRows         = 10
+	flagFutex               = false
+	flagFutexRows           = 10
+	flagHeatmap             = false
+	flagHeatmapBy           = string(heatmap.GroupBySyscall)
+	flagHeatmapInterval     = time.Duration(0)
+	flagHeatmapRows         = 20
 	flagShowSyscallNumber   = false
 	flagFilterPassing       = false
 	flagFilterFailing       = false
 	flagOutputFile          = ""
 	flagRawOutput           = false
+	flagContextBefore       = 0
+	flagContextAfter        = 0
+	flagFormat              = string(printer.FormatText)
+	flagDumpIO              = ""
+	flagDumpIODir           = "."
+	flagPcap                = ""
+	flagTree                = false
+	flagFollowForks         = false
 )
 
 var rootCmd = &cobra.Command{
@@ -41,6 +74,7 @@ var rootCmd = &cobra.Command{
 	Short: `grace is a CLI tool for monitoring and modifying syscalls for a given process.
 
 It's essentially strace, in Go, with colours and pretty output.`,
+	Args: cobra.ArbitraryArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cmd.SilenceErrors = true
 		cmd.SilenceUsage = true
@@ -49,93 +83,209 @@ It's essentially strace, in Go, with colours and pretty output.`,
 			return cmd.Help()
 		}
 
-		var t *tracer.Tracer
+		t, err := createTracer(args, os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		return run(cmd, t, time.Now())
+	},
+}
+
+func createTracer(args []string, stdin io.Reader) (*tracer.Tracer, error) {
+	var t *tracer.Tracer
+	if flagPID > 0 {
+		t = tracer.New(flagPID)
+	} else {
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdin = stdin
+		if flagForwardIO {
+			cmd.Stdout = os.Stdout
+			cmd.Stderr = os.Stderr
+		}
 		var err error
-		if flagPID > 0 {
-			t = tracer.New(flagPID)
-		} else {
-			t, err = tracer.FromCommand(!flagForwardIO, args[0], args[1:]...)
-			if err != nil {
-				return err
-			}
+		if t, err = tracer.FromCmd(cmd); err != nil {
+			return nil, err
 		}
+	}
+	t.SetFollowForks(flagFollowForks || flagTree || flagSummaryBy != "" || flagBlocked || flagFutex)
+	return t, nil
+}
 
-		output := cmd.OutOrStdout()
-		if flagOutputFile != "" {
-			output, err = os.Create(flagOutputFile)
-			if err != nil {
-				return err
-			}
-			defer func() {
-				_ = output.(*os.File).Close()
-			}()
+// run configures output for the given tracer according to the flags, and starts it
+func run(cmd *cobra.Command, t *tracer.Tracer, started time.Time) error {
+
+	var err error
+	output := cmd.OutOrStdout()
+	if flagOutputFile != "" {
+		output, err = os.Create(flagOutputFile)
+		if err != nil {
+			return err
 		}
+		defer func() {
+			_ = output.(*os.File).Close()
+		}()
+	}
 
-		p := printer.New(output)
+	p := printer.New(output)
+	p.SetStartTime(started)
+	defer func() { _ = p.Close() }()
+	if err := p.SetFormat(printer.Format(flagFormat)); err != nil {
+		return err
+	}
 
-		p.SetUseColours(!flagDisableColours && flagOutputFile == "")
-		p.SetMaxStringLen(flagMaxStringLen)
-		p.SetMaxHexDumpLen(flagMaxHexDumpLen)
-		p.SetExtraNewLine(flagExtraNewLine)
-		p.SetMultiLine(flagMultiline)
-		p.SetHexDumpLongStrings(flagHexDumpLongStrings)
-		p.SetShowAbsoluteTimestamps(flagAbsoluteTimestamps)
-		p.SetShowRelativeTimestamps(flagRelativeTimestamps)
-		p.SetShowSyscallNumber(flagShowSyscallNumber)
-		p.SetRawOutput(flagRawOutput)
+	p.SetUseColours(!flagDisableColours && flagOutputFile == "")
+	p.SetMaxStringLen(flagMaxStringLen)
+	p.SetMaxHexDumpLen(flagMaxHexDumpLen)
+	p.SetExtraNewLine(flagExtraNewLine)
+	p.SetMultiLine(flagMultiline)
+	p.SetHexDumpLongStrings(flagHexDumpLongStrings)
+	p.SetShowAbsoluteTimestamps(flagAbsoluteTimestamps)
+	p.SetShowRelativeTimestamps(flagRelativeTimestamps)
+	p.SetShowSyscallNumber(flagShowSyscallNumber)
+	p.SetRawOutput(flagRawOutput)
+	p.SetContextBefore(flagContextBefore)
+	p.SetContextAfter(flagContextAfter)
 
-		if flagVerbose {
-			p.SetMaxObjectProperties(0)
-		} else {
-			p.SetMaxObjectProperties(flagMaxObjectProperties)
+	if flagVerbose {
+		p.SetMaxObjectProperties(0)
+	} else {
+		p.SetMaxObjectProperties(flagMaxObjectProperties)
+	}
+
+	fltr, err := filter.Parse(flagFilter)
+	if err != nil {
+		return fmt.Errorf("failed to parse filter: %s", err)
+	}
+	if err := fltr.Exclude(flagExclude); err != nil {
+		return fmt.Errorf("failed to parse exclusions: %s", err)
+	}
+	fltr.SetFailingOnly(flagFilterFailing)
+	fltr.SetPassingOnly(flagFilterPassing)
+	p.SetFilter(fltr)
+
+	// the trace is printed unless it's replaced by a report, and the summary handlers are chained after the printer
+	// so that the summary can also be shown alongside the trace
+	if flagTree {
+		// the tree is printed as processes start and exit, so it can't share the output with the trace or other reports
+		if reportEnabled() || flagWithTrace {
+			return fmt.Errorf("--tree cannot be used with --with-trace or other reports")
+		}
+		configureTree(t, output, started, !flagDisableColours && flagOutputFile == "")
+	} else {
+		if !reportEnabled() || flagWithTrace {
+			t.AddSyscallEnterHandler(p.PrintSyscallEnter)
+			t.AddSyscallExitHandler(p.PrintSyscallExit)
+			t.AddSignalHandler(p.PrintSignal)
+			t.AddExitHandler(p.PrintExit)
+			t.AddAttachHandler(p.PrintAttach)
+			t.AddDetachHandler(p.PrintDetach)
 		}
+		if summaryEnabled() {
+			if err := configureSummary(t, output); err != nil {
+				return err
+			}
+		}
+		if ioStatEnabled() {
+			if err := configureIOStat(t, output); err != nil {
+				return err
+			}
+		}
+		if flagBlocked {
+			configureBlocked(t, output)
+		}
+		if flagFutex {
+			configureFutex(t, output)
+		}
+		if heatmapEnabled() {
+			if err := configureHeatmap(t, output, started); err != nil {
+				return err
+			}
+		}
+	}
 
-		fltr, err := filter.Parse(flagFilter)
+	if flagDumpIO != "" {
+		dumper, err := iodump.New(flagDumpIODir, flagDumpIO)
 		if err != nil {
-			return fmt.Errorf("failed to parse filter: %s", err)
+			return fmt.Errorf("failed to parse --dump-io: %w", err)
 		}
-		fltr.SetFailingOnly(flagFilterFailing)
-		fltr.SetPassingOnly(flagFilterPassing)
-		p.SetFilter(fltr)
-
-		if flagSummarise {
-			configureSummary(t, output, flagSortKey)
-		} else {
-			t.SetSyscallEnterHandler(p.PrintSyscallEnter)
-			t.SetSyscallExitHandler(p.PrintSyscallExit)
-			t.SetSignalHandler(p.PrintSignal)
-			t.SetProcessExitHandler(p.PrintProcessExit)
-			t.SetAttachHandler(p.PrintAttach)
-			t.SetDetachHandler(p.PrintDetach)
+		defer func() {
+			if err := dumper.Close(); err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Some data could not be dumped: %s\n", err)
+			}
+		}()
+		t.AddSyscallExitHandler(dumper.HandleSyscallExit)
+	}
+	if flagPcap != "" {
+		f, err := os.Create(flagPcap)
+		if err != nil {
+			return err
 		}
+		defer func() { _ = f.Close() }()
+		capture, err := pcap.NewCapture(f)
+		if err != nil {
+			return fmt.Errorf("failed to write pcap file: %w", err)
+		}
+		t.AddSyscallExitHandler(capture.HandleSyscallExit)
+	}
 
-		defer func() { _, _ = fmt.Fprintln(cmd.ErrOrStderr(), "") }()
+	defer func() { _, _ = fmt.Fprintln(cmd.ErrOrStderr(), "") }()
 
-		return t.Start()
-	},
+	return t.Start()
+}
+
+// reportEnabled returns true if the trace is replaced by one or more reports which are printed when tracing finishes
+func reportEnabled() bool {
+	return summaryEnabled() || ioStatEnabled() || flagBlocked || flagFutex || heatmapEnabled()
 }
 
 func init() {
-	rootCmd.Flags().BoolVarP(&flagDisableColours, "no-colours", "C", flagDisableColours, "disable colours in output")
-	rootCmd.Flags().IntVarP(&flagMaxStringLen, "max-string-len", "s", flagMaxStringLen, "maximum length of strings to print")
-	rootCmd.Flags().BoolVarP(&flagHexDumpLongStrings, "hex-dump-long-strings", "x", flagHexDumpLongStrings, "hex dump strings longer than --max-string-len")
-	rootCmd.Flags().IntVarP(&flagMaxHexDumpLen, "max-hex-dump-len", "l", flagMaxHexDumpLen, "maximum length of hex dumps")
-	rootCmd.Flags().IntVarP(&flagPID, "pid", "p", flagPID, "trace an existing process by PID")
-	rootCmd.Flags().BoolVarP(&flagForwardIO, "forward-io", "F", flagForwardIO, "forward stdin/stdout/stderr for the given command")
-	rootCmd.Flags().IntVarP(&flagMaxObjectProperties, "max-object-properties", "O", flagMaxObjectProperties, "maximum number of properties to print for objects (recursive) - this also applies to array elements")
-	rootCmd.Flags().BoolVarP(&flagVerbose, "verbose", "v", flagVerbose, "enable verbose output (overrides other verbosity settings)")
-	rootCmd.Flags().BoolVarP(&flagExtraNewLine, "extra-newline", "n", flagExtraNewLine, "print an extra newline after each syscall to aid readability")
-	rootCmd.Flags().BoolVarP(&flagMultiline, "multiline", "m", flagMultiline, "print each syscall argument on a separate line to aid readability")
-	rootCmd.Flags().StringVarP(&flagFilter, "filter", "f", flagFilter, "Filter string to apply to output. The string should be formatted as a query string e.g. 'syscall=write&arg0=stdout'. The syscall parameter filters syscalls by name. The path parameter filters syscalls that reference a particular path. The ret parameter filters by return value (values for ret are assumed to be decimal unless prefixed with 0x). Each parameter can be specified multiple times with an OR match being appied to parameters of that type, and an AND match applied to parameters of a different type.")
-	rootCmd.Flags().BoolVarP(&flagAbsoluteTimestamps, "absolute-timestamps", "a", flagAbsoluteTimestamps, "print absolute timestamps for each event")
-	rootCmd.Flags().BoolVarP(&flagRelativeTimestamps, "relative-timestamps", "r", flagRelativeTimestamps, "print relative timestamps for each event")
-	rootCmd.Flags().BoolVarP(&flagSummarise, "summary", "S", flagSummarise, "summarise counts of all syscalls")
-	rootCmd.Flags().StringVarP(&flagSortKey, "sort-column", "c", flagSortKey, "sort key for summary output (time, seconds, count, errors) (default is sort by syscall name)")
-	rootCmd.Flags().BoolVarP(&flagShowSyscallNumber, "number", "N", flagShowSyscallNumber, "show syscall numbers in output")
-	rootCmd.Flags().BoolVarP(&flagFilterFailing, "only-failing", "Z", flagFilterFailing, "show only failing syscalls")
-	rootCmd.Flags().BoolVarP(&flagFilterPassing, "only-passing", "z", flagFilterPassing, "show only passing syscalls")
-	rootCmd.Flags().StringVarP(&flagOutputFile, "output-file", "o", flagOutputFile, "output file (default is stdout)")
-	rootCmd.Flags().BoolVarP(&flagRawOutput, "raw", "R", flagRawOutput, "Raw output format for arguments and return values (format everything as raw hex values)")
+	rootCmd.PersistentFlags().BoolVarP(&flagDisableColours, "no-colours", "C", flagDisableColours, "disable colours in output")
+	rootCmd.PersistentFlags().IntVarP(&flagMaxStringLen, "max-string-len", "s", flagMaxStringLen, "maximum length of strings to print")
+	rootCmd.PersistentFlags().BoolVarP(&flagHexDumpLongStrings, "hex-dump-long-strings", "x", flagHexDumpLongStrings, "hex dump strings longer than --max-string-len")
+	rootCmd.PersistentFlags().IntVarP(&flagMaxHexDumpLen, "max-hex-dump-len", "l", flagMaxHexDumpLen, "maximum length of hex dumps")
+	rootCmd.PersistentFlags().IntVarP(&flagPID, "pid", "p", flagPID, "trace an existing process by PID")
+	rootCmd.PersistentFlags().BoolVarP(&flagForwardIO, "forward-io", "F", flagForwardIO, "forward stdin/stdout/stderr for the given command")
+	rootCmd.PersistentFlags().IntVarP(&flagMaxObjectProperties, "max-object-properties", "O", flagMaxObjectProperties, "maximum number of properties to print for objects (recursive) - this also applies to array elements")
+	rootCmd.PersistentFlags().BoolVarP(&flagVerbose, "verbose", "v", flagVerbose, "enable verbose output (overrides other verbosity settings)")
+	rootCmd.PersistentFlags().BoolVarP(&flagExtraNewLine, "extra-newline", "n", flagExtraNewLine, "print an extra newline after each syscall to aid readability")
+	rootCmd.PersistentFlags().BoolVarP(&flagMultiline, "multiline", "m", flagMultiline, "print each syscall argument on a separate line to aid readability")
+	rootCmd.PersistentFlags().StringVarP(&flagFilter, "filter", "f", flagFilter, "Filter string to apply to output. The string should be formatted as a query string e.g. 'syscall=write&arg0=stdout'. The syscall parameter filters syscalls by name. The path parameter filters syscalls that reference a particular path, and can contain * and ? wildcards, where * also matches '/' so that e.g. 'path=/proc/*' matches everything under /proc. The ret parameter filters by return value (values for ret are assumed to be decimal unless prefixed with 0x). The pid, tid, comm and exe parameters filter by the process or thread which made the syscall (exe matches either the full path or the file name of the executable). The duration parameter filters by time spent in the syscall and supports comparisons such as 'duration>10ms' (every duration condition must match, so a range such as 'duration>1ms&duration<1s' can be used either to filter or to exclude). The slowest parameter only shows a call if it is one of the N slowest calls to that syscall seen so far. The content, content-regex and content-hex parameters filter by the contents of buffers such as those passed to read/write/send/recv (including iovec and msghdr payloads), and highlight the matching bytes in the output. Any parameter can be negated to exclude matching syscalls, either with a '!' prefix e.g. '!syscall=futex' or with '!=' e.g. 'path!=/proc/*'. A syscall matching any exclusion is never shown. Each parameter can be specified multiple times with an OR match being appied to parameters of that type, and an AND match applied to parameters of a different type.")
+	rootCmd.PersistentFlags().StringVarP(&flagExclude, "exclude", "E", flagExclude, "Exclusion string to apply to output, using the same format as --filter. Any syscall matching any of the given parameters is hidden e.g. 'syscall=mmap,mprotect,futex&path=/proc/*'.")
+	rootCmd.PersistentFlags().BoolVarP(&flagAbsoluteTimestamps, "absolute-timestamps", "a", flagAbsoluteTimestamps, "print absolute timestamps for each event")
+	rootCmd.PersistentFlags().BoolVarP(&flagRelativeTimestamps, "relative-timestamps", "r", flagRelativeTimestamps, "print relative timestamps for each event")
+	rootCmd.PersistentFlags().BoolVarP(&flagSummarise, "summary", "S", flagSummarise, "summarise counts of all syscalls")
+	rootCmd.PersistentFlags().StringVarP(&flagSortKey, "sort-column", "c", flagSortKey, "sort key for summary output (time, seconds, count, errors, min, avg, p50, p95, p99, max) (default is sort by syscall name)")
+	rootCmd.PersistentFlags().BoolVarP(&flagSummaryHistogram, "summary-histogram", "", flagSummaryHistogram, "print a latency histogram for each syscall beneath the summary table (implies --summary)")
+	rootCmd.PersistentFlags().BoolVarP(&flagSummaryErrors, "summary-errors", "", flagSummaryErrors, "print a breakdown of the errors returned by each syscall beneath the summary table, ordered by --sort-column and then by count (implies --summary)")
+	rootCmd.PersistentFlags().StringVarP(&flagSummaryErrorsBy, "summary-errors-by", "", flagSummaryErrorsBy, "also group the error breakdown by the path or file descriptor each syscall was made with (path, fd) (implies --summary-errors)")
+	rootCmd.PersistentFlags().StringVarP(&flagSummaryBy, "summary-by", "", flagSummaryBy, "follow child processes and threads, and split the summary by process or thread (process, thread) - each is listed with its time in syscalls, followed by a table for each and the combined total, and '-c time' orders them by time (implies --summary)")
+	rootCmd.PersistentFlags().IntVarP(&flagSummaryLive, "summary-live", "", flagSummaryLive, "redraw the summary every N seconds, showing the rate of each syscall since the last update alongside the totals so far - the full summary is still printed on exit, and can be printed at any time by sending SIGUSR1 to grace (implies --summary, and cannot be used with --with-trace)")
+	rootCmd.PersistentFlags().BoolVarP(&flagWithTrace, "with-trace", "", flagWithTrace, "print the trace as normal as well as any reports (--summary, --io-summary, --blocked, --futex, --heatmap), which otherwise replace it - with --summary this is like strace -C")
+	rootCmd.PersistentFlags().StringVarP(&flagSummaryFormat, "summary-format", "", flagSummaryFormat, "summary output format (table, json, csv) - durations in json and csv output are in nanoseconds, and csv output has a row for each syscall (implies --summary)")
+	rootCmd.PersistentFlags().BoolVarP(&flagIOSummary, "io-summary", "", flagIOSummary, "print a table of the bytes and calls read from and written to each file and socket endpoint (protocol, local and remote address), including the average size of each read and write to spot lots of tiny reads")
+	rootCmd.PersistentFlags().StringVarP(&flagIOSortKey, "io-sort-column", "", flagIOSortKey, "sort key for the I/O summary (bytes, read, written, calls, reads, writes, avg-read, avg-write, errors, time, name) (default is bytes) (implies --io-summary)")
+	rootCmd.PersistentFlags().BoolVarP(&flagBlocked, "blocked", "", flagBlocked, "follow child processes and threads, and print a report for each thread of where it spent its time blocked - time in blocking syscalls is attributed to the file, socket endpoint, epoll/poll set, futex address, child process or timer waited for, and ranked by total time")
+	rootCmd.PersistentFlags().IntVarP(&flagBlockedRows, "blocked-rows", "", flagBlockedRows, "maximum number of resources to list for each thread in the --blocked report (0 lists all of them)")
+	rootCmd.PersistentFlags().BoolVarP(&flagFutex, "futex", "", flagFutex, "follow child processes and threads, and print a report of the most contended futex addresses with their wait counts, total and max wait times, the memory region or symbol they are in, and which threads woke which")
+	rootCmd.PersistentFlags().IntVarP(&flagFutexRows, "futex-rows", "", flagFutexRows, "maximum number of addresses to list in the --futex report (0 lists all of them)")
+	rootCmd.PersistentFlags().BoolVarP(&flagHeatmap, "heatmap", "", flagHeatmap, "print a heatmap of syscalls over time, with a row for each syscall and a column for each slice of time shaded by the number of calls, to spot bursts, periodic activity and stalls")
+	rootCmd.PersistentFlags().StringVarP(&flagHeatmapBy, "heatmap-by", "", flagHeatmapBy, "show a row in the heatmap for each syscall, or for each class of syscall (syscall, class) - the classes are file, desc, network, process, signal, memory, ipc, time and other (implies --heatmap)")
+	rootCmd.PersistentFlags().DurationVarP(&flagHeatmapInterval, "heatmap-interval", "", flagHeatmapInterval, "length of time each column of the heatmap covers e.g. 100ms (default is to fit the trace to the terminal width) (implies --heatmap)")
+	rootCmd.PersistentFlags().IntVarP(&flagHeatmapRows, "heatmap-rows", "", flagHeatmapRows, "maximum number of rows in the heatmap, showing the busiest (0 shows all of them)")
+	rootCmd.PersistentFlags().BoolVarP(&flagShowSyscallNumber, "number", "N", flagShowSyscallNumber, "show syscall numbers in output")
+	rootCmd.PersistentFlags().BoolVarP(&flagFilterFailing, "only-failing", "Z", flagFilterFailing, "show only failing syscalls")
+	rootCmd.PersistentFlags().BoolVarP(&flagFilterPassing, "only-passing", "z", flagFilterPassing, "show only passing syscalls")
+	rootCmd.PersistentFlags().StringVarP(&flagOutputFile, "output-file", "o", flagOutputFile, "output file (default is stdout)")
+	rootCmd.PersistentFlags().IntVarP(&flagContextBefore, "before", "B", flagContextBefore, "print N unmatched syscalls before each filter match (dimmed)")
+	rootCmd.PersistentFlags().IntVarP(&flagContextAfter, "after", "A", flagContextAfter, "print N unmatched syscalls after each filter match (dimmed)")
+	rootCmd.PersistentFlags().StringVarP(&flagFormat, "format", "", flagFormat, "output format (text, json, strace, chrome) - chrome writes Chrome Trace Event JSON for ui.perfetto.dev or chrome://tracing")
+	rootCmd.PersistentFlags().StringVarP(&flagDumpIO, "dump-io", "", flagDumpIO, "append all data read from or written to matching file descriptors to a file per descriptor, e.g. 'fd=3,path=/var/log/*,socket=*:5432' (conditions are OR'd, and paths/sockets can contain * and ? wildcards)")
+	rootCmd.PersistentFlags().StringVarP(&flagDumpIODir, "dump-io-dir", "", flagDumpIODir, "directory to write --dump-io files to")
+	rootCmd.PersistentFlags().StringVarP(&flagPcap, "pcap", "", flagPcap, "write data sent and received over TCP/UDP sockets to a pcap file as synthetic packets, for viewing in Wireshark")
+	rootCmd.PersistentFlags().BoolVarP(&flagFollowForks, "follow-forks", "", flagFollowForks, "trace child processes and threads as they are created - each line is prefixed with the pid (and tid for threads) once there is more than one")
+	rootCmd.PersistentFlags().BoolVarP(&flagTree, "tree", "", flagTree, "follow child processes, and show the process tree with the command line, working directory, duration, exit status and syscall counts of each process - processes are shown as they start, exec and exit, and the full tree is printed at the end (cannot be used with --with-trace or other reports)")
+	rootCmd.PersistentFlags().BoolVarP(&flagRawOutput, "raw", "R", flagRawOutput, "Raw output format for arguments and return values (format everything as raw hex values)")
 }
 
 func main() {
@@ -0,0 +1,115 @@
+package pcap
+
+import (
+	"io"
+	"strings"
+
+	"github.com/liamg/grace/iodump"
+	"github.com/liamg/grace/tracer"
+	"github.com/liamg/grace/tracer/netw"
+)
+
+// Capture converts data sent and received on TCP and UDP sockets by traced processes into synthetic packets
+type Capture struct {
+	writer *Writer
+
+	resolver *netw.Resolver
+
+	// when both ends of a connection are traced, the same data is seen once when it is sent and again when it is
+	// received. These track how much of each flow has been seen from each end, and how much has been written to the
+	// capture, so that it is only written once. TCP flows are counted in bytes, and UDP flows in datagrams.
+	observed map[observation]uint64
+	emitted  map[flowKey]uint64
+}
+
+type observation struct {
+	flow      flowKey
+	direction iodump.Direction
+}
+
+// NewCapture creates a capture which writes a pcap file to w
+func NewCapture(w io.Writer) (*Capture, error) {
+	writer, err := NewWriter(w)
+	if err != nil {
+		return nil, err
+	}
+	return &Capture{
+		writer:   writer,
+		resolver: netw.NewResolver(),
+		observed: make(map[observation]uint64),
+		emitted:  make(map[flowKey]uint64),
+	}, nil
+}
+
+// HandleSyscallExit writes a packet for any data which the given syscall moved over a TCP or UDP socket
+func (c *Capture) HandleSyscallExit(call *tracer.Syscall) {
+	// data sent by sendfile which can't be read back is left out of the capture
+	transfers, _ := iodump.Transfers(call)
+	for _, transfer := range transfers {
+		conn := c.connection(call.Pid(), transfer.Fd)
+		if conn == nil {
+			continue
+		}
+
+		var protocol Protocol
+		switch strings.TrimSuffix(conn.Protocol, "6") {
+		case "tcp":
+			protocol = ProtocolTCP
+		case "udp":
+			protocol = ProtocolUDP
+		default:
+			continue
+		}
+
+		local := Endpoint{IP: conn.LocalAddress, Port: conn.LocalPort}
+		remote := Endpoint{IP: conn.RemoteAddress, Port: conn.RemotePort}
+		// unconnected UDP sockets have no remote address, but one is passed to each sendto/recvfrom call
+		if transfer.Peer != nil && (remote.Port == 0 || remote.IP.IsUnspecified()) {
+			remote = Endpoint{IP: transfer.Peer.IP, Port: transfer.Peer.Port}
+		}
+
+		// sockets bound to a wildcard address send loopback traffic from the loopback address
+		if local.IP.IsUnspecified() && remote.IP.IsLoopback() {
+			local.IP = remote.IP
+		}
+
+		src, dst := local, remote
+		if transfer.Direction == iodump.DirectionRead {
+			src, dst = remote, local
+		}
+		if data := c.unseen(protocol, src, dst, transfer); len(data) > 0 {
+			_ = c.writer.WritePacket(call.ExitTime(), protocol, src, dst, data)
+		}
+	}
+}
+
+// unseen returns the part of the transferred data which has not already been written to the capture
+func (c *Capture) unseen(protocol Protocol, src, dst Endpoint, transfer iodump.Transfer) []byte {
+	flow := flowKey{protocol: protocol, src: endpointKey(src), dst: endpointKey(dst)}
+	key := observation{flow: flow, direction: transfer.Direction}
+
+	size := uint64(1)
+	if protocol == ProtocolTCP {
+		size = uint64(len(transfer.Data))
+	}
+	c.observed[key] += size
+	if c.observed[key] <= c.emitted[flow] {
+		return nil
+	}
+	unseen := c.observed[key] - c.emitted[flow]
+	c.emitted[flow] = c.observed[key]
+
+	if protocol == ProtocolTCP && unseen < uint64(len(transfer.Data)) {
+		return transfer.Data[uint64(len(transfer.Data))-unseen:]
+	}
+	return transfer.Data
+}
+
+// connection returns the TCP/UDP connection which the given descriptor refers to, if any
+func (c *Capture) connection(pid int, fd int) *netw.Connection {
+	_, conn, err := c.resolver.Resolve(pid, fd)
+	if err != nil {
+		return nil
+	}
+	return conn
+}
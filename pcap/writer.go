@@ -0,0 +1,224 @@
+package pcap
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"time"
+)
+
+const (
+	// magic number for pcap files with nanosecond resolution timestamps
+	magicNanoseconds = 0xa1b23c4d
+	versionMajor     = 2
+	versionMinor     = 4
+	snapLen          = 262144
+	linkTypeEthernet = 1
+
+	etherTypeIPv4 = 0x0800
+	etherTypeIPv6 = 0x86dd
+
+	protocolTCP = 6
+	protocolUDP = 17
+
+	tcpFlagPSH = 0x08
+	tcpFlagACK = 0x10
+
+	// payloads larger than this are split across several segments, to stay within the IPv4 total length
+	maxSegmentSize = 65000
+)
+
+// Protocol is the transport protocol of a synthesised packet
+type Protocol int
+
+const (
+	ProtocolTCP Protocol = protocolTCP
+	ProtocolUDP Protocol = protocolUDP
+)
+
+// Endpoint is one end of a connection
+type Endpoint struct {
+	IP   net.IP
+	Port int
+}
+
+type flowKey struct {
+	protocol Protocol
+	src      string
+	dst      string
+}
+
+// Writer writes synthetic Ethernet frames to a pcap file
+type Writer struct {
+	w io.Writer
+
+	// the number of bytes sent in each direction of each TCP flow, used for sequence numbers
+	sent map[flowKey]uint32
+}
+
+// NewWriter writes the pcap file header to w, and returns a writer for packets
+func NewWriter(w io.Writer) (*Writer, error) {
+	header := make([]byte, 24)
+	binary.LittleEndian.PutUint32(header[0:], magicNanoseconds)
+	binary.LittleEndian.PutUint16(header[4:], versionMajor)
+	binary.LittleEndian.PutUint16(header[6:], versionMinor)
+	binary.LittleEndian.PutUint32(header[16:], snapLen)
+	binary.LittleEndian.PutUint32(header[20:], linkTypeEthernet)
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+	return &Writer{
+		w:    w,
+		sent: make(map[flowKey]uint32),
+	}, nil
+}
+
+// WritePacket writes the given payload as one or more packets from src to dst
+func (w *Writer) WritePacket(at time.Time, protocol Protocol, src, dst Endpoint, payload []byte) error {
+	if protocol == ProtocolUDP {
+		return w.writeFrame(at, protocol, src, dst, w.udp(src, dst, payload))
+	}
+	for len(payload) > 0 {
+		segment := payload
+		if len(segment) > maxSegmentSize {
+			segment = segment[:maxSegmentSize]
+		}
+		payload = payload[len(segment):]
+		if err := w.writeFrame(at, protocol, src, dst, w.tcp(src, dst, segment)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *Writer) writeFrame(at time.Time, protocol Protocol, src, dst Endpoint, transport []byte) error {
+	var packet []byte
+	var etherType uint16
+	if src4, dst4 := src.IP.To4(), dst.IP.To4(); src4 != nil && dst4 != nil {
+		etherType = etherTypeIPv4
+		packet = ipv4(protocol, src4, dst4, transport)
+	} else {
+		etherType = etherTypeIPv6
+		packet = ipv6(protocol, src.IP.To16(), dst.IP.To16(), transport)
+	}
+
+	frame := make([]byte, 14, 14+len(packet))
+	copy(frame[0:6], macAddress(dst.IP))
+	copy(frame[6:12], macAddress(src.IP))
+	binary.BigEndian.PutUint16(frame[12:], etherType)
+	frame = append(frame, packet...)
+
+	record := make([]byte, 16)
+	binary.LittleEndian.PutUint32(record[0:], uint32(at.Unix()))
+	binary.LittleEndian.PutUint32(record[4:], uint32(at.Nanosecond()))
+	binary.LittleEndian.PutUint32(record[8:], uint32(len(frame)))
+	binary.LittleEndian.PutUint32(record[12:], uint32(len(frame)))
+	if _, err := w.w.Write(record); err != nil {
+		return err
+	}
+	_, err := w.w.Write(frame)
+	return err
+}
+
+// macAddress returns a locally administered MAC address derived from an IP, so each host is distinguishable
+func macAddress(ip net.IP) []byte {
+	ip = ip.To16()
+	return []byte{0x02, 0x00, ip[12], ip[13], ip[14], ip[15]}
+}
+
+func (w *Writer) tcp(src, dst Endpoint, payload []byte) []byte {
+	out := flowKey{protocol: ProtocolTCP, src: endpointKey(src), dst: endpointKey(dst)}
+	in := flowKey{protocol: ProtocolTCP, src: out.dst, dst: out.src}
+
+	segment := make([]byte, 20, 20+len(payload))
+	binary.BigEndian.PutUint16(segment[0:], uint16(src.Port))
+	binary.BigEndian.PutUint16(segment[2:], uint16(dst.Port))
+	binary.BigEndian.PutUint32(segment[4:], w.sent[out])
+	binary.BigEndian.PutUint32(segment[8:], w.sent[in])
+	segment[12] = 5 << 4 // data offset, in 32-bit words
+	segment[13] = tcpFlagPSH | tcpFlagACK
+	binary.BigEndian.PutUint16(segment[14:], 65535)
+	segment = append(segment, payload...)
+
+	w.sent[out] += uint32(len(payload))
+	return segment
+}
+
+func (w *Writer) udp(src, dst Endpoint, payload []byte) []byte {
+	datagram := make([]byte, 8, 8+len(payload))
+	binary.BigEndian.PutUint16(datagram[0:], uint16(src.Port))
+	binary.BigEndian.PutUint16(datagram[2:], uint16(dst.Port))
+	binary.BigEndian.PutUint16(datagram[4:], uint16(8+len(payload)))
+	return append(datagram, payload...)
+}
+
+func endpointKey(e Endpoint) string {
+	return (&net.UDPAddr{IP: e.IP, Port: e.Port}).String()
+}
+
+func ipv4(protocol Protocol, src, dst net.IP, transport []byte) []byte {
+	header := make([]byte, 20)
+	header[0] = 0x45 // version 4, header length 5 words
+	binary.BigEndian.PutUint16(header[2:], uint16(20+len(transport)))
+	header[6] = 0x40 // don't fragment
+	header[8] = 64   // ttl
+	header[9] = byte(protocol)
+	copy(header[12:16], src)
+	copy(header[16:20], dst)
+	binary.BigEndian.PutUint16(header[10:], checksum(header, 0))
+
+	setTransportChecksum(protocol, transport, pseudoHeaderSum(src, dst, protocol, len(transport)))
+	return append(header, transport...)
+}
+
+func ipv6(protocol Protocol, src, dst net.IP, transport []byte) []byte {
+	header := make([]byte, 40)
+	header[0] = 0x60 // version 6
+	binary.BigEndian.PutUint16(header[4:], uint16(len(transport)))
+	header[6] = byte(protocol)
+	header[7] = 64 // hop limit
+	copy(header[8:24], src)
+	copy(header[24:40], dst)
+
+	setTransportChecksum(protocol, transport, pseudoHeaderSum(src, dst, protocol, len(transport)))
+	return append(header, transport...)
+}
+
+func setTransportChecksum(protocol Protocol, transport []byte, initial uint32) {
+	offset := 16
+	if protocol == ProtocolUDP {
+		offset = 6
+	}
+	sum := checksum(transport, initial)
+	if sum == 0 && protocol == ProtocolUDP {
+		sum = 0xffff
+	}
+	binary.BigEndian.PutUint16(transport[offset:], sum)
+}
+
+func pseudoHeaderSum(src, dst net.IP, protocol Protocol, length int) uint32 {
+	var sum uint32
+	for _, addr := range [][]byte{src, dst} {
+		for i := 0; i+1 < len(addr); i += 2 {
+			sum += uint32(binary.BigEndian.Uint16(addr[i:]))
+		}
+	}
+	sum += uint32(protocol)
+	sum += uint32(length)
+	return sum
+}
+
+// checksum calculates the internet checksum (RFC 1071) of the given data
+func checksum(data []byte, initial uint32) uint16 {
+	sum := initial
+	for i := 0; i+1 < len(data); i += 2 {
+		sum += uint32(binary.BigEndian.Uint16(data[i:]))
+	}
+	if len(data)%2 == 1 {
+		sum += uint32(data[len(data)-1]) << 8
+	}
+	for sum > 0xffff {
+		sum = (sum & 0xffff) + (sum >> 16)
+	}
+	return ^uint16(sum)
+}
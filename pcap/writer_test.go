@@ -0,0 +1,52 @@
+package pcap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecksum(t *testing.T) {
+	// example IPv4 header from https://en.wikipedia.org/wiki/Internet_checksum
+	header := []byte{0x45, 0x00, 0x00, 0x73, 0x00, 0x00, 0x40, 0x00, 0x40, 0x11, 0x00, 0x00, 0xc0, 0xa8, 0x00, 0x01, 0xc0, 0xa8, 0x00, 0xc7}
+	assert.Equal(t, uint16(0xb861), checksum(header, 0))
+}
+
+func TestWriterTCPSequenceNumbers(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	w, err := NewWriter(buffer)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(magicNanoseconds), binary.LittleEndian.Uint32(buffer.Bytes()))
+
+	client := Endpoint{IP: net.ParseIP("127.0.0.1"), Port: 40000}
+	server := Endpoint{IP: net.ParseIP("127.0.0.1"), Port: 80}
+	at := time.Unix(1700000000, 123)
+
+	require.NoError(t, w.WritePacket(at, ProtocolTCP, client, server, []byte("GET / HTTP/1.1\r\n\r\n")))
+	require.NoError(t, w.WritePacket(at, ProtocolTCP, server, client, []byte("HTTP/1.1 200 OK\r\n\r\n")))
+	require.NoError(t, w.WritePacket(at, ProtocolTCP, client, server, []byte("bye")))
+
+	data := buffer.Bytes()[24:]
+	var seqs, acks []uint32
+	for len(data) > 0 {
+		assert.Equal(t, uint32(1700000000), binary.LittleEndian.Uint32(data[0:]))
+		assert.Equal(t, uint32(123), binary.LittleEndian.Uint32(data[4:]))
+		length := binary.LittleEndian.Uint32(data[8:])
+		frame := data[16 : 16+length]
+		data = data[16+length:]
+
+		ip := frame[14:]
+		assert.Equal(t, uint16(0xffff), ^checksum(ip[:20], 0), "invalid ip checksum")
+		tcp := ip[20:]
+		assert.Equal(t, uint16(0xffff), ^checksum(tcp, pseudoHeaderSum(ip[12:16], ip[16:20], ProtocolTCP, len(tcp))), "invalid tcp checksum")
+		seqs = append(seqs, binary.BigEndian.Uint32(tcp[4:]))
+		acks = append(acks, binary.BigEndian.Uint32(tcp[8:]))
+	}
+	assert.Equal(t, []uint32{0, 0, 18}, seqs)
+	assert.Equal(t, []uint32{0, 18, 19}, acks)
+}
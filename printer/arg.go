@@ -43,14 +43,16 @@ func (p *Printer) PrintArgValue(arg *tracer.Arg, colour Colour, exit bool, propC
 	switch arg.Type() {
 	case tracer.ArgTypeData:
 		data := arg.Data()
+		var suffix string
 		if p.maxStringLen > 0 && len(data) > p.maxStringLen {
 			if p.hexDumpLongStrings {
 				p.HexDump(arg.Raw(), arg.Data(), indent)
 				return propCount
 			}
-			data = append(data[:p.maxStringLen], []byte("...")...)
+			data = data[:p.maxStringLen]
+			suffix = "..."
 		}
-		p.PrintColour(colour, "%q", string(data))
+		p.printQuoted(data, suffix, colour)
 		//p.PrintDim(" @ 0x%x", arg.Raw())
 	case tracer.ArgTypeInt, tracer.ArgTypeLong, tracer.ArgTypeUnsignedInt, tracer.ArgTypeUnsignedLong, tracer.ArgTypeUnknown:
 		p.PrintColour(colour, "%d", arg.Int())
@@ -0,0 +1,251 @@
+package printer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/liamg/grace/tracer"
+	"github.com/liamg/grace/tracer/annotation"
+	"golang.org/x/sys/unix"
+)
+
+// chromeEvent is a single event in the Chrome Trace Event format, which can be loaded by ui.perfetto.dev and
+// chrome://tracing. See https://docs.google.com/document/d/1CvAClvFfyA5R-PhYUmn5OOQtYMH4h6I0nSsKchNAySU
+type chromeEvent struct {
+	Name      string                 `json:"name"`
+	Category  string                 `json:"cat,omitempty"`
+	Phase     string                 `json:"ph"`
+	Timestamp float64                `json:"ts"`
+	Duration  *float64               `json:"dur,omitempty"`
+	Pid       int                    `json:"pid"`
+	Tid       int                    `json:"tid"`
+	Scope     string                 `json:"s,omitempty"`
+	ID        string                 `json:"id,omitempty"`
+	BindPoint string                 `json:"bp,omitempty"`
+	Args      map[string]interface{} `json:"args,omitempty"`
+}
+
+// chromeEncoder writes a JSON array of trace events, with one complete event per syscall
+type chromeEncoder struct {
+	w         io.Writer
+	start     time.Time
+	formatter *straceEncoder
+	written   bool
+	inFlight  map[int]*tracer.Syscall // tid -> syscall which has been entered but not exited
+	names     map[int]string          // pid/tid -> last name given to the track
+	pids      map[int]int             // tid -> pid
+	flows     map[int]string          // tid -> id of a flow which should finish at the next event for that tid
+	flowID    int
+}
+
+func newChromeEncoder(p *Printer) *chromeEncoder {
+	return &chromeEncoder{
+		w:         p.w,
+		start:     p.startTime,
+		formatter: newStraceEncoder(p),
+		inFlight:  make(map[int]*tracer.Syscall),
+		names:     make(map[int]string),
+		pids:      make(map[int]int),
+		flows:     make(map[int]string),
+	}
+}
+
+func (c *chromeEncoder) timestamp(at time.Time) float64 {
+	return float64(at.Sub(c.start).Nanoseconds()) / 1000
+}
+
+func (c *chromeEncoder) write(event chromeEvent) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	if c.written {
+		_, _ = io.WriteString(c.w, ",\n")
+	} else {
+		_, _ = io.WriteString(c.w, "[\n")
+		c.written = true
+	}
+	_, _ = c.w.Write(data)
+}
+
+// nameTracks adds metadata events so the process and thread tracks are labelled with the command name
+func (c *chromeEncoder) nameTracks(call *tracer.Syscall) {
+	proc := call.Process()
+	c.pids[proc.Tid] = proc.Pid
+	name := proc.Comm
+	if name == "" {
+		name = fmt.Sprintf("%d", proc.Pid)
+	}
+	if proc.Pid == proc.Tid && c.names[-proc.Pid] != name {
+		c.names[-proc.Pid] = name
+		c.write(chromeEvent{
+			Name:  "process_name",
+			Phase: "M",
+			Pid:   proc.Pid,
+			Tid:   proc.Tid,
+			Args:  map[string]interface{}{"name": fmt.Sprintf("%s (%d)", name, proc.Pid)},
+		})
+	}
+	if c.names[proc.Tid] != name {
+		c.names[proc.Tid] = name
+		c.write(chromeEvent{
+			Name:  "thread_name",
+			Phase: "M",
+			Pid:   proc.Pid,
+			Tid:   proc.Tid,
+			Args:  map[string]interface{}{"name": fmt.Sprintf("%s (%d)", name, proc.Tid)},
+		})
+	}
+}
+
+// finishFlow ends any flow arrow which is waiting for the next event from this thread
+func (c *chromeEncoder) finishFlow(pid int, tid int, at time.Time, name string) {
+	id, ok := c.flows[tid]
+	if !ok {
+		return
+	}
+	delete(c.flows, tid)
+	c.write(chromeEvent{
+		Name:      name,
+		Category:  "process",
+		Phase:     "f",
+		BindPoint: "e",
+		Timestamp: c.timestamp(at),
+		Pid:       pid,
+		Tid:       tid,
+		ID:        id,
+	})
+}
+
+func (c *chromeEncoder) startFlow(call *tracer.Syscall, target int, name string) {
+	c.flowID++
+	id := fmt.Sprintf("%d", c.flowID)
+	c.flows[target] = id
+	c.write(chromeEvent{
+		Name:      name,
+		Category:  "process",
+		Phase:     "s",
+		Timestamp: c.timestamp(call.EnterTime()),
+		Pid:       call.Pid(),
+		Tid:       call.Tid(),
+		ID:        id,
+	})
+}
+
+func (c *chromeEncoder) syscallEnter(call *tracer.Syscall, _ bool) {
+	c.inFlight[call.Tid()] = call
+}
+
+func (c *chromeEncoder) syscallExit(call *tracer.Syscall, context bool) {
+	delete(c.inFlight, call.Tid())
+	c.nameTracks(call)
+	c.writeSyscall(call, call.ExitTime(), context)
+
+	switch call.Number() {
+	case unix.SYS_CLONE, unix.SYS_CLONE3, unix.SYS_FORK, unix.SYS_VFORK:
+		if child := call.Return().Int(); child > 0 {
+			c.startFlow(call, child, "fork")
+		}
+	case unix.SYS_EXECVE, unix.SYS_EXECVEAT:
+		if call.Errno() == 0 {
+			c.startFlow(call, call.Tid(), "exec")
+		}
+	}
+}
+
+func (c *chromeEncoder) writeSyscall(call *tracer.Syscall, end time.Time, context bool) {
+	start := c.timestamp(call.EnterTime())
+	duration := c.timestamp(end) - start
+	var args []string
+	for _, arg := range call.Args() {
+		arg := arg
+		args = append(args, c.formatter.formatArg(&arg))
+	}
+	category := "syscall"
+	if context {
+		category += ",context"
+	}
+	event := chromeEvent{
+		Name:      call.Name(),
+		Category:  category,
+		Phase:     "X",
+		Timestamp: start,
+		Duration:  &duration,
+		Pid:       call.Pid(),
+		Tid:       call.Tid(),
+		Args: map[string]interface{}{
+			"args":   strings.Join(args, ", "),
+			"number": call.Number(),
+		},
+	}
+	if !call.ExitTime().IsZero() {
+		event.Args["return"] = c.formatter.formatReturn(call)
+	}
+	c.finishFlow(call.Pid(), call.Tid(), call.EnterTime(), call.Name())
+	c.write(event)
+}
+
+func (c *chromeEncoder) signal(pid int, signal *tracer.SigInfo) {
+	c.write(chromeEvent{
+		Name:      annotation.SignalToString(int(signal.Signo)),
+		Category:  "signal",
+		Phase:     "i",
+		Scope:     "t",
+		Timestamp: c.timestamp(time.Now()),
+		Pid:       c.pidOf(pid),
+		Tid:       pid,
+		Args: map[string]interface{}{
+			"code":       annotation.SignalCodeToString(syscall.Signal(signal.Signo), signal.Code),
+			"sender_pid": signal.Pid,
+			"sender_uid": signal.Uid,
+		},
+	})
+}
+
+func (c *chromeEncoder) pidOf(tid int) int {
+	if pid, ok := c.pids[tid]; ok {
+		return pid
+	}
+	return tid
+}
+
+func (c *chromeEncoder) processExit(exit *tracer.Exit) {
+	now := exit.Time
+	pid := exit.Pid
+	name := fmt.Sprintf("exited with %d", exit.Status)
+	if exit.Signal != 0 {
+		name = "killed by " + annotation.SignalToString(int(exit.Signal))
+	}
+	// syscalls such as exit_group never return, so finish them when the process goes away
+	if call, ok := c.inFlight[pid]; ok {
+		delete(c.inFlight, pid)
+		c.nameTracks(call)
+		c.writeSyscall(call, now, false)
+	}
+	c.write(chromeEvent{
+		Name:      name,
+		Category:  "process",
+		Phase:     "i",
+		Scope:     "p",
+		Timestamp: c.timestamp(now),
+		Pid:       c.pidOf(pid),
+		Tid:       pid,
+	})
+}
+
+func (c *chromeEncoder) attach(int) {}
+
+func (c *chromeEncoder) detach(int) {}
+
+func (c *chromeEncoder) close() error {
+	if !c.written {
+		_, err := io.WriteString(c.w, "[]\n")
+		return err
+	}
+	_, err := io.WriteString(c.w, "\n]\n")
+	return err
+}
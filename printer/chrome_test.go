@@ -0,0 +1,96 @@
+package printer
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/liamg/grace/internal/tracertest"
+	"github.com/liamg/grace/tracer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chromeEvents prints using the chrome format and decodes the resulting trace
+func chromeEvents(t *testing.T, print func(p *Printer)) []chromeEvent {
+	buffer := bytes.NewBuffer(nil)
+	p := New(buffer)
+	p.SetStartTime(testStart)
+	require.NoError(t, p.SetFormat(FormatChrome))
+	print(p)
+	require.NoError(t, p.Close())
+	var events []chromeEvent
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &events), buffer.String())
+	return events
+}
+
+func Test_ChromeOutput(t *testing.T) {
+	events := chromeEvents(t, func(p *Printer) {
+		call := openatCall(t)
+		p.PrintSyscallEnter(call)
+		p.PrintSyscallExit(call)
+		p.PrintExit(&tracer.Exit{Pid: 100, Status: 0, Time: testTime(2 * time.Millisecond)})
+	})
+	require.Len(t, events, 4)
+
+	assert.Equal(t, "process_name", events[0].Name)
+	assert.Equal(t, "M", events[0].Phase)
+	assert.Equal(t, "thread_name", events[1].Name)
+	assert.Equal(t, "M", events[1].Phase)
+
+	call := events[2]
+	assert.Equal(t, "openat", call.Name)
+	assert.Equal(t, "X", call.Phase)
+	assert.Equal(t, 1000.0, call.Timestamp)
+	require.NotNil(t, call.Duration)
+	assert.Equal(t, 20.0, *call.Duration)
+	assert.Equal(t, 100, call.Pid)
+	assert.Equal(t, 100, call.Tid)
+	assert.Equal(t, `AT_FDCWD, "/etc/passwd", O_RDONLY|O_CLOEXEC`, call.Args["args"])
+	assert.Equal(t, "3", call.Args["return"])
+
+	exit := events[3]
+	assert.Equal(t, "exited with 0", exit.Name)
+	assert.Equal(t, "i", exit.Phase)
+	assert.Equal(t, 2000.0, exit.Timestamp)
+	assert.Equal(t, 100, exit.Pid)
+}
+
+func Test_ChromeOutputKilledMidSyscall(t *testing.T) {
+	events := chromeEvents(t, func(p *Printer) {
+		call := tracertest.Syscall{
+			Name:    "read",
+			Process: tracer.Process{Pid: 100, Tid: 101},
+			Args: []tracertest.Arg{
+				{Name: "fd", Type: tracer.ArgTypeInt, Raw: 3},
+			},
+			Entered:    testTime(2 * time.Millisecond),
+			Running:    true,
+			Incomplete: true,
+		}.Build(t)
+		p.PrintSyscallEnter(call)
+		p.PrintExit(&tracer.Exit{Pid: 101, Signal: 9, Time: testTime(3 * time.Millisecond)})
+	})
+	require.Len(t, events, 3)
+
+	call := events[1]
+	assert.Equal(t, "read", call.Name)
+	assert.Equal(t, "X", call.Phase)
+	assert.Equal(t, 2000.0, call.Timestamp)
+	require.NotNil(t, call.Duration)
+	assert.Equal(t, 1000.0, *call.Duration)
+	assert.Equal(t, 100, call.Pid)
+	assert.Equal(t, 101, call.Tid)
+	assert.NotContains(t, call.Args, "return")
+
+	exit := events[2]
+	assert.Equal(t, "killed by SIGKILL", exit.Name)
+	assert.Equal(t, 3000.0, exit.Timestamp)
+	assert.Equal(t, 100, exit.Pid)
+	assert.Equal(t, 101, exit.Tid)
+}
+
+func Test_ChromeOutputEmpty(t *testing.T) {
+	assert.Empty(t, chromeEvents(t, func(p *Printer) {}))
+}
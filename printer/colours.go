@@ -14,6 +14,9 @@ const (
 
 var ColourDefault Colour = 0
 
+// ColourDim is the SGR code for faint text, used for context syscalls and other secondary output
+const ColourDim Colour = 2
+
 var colours = []Colour{
 	ColourBlue,
 	ColourYellow,
@@ -21,11 +24,11 @@ var colours = []Colour{
 }
 
 func (p *Printer) currentColour() Colour {
-	return colours[p.colourIndex%len(colours)]
+	return colours[p.current.colourIndex%len(colours)]
 }
 
 func (p *Printer) nextColour() Colour {
-	colour := colours[p.colourIndex%len(colours)]
-	p.colourIndex++
+	colour := colours[p.current.colourIndex%len(colours)]
+	p.current.colourIndex++
 	return colour
 }
@@ -0,0 +1,104 @@
+package printer
+
+import "github.com/liamg/grace/tracer"
+
+// context holds the state required to print unmatched syscalls around filter matches, similar to grep -B/-A
+type context struct {
+	before  int
+	after   int
+	tracees map[int]*traceeContext
+}
+
+type traceeContext struct {
+	seq         int               // sequence number of the most recently entered syscall
+	lastPrinted int               // sequence number of the last syscall printed
+	remaining   int               // number of unmatched syscalls still to print after the last match
+	buffer      []*tracer.Syscall // recent unmatched syscalls, oldest first
+	seqs        []int
+}
+
+func (p *Printer) SetContextBefore(before int) {
+	p.context.before = before
+}
+
+func (p *Printer) SetContextAfter(after int) {
+	p.context.after = after
+}
+
+func (p *Printer) contextEnabled() bool {
+	return p.context.before > 0 || p.context.after > 0
+}
+
+func (p *Printer) traceeContext(tid int) *traceeContext {
+	if p.context.tracees == nil {
+		p.context.tracees = make(map[int]*traceeContext)
+	}
+	tracee, ok := p.context.tracees[tid]
+	if !ok {
+		tracee = &traceeContext{}
+		p.context.tracees[tid] = tracee
+	}
+	return tracee
+}
+
+func (p *Printer) contextEnter(syscall *tracer.Syscall) {
+	if !p.contextEnabled() {
+		return
+	}
+	p.traceeContext(syscall.Tid()).seq++
+}
+
+// contextMatch is called when a syscall matches the filter, and flushes any buffered context before it is printed
+func (p *Printer) contextMatch(syscall *tracer.Syscall) {
+	if !p.contextEnabled() {
+		return
+	}
+	tracee := p.traceeContext(syscall.Tid())
+	for i, buffered := range tracee.buffer {
+		p.contextSeparator(tracee, tracee.seqs[i])
+		p.printDimmedSyscall(buffered)
+		tracee.lastPrinted = tracee.seqs[i]
+	}
+	tracee.buffer = nil
+	tracee.seqs = nil
+	p.contextSeparator(tracee, tracee.seq)
+	tracee.lastPrinted = tracee.seq
+	tracee.remaining = p.context.after
+}
+
+// contextMiss is called when a syscall has exited without matching the filter
+func (p *Printer) contextMiss(syscall *tracer.Syscall) {
+	if !p.contextEnabled() {
+		return
+	}
+	tracee := p.traceeContext(syscall.Tid())
+	if tracee.remaining > 0 {
+		p.printDimmedSyscall(syscall)
+		tracee.lastPrinted = tracee.seq
+		tracee.remaining--
+		return
+	}
+	if p.context.before == 0 {
+		return
+	}
+	tracee.buffer = append(tracee.buffer, syscall)
+	tracee.seqs = append(tracee.seqs, tracee.seq)
+	if len(tracee.buffer) > p.context.before {
+		tracee.buffer = tracee.buffer[1:]
+		tracee.seqs = tracee.seqs[1:]
+	}
+}
+
+// contextSeparator marks a gap between printed groups of syscalls, in the same way as grep -C
+func (p *Printer) contextSeparator(tracee *traceeContext, seq int) {
+	if tracee.lastPrinted > 0 && seq > tracee.lastPrinted+1 {
+		p.PrintDim("--\n")
+	}
+}
+
+func (p *Printer) printDimmedSyscall(syscall *tracer.Syscall) {
+	p.dimmed = true
+	defer func() { p.dimmed = false }()
+	p.printSyscallEnter(syscall, true)
+	p.printSyscallExit(syscall)
+}
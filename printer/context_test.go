@@ -0,0 +1,123 @@
+package printer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/liamg/grace/internal/tracertest"
+	"github.com/liamg/grace/tracer"
+	"github.com/stretchr/testify/assert"
+)
+
+// nameFilter matches syscalls by name
+type nameFilter string
+
+func (f nameFilter) Match(syscall *tracer.Syscall, exit bool) bool {
+	return syscall.Name() == string(f)
+}
+
+// printCalls prints a getpid for each '.' and a getuid for each 'M' in the pattern, numbering their return values
+// from 1 so they can be told apart
+func printCalls(t *testing.T, p *Printer, pattern string) {
+	for i, c := range pattern {
+		name := "getpid"
+		if c == 'M' {
+			name = "getuid"
+		}
+		call := tracertest.Syscall{
+			Name:    name,
+			Process: tracer.Process{Pid: 100},
+			Return:  tracertest.Arg{Type: tracer.ArgTypeInt, Raw: uintptr(i + 1)},
+			Entered: time.Now(),
+		}.Build(t)
+		p.PrintSyscallEnter(call)
+		p.PrintSyscallExit(call)
+	}
+}
+
+func Test_Context(t *testing.T) {
+	tests := []struct {
+		name    string
+		before  int
+		after   int
+		pattern string
+		want    string
+	}{
+		{
+			name:    "only the most recent unmatched syscalls are printed before a match",
+			before:  2,
+			pattern: ".....M.",
+			want: `getpid() = 4
+getpid() = 5
+getuid() = 6
+`,
+		},
+		{
+			name:    "groups are separated",
+			before:  1,
+			after:   1,
+			pattern: "..M...M..",
+			want: `getpid() = 2
+getuid() = 3
+getpid() = 4
+--
+getpid() = 6
+getuid() = 7
+getpid() = 8
+`,
+		},
+		{
+			name:    "overlapping contexts are merged without repeating syscalls",
+			before:  2,
+			after:   2,
+			pattern: ".M...M.M...",
+			want: `getpid() = 1
+getuid() = 2
+getpid() = 3
+getpid() = 4
+getpid() = 5
+getuid() = 6
+getpid() = 7
+getuid() = 8
+getpid() = 9
+getpid() = 10
+`,
+		},
+		{
+			name:    "adjacent contexts are not separated",
+			before:  1,
+			after:   1,
+			pattern: "M..M",
+			want: `getuid() = 1
+getpid() = 2
+getpid() = 3
+getuid() = 4
+`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buffer := bytes.NewBuffer(nil)
+			p := New(buffer)
+			p.SetUseColours(false)
+			p.SetFilter(nameFilter("getuid"))
+			p.SetContextBefore(test.before)
+			p.SetContextAfter(test.after)
+			printCalls(t, p, test.pattern)
+			assert.Equal(t, test.want, buffer.String())
+		})
+	}
+}
+
+func Test_ContextIsDimmed(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	p := New(buffer)
+	p.SetFilter(nameFilter("getuid"))
+	p.SetContextBefore(1)
+	printCalls(t, p, "..M")
+	lines := strings.SplitAfter(buffer.String(), "\n")
+	// every part of a context syscall is dimmed, including the return value which is usually green
+	assert.Equal(t, "\x1b[2mgetpid\x1b[0m\x1b[2m(\x1b[0m\x1b[2m)\x1b[0m\x1b[2m = \x1b[0m\x1b[2m2\x1b[0m\n", lines[0])
+}
@@ -0,0 +1,71 @@
+package printer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/liamg/grace/internal/tracertest"
+	"github.com/liamg/grace/tracer"
+)
+
+// testStart is the time tracing started in tests, so that output containing timestamps is stable
+var testStart = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func testTime(offset time.Duration) time.Time {
+	return testStart.Add(offset)
+}
+
+// openatCall is a successful openat made by pid 100
+func openatCall(t *testing.T) *tracer.Syscall {
+	return tracertest.Syscall{
+		Name:    "openat",
+		Process: tracer.Process{Pid: 100},
+		Args: []tracertest.Arg{
+			{Name: "dfd", Type: tracer.ArgTypeInt, Raw: uintptr(0xffffffffffffff9c), Annotation: "AT_FDCWD"},
+			{Name: "filename", Type: tracer.ArgTypeData, Data: []byte("/etc/passwd")},
+			{Name: "flags", Type: tracer.ArgTypeInt, Raw: 0x80000, Annotation: "O_RDONLY|O_CLOEXEC"},
+		},
+		Return:   tracertest.Arg{Type: tracer.ArgTypeInt, Raw: 3},
+		Paths:    []string{"/etc/passwd"},
+		Entered:  testTime(time.Millisecond),
+		Duration: 20 * time.Microsecond,
+	}.Build(t)
+}
+
+// readCall is a read by thread 101 of pid 100, which reads data that is not valid UTF-8
+func readCall(t *testing.T) *tracer.Syscall {
+	return tracertest.Syscall{
+		Name:    "read",
+		Process: tracer.Process{Pid: 100, Tid: 101},
+		Args: []tracertest.Arg{
+			{Name: "fd", Type: tracer.ArgTypeInt, Raw: 3},
+			{Name: "buf", Type: tracer.ArgTypeData, Data: []byte("root\xff\n")},
+			{Name: "count", Type: tracer.ArgTypeUnsignedLong, Raw: 4096},
+		},
+		Return:   tracertest.Arg{Type: tracer.ArgTypeInt, Raw: 6},
+		Entered:  testTime(2 * time.Millisecond),
+		Duration: 150 * time.Microsecond,
+	}.Build(t)
+}
+
+// mmapCall is an mmap by pid 100 which fails with ENOMEM
+func mmapCall(t *testing.T) *tracer.Syscall {
+	return tracertest.Syscall{
+		Name:    "mmap",
+		Process: tracer.Process{Pid: 100},
+		Args: []tracertest.Arg{
+			{Name: "addr", Type: tracer.ArgTypeAddress},
+			{Name: "len", Type: tracer.ArgTypeUnsignedLong, Raw: 4096},
+			{Name: "prot", Type: tracer.ArgTypeInt, Raw: 3, Annotation: "PROT_READ|PROT_WRITE"},
+			{Name: "flags", Type: tracer.ArgTypeInt, Raw: 0x22, Annotation: "MAP_PRIVATE|MAP_ANONYMOUS"},
+			{Name: "fd", Type: tracer.ArgTypeInt, Raw: uintptr(0xffffffffffffffff)},
+			{Name: "off", Type: tracer.ArgTypeUnsignedLong},
+		},
+		Return:   tracertest.Arg{Type: tracer.ArgTypeAddress, Raw: tracertest.Errno(12)},
+		Entered:  testTime(3 * time.Millisecond),
+		Duration: 5 * time.Microsecond,
+	}.Build(t)
+}
+
+// segfault is a SIGSEGV raised by the kernel
+var segfault = &tracer.SigInfo{Signo: 11, Code: 1}
@@ -0,0 +1,58 @@
+package printer
+
+import (
+	"fmt"
+
+	"github.com/liamg/grace/tracer"
+)
+
+type Format string
+
+const (
+	FormatText   Format = "text"
+	FormatJSON   Format = "json"
+	FormatStrace Format = "strace"
+	FormatChrome Format = "chrome"
+)
+
+var formats = []Format{
+	FormatText,
+	FormatJSON,
+	FormatStrace,
+	FormatChrome,
+}
+
+// encoder renders events in a machine-readable format, in place of the default coloured text output
+type encoder interface {
+	syscallEnter(syscall *tracer.Syscall, context bool)
+	syscallExit(syscall *tracer.Syscall, context bool)
+	signal(pid int, signal *tracer.SigInfo)
+	processExit(exit *tracer.Exit)
+	attach(pid int)
+	detach(pid int)
+	close() error
+}
+
+func (p *Printer) SetFormat(format Format) error {
+	switch format {
+	case FormatText, "":
+		p.encoder = nil
+	case FormatJSON:
+		p.encoder = newJSONEncoder(p.w, p.startTime)
+	case FormatStrace:
+		p.encoder = newStraceEncoder(p)
+	case FormatChrome:
+		p.encoder = newChromeEncoder(p)
+	default:
+		return fmt.Errorf("unsupported output format '%s' - supported formats are %v", format, formats)
+	}
+	return nil
+}
+
+// Close flushes any remaining output
+func (p *Printer) Close() error {
+	if p.encoder != nil {
+		return p.encoder.close()
+	}
+	return nil
+}
@@ -12,6 +12,8 @@ func (p *Printer) HexDump(addr uintptr, data []byte, indent int) {
 		data = data[:p.maxHexDumpLen]
 	}
 
+	mask := p.highlightMask(data)
+
 	startAddr := addr - (addr % dumpWidth)
 	endAddr := addr + uintptr(len(data))
 	if endAddr%dumpWidth > 0 {
@@ -31,6 +33,9 @@ func (p *Printer) HexDump(addr uintptr, data []byte, indent int) {
 			local := (i + uintptr(j)) - addr
 			if i+uintptr(j) < addr || local >= uintptr(len(data)) {
 				p.PrintDim(".. ")
+			} else if mask != nil && mask[local] {
+				p.PrintHighlight(ColourRed, "%02x", data[local])
+				p.Print(" ")
 			} else {
 				p.PrintColour(ColourRed, "%02x ", data[local])
 			}
@@ -44,7 +49,11 @@ func (p *Printer) HexDump(addr uintptr, data []byte, indent int) {
 				if c < 32 || c > 126 {
 					c = '.'
 				}
-				p.PrintColour(ColourBlue, "%c", c)
+				if mask != nil && mask[local] {
+					p.PrintHighlight(ColourBlue, "%c", c)
+				} else {
+					p.PrintColour(ColourBlue, "%c", c)
+				}
 			}
 		}
 	}
@@ -0,0 +1,61 @@
+package printer
+
+import "strconv"
+
+// Highlighter can be implemented by a Filter to highlight matching parts of buffers in the output
+type Highlighter interface {
+	Highlight(data []byte) [][2]int
+}
+
+func (p *Printer) highlightMask(data []byte) []bool {
+	highlighter, ok := p.filter.(Highlighter)
+	if !ok {
+		return nil
+	}
+	ranges := highlighter.Highlight(data)
+	if len(ranges) == 0 {
+		return nil
+	}
+	mask := make([]bool, len(data))
+	for _, r := range ranges {
+		for i := r[0]; i < r[1] && i < len(mask); i++ {
+			mask[i] = true
+		}
+	}
+	return mask
+}
+
+func (p *Printer) PrintHighlight(colour Colour, format string, args ...interface{}) {
+	if p.useColours && !p.dimmed {
+		p.Print("\x1b[7;%dm", colour)
+	}
+	p.Print(format, args...)
+	if p.useColours && !p.dimmed {
+		p.Print("\x1b[0m")
+	}
+}
+
+// printQuoted prints data as a quoted string, highlighting any parts which match the filter
+func (p *Printer) printQuoted(data []byte, suffix string, colour Colour) {
+	mask := p.highlightMask(data)
+	if mask == nil {
+		p.PrintColour(colour, "%q", string(data)+suffix)
+		return
+	}
+	p.PrintColour(colour, "\"")
+	for start := 0; start < len(data); {
+		end := start
+		for end < len(data) && mask[end] == mask[start] {
+			end++
+		}
+		segment := strconv.Quote(string(data[start:end]))
+		segment = segment[1 : len(segment)-1]
+		if mask[start] {
+			p.PrintHighlight(colour, "%s", segment)
+		} else {
+			p.PrintColour(colour, "%s", segment)
+		}
+		start = end
+	}
+	p.PrintColour(colour, "%s\"", suffix)
+}
@@ -0,0 +1,223 @@
+package printer
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"github.com/liamg/grace/tracer"
+	"github.com/liamg/grace/tracer/annotation"
+)
+
+// JSONSchemaVersion is incremented whenever a breaking change is made to the JSON output format
+const JSONSchemaVersion = 1
+
+type jsonEvent struct {
+	Version    int          `json:"version"`
+	Type       string       `json:"type"`
+	Time       time.Time    `json:"time"`
+	RelativeNS int64        `json:"relative_ns"`
+	Pid        int          `json:"pid"`
+	Tid        int          `json:"tid,omitempty"`
+	Context    bool         `json:"context,omitempty"`
+	Syscall    *jsonSyscall `json:"syscall,omitempty"`
+	Signal     *jsonSignal  `json:"signal,omitempty"`
+	ExitStatus *int         `json:"exit_status,omitempty"`
+	ExitSignal string       `json:"exit_signal,omitempty"`
+}
+
+type jsonSyscall struct {
+	Name       string    `json:"name"`
+	Number     int       `json:"number"`
+	Unknown    bool      `json:"unknown,omitempty"`
+	Complete   bool      `json:"complete"`
+	Args       []jsonArg `json:"args"`
+	Return     *jsonArg  `json:"return,omitempty"`
+	Errno      string    `json:"errno,omitempty"`
+	DurationNS *int64    `json:"duration_ns,omitempty"`
+	Paths      []string  `json:"paths,omitempty"`
+}
+
+type jsonArg struct {
+	Name       string      `json:"name,omitempty"`
+	Type       string      `json:"type"`
+	Raw        uint64      `json:"raw"`
+	Value      *int64      `json:"value,omitempty"`
+	Data       *string     `json:"data,omitempty"`
+	DataBase64 string      `json:"data_base64,omitempty"`
+	Annotation string      `json:"annotation,omitempty"`
+	Replace    bool        `json:"replace,omitempty"`
+	Object     *jsonObject `json:"object,omitempty"`
+	Array      []jsonArg   `json:"array,omitempty"`
+}
+
+type jsonObject struct {
+	Name       string    `json:"name"`
+	Properties []jsonArg `json:"properties"`
+}
+
+type jsonSignal struct {
+	Name      string `json:"name"`
+	Number    int    `json:"number"`
+	Code      string `json:"code"`
+	SenderPid int    `json:"sender_pid"`
+	SenderUid int    `json:"sender_uid"`
+}
+
+// jsonEncoder writes one JSON object per line for each event (JSON Lines)
+type jsonEncoder struct {
+	enc   *json.Encoder
+	start time.Time
+	now   func() time.Time // the time of events which aren't timestamped by the tracer
+}
+
+func newJSONEncoder(w io.Writer, start time.Time) *jsonEncoder {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &jsonEncoder{
+		enc:   enc,
+		start: start,
+		now:   time.Now,
+	}
+}
+
+func (j *jsonEncoder) write(event jsonEvent) {
+	event.Version = JSONSchemaVersion
+	if event.Time.IsZero() {
+		event.Time = j.now()
+	}
+	event.RelativeNS = event.Time.Sub(j.start).Nanoseconds()
+	_ = j.enc.Encode(event)
+}
+
+func (j *jsonEncoder) syscallEnter(call *tracer.Syscall, context bool) {
+	j.write(jsonEvent{
+		Type:    "syscall_enter",
+		Time:    call.EnterTime(),
+		Pid:     call.Pid(),
+		Tid:     call.Tid(),
+		Context: context,
+		Syscall: convertJSONSyscall(call, false),
+	})
+}
+
+func (j *jsonEncoder) syscallExit(call *tracer.Syscall, context bool) {
+	j.write(jsonEvent{
+		Type:    "syscall_exit",
+		Time:    call.ExitTime(),
+		Pid:     call.Pid(),
+		Tid:     call.Tid(),
+		Context: context,
+		Syscall: convertJSONSyscall(call, true),
+	})
+}
+
+func (j *jsonEncoder) signal(pid int, signal *tracer.SigInfo) {
+	j.write(jsonEvent{
+		Type: "signal",
+		Pid:  pid,
+		Signal: &jsonSignal{
+			Name:      annotation.SignalToString(int(signal.Signo)),
+			Number:    int(signal.Signo),
+			Code:      annotation.SignalCodeToString(syscall.Signal(signal.Signo), signal.Code),
+			SenderPid: int(signal.Pid),
+			SenderUid: int(signal.Uid),
+		},
+	})
+}
+
+func (j *jsonEncoder) processExit(exit *tracer.Exit) {
+	event := jsonEvent{
+		Type: "process_exit",
+		Time: exit.Time,
+		Pid:  exit.Pid,
+	}
+	if exit.Signal != 0 {
+		event.ExitSignal = annotation.SignalToString(int(exit.Signal))
+	} else {
+		event.ExitStatus = &exit.Status
+	}
+	j.write(event)
+}
+
+func (j *jsonEncoder) attach(pid int) {
+	j.write(jsonEvent{
+		Type: "attach",
+		Pid:  pid,
+	})
+}
+
+func (j *jsonEncoder) detach(pid int) {
+	j.write(jsonEvent{
+		Type: "detach",
+		Pid:  pid,
+	})
+}
+
+func (j *jsonEncoder) close() error {
+	return nil
+}
+
+func convertJSONSyscall(call *tracer.Syscall, exit bool) *jsonSyscall {
+	output := &jsonSyscall{
+		Name:     call.Name(),
+		Number:   call.Number(),
+		Unknown:  call.Unknown(),
+		Complete: call.Complete(),
+		Args:     convertJSONArgs(call.Args()),
+		Paths:    call.Paths(),
+	}
+	if exit {
+		ret := convertJSONArg(call.Return())
+		output.Return = &ret
+		if errno := call.Errno(); errno != 0 {
+			output.Errno = annotation.ErrNoToString(errno)
+		}
+		duration := call.Duration().Nanoseconds()
+		output.DurationNS = &duration
+	}
+	return output
+}
+
+func convertJSONArgs(args []tracer.Arg) []jsonArg {
+	output := make([]jsonArg, 0, len(args))
+	for _, arg := range args {
+		output = append(output, convertJSONArg(arg))
+	}
+	return output
+}
+
+func convertJSONArg(arg tracer.Arg) jsonArg {
+	output := jsonArg{
+		Name:       arg.Name(),
+		Type:       arg.Type().String(),
+		Raw:        uint64(arg.Raw()),
+		Annotation: arg.Annotation(),
+		Replace:    arg.ReplaceValueWithAnnotation(),
+	}
+	switch arg.Type() {
+	case tracer.ArgTypeData:
+		if data := arg.Data(); utf8.Valid(data) {
+			str := string(data)
+			output.Data = &str
+		} else {
+			output.DataBase64 = base64.StdEncoding.EncodeToString(data)
+		}
+	case tracer.ArgTypeInt, tracer.ArgTypeLong, tracer.ArgTypeUnsignedInt, tracer.ArgTypeUnsignedLong, tracer.ArgTypeUnknown, tracer.ArgTypeErrorCode:
+		value := int64(arg.Int())
+		output.Value = &value
+	case tracer.ArgTypeObject:
+		if obj := arg.Object(); obj != nil {
+			output.Object = &jsonObject{
+				Name:       obj.Name,
+				Properties: convertJSONArgs(obj.Properties),
+			}
+		}
+	case tracer.ArgTypeArray:
+		output.Array = convertJSONArgs(arg.Array())
+	}
+	return output
+}
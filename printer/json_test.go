@@ -0,0 +1,49 @@
+package printer
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/liamg/grace/tracer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_JSONOutput(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	p := New(buffer)
+	p.SetStartTime(testStart)
+	require.NoError(t, p.SetFormat(FormatJSON))
+	p.encoder.(*jsonEncoder).now = func() time.Time {
+		return testTime(4 * time.Millisecond)
+	}
+
+	p.PrintAttach(100)
+	open := openatCall(t)
+	p.PrintSyscallEnter(open)
+	p.PrintSyscallExit(open)
+	read := readCall(t)
+	p.PrintSyscallEnter(read)
+	mmap := mmapCall(t)
+	p.PrintSyscallEnter(mmap)
+	p.PrintSyscallExit(mmap)
+	p.PrintSyscallExit(read)
+	p.PrintSignal(100, segfault)
+	p.PrintExit(&tracer.Exit{Pid: 101, Status: 0, Time: testTime(5 * time.Millisecond)})
+	p.PrintExit(&tracer.Exit{Pid: 100, Signal: 11, Time: testTime(6 * time.Millisecond)})
+	p.PrintDetach(100)
+	require.NoError(t, p.Close())
+
+	golden, err := os.ReadFile("testdata/events.jsonl")
+	require.NoError(t, err)
+	assert.Equal(t, string(golden), buffer.String())
+
+	// every line must be a standalone JSON object
+	for _, line := range strings.Split(strings.TrimSpace(buffer.String()), "\n") {
+		assert.True(t, json.Valid([]byte(line)), line)
+	}
+}
@@ -6,27 +6,34 @@ import (
 	"time"
 
 	"github.com/liamg/grace/tracer"
+	"github.com/liamg/grace/tracer/annotation"
 )
 
 type Printer struct {
-	w                      io.Writer
-	useColours             bool
-	maxStringLen           int
-	hexDumpLongStrings     bool
-	maxHexDumpLen          int
-	maxObjectProperties    int
-	colourIndex            int
-	argProgress            int
-	extraNewLine           bool
-	multiline              bool
-	inSyscall              bool
-	filter                 Filter
-	lastEntryMatchedFilter bool
-	relativeTimestamps     bool
-	absoluteTimestamps     bool
-	startTime              time.Time
-	showNumbers            bool
-	rawOutput              bool
+	w                   io.Writer
+	useColours          bool
+	maxStringLen        int
+	hexDumpLongStrings  bool
+	maxHexDumpLen       int
+	maxObjectProperties int
+	extraNewLine        bool
+	multiline           bool
+	filter              Filter
+	relativeTimestamps  bool
+	absoluteTimestamps  bool
+	startTime           time.Time
+	showNumbers         bool
+	rawOutput           bool
+	context             context
+	dimmed              bool
+	encoder             encoder
+	pid                 int
+	tracees             map[int]*traceeState
+	current             *traceeState // state of the thread whose event is being printed
+	currentTid          int
+	open                int // tid of the thread with a partially printed line, or zero
+	showPids            bool
+	pidColours          map[int]Colour
 }
 
 type Filter interface {
@@ -42,11 +49,17 @@ func New(w io.Writer) *Printer {
 		maxHexDumpLen:       4096,
 		maxObjectProperties: 2,
 		startTime:           time.Now(),
+		current:             &traceeState{},
 	}
 }
 
 const indentSize = 4
 
+// SetStartTime sets the time which relative timestamps are calculated from
+func (p *Printer) SetStartTime(start time.Time) {
+	p.startTime = start
+}
+
 func (p *Printer) SetUseColours(useColours bool) {
 	p.useColours = useColours
 }
@@ -96,11 +109,15 @@ func (p *Printer) SetRawOutput(output bool) {
 }
 
 func (p *Printer) PrefixEvent() {
+	p.prefixLine(time.Now())
+}
+
+func (p *Printer) prefixEventAt(at time.Time) {
 	if p.relativeTimestamps {
-		p.PrintDim("%12s ", time.Since(p.startTime))
+		p.PrintDim("%12s ", at.Sub(p.startTime))
 	}
 	if p.absoluteTimestamps {
-		p.PrintDim("%18s ", time.Now().Format("15:04:05.999999999"))
+		p.PrintDim("%18s ", at.Format("15:04:05.999999999"))
 	}
 }
 
@@ -109,10 +126,13 @@ func (p *Printer) Print(format string, args ...interface{}) {
 }
 
 func (p *Printer) PrintDim(format string, args ...interface{}) {
-	p.PrintColour(2, format, args...)
+	p.PrintColour(ColourDim, format, args...)
 }
 
 func (p *Printer) PrintColour(colour Colour, format string, args ...interface{}) {
+	if p.dimmed {
+		colour = ColourDim
+	}
 	if p.useColours {
 		p.Print("\x1b[%dm", colour)
 	}
@@ -122,21 +142,57 @@ func (p *Printer) PrintColour(colour Colour, format string, args ...interface{})
 	}
 }
 
+// PrintProcessExit prints the exit status of the traced process
 func (p *Printer) PrintProcessExit(i int) {
-	colour := ColourGreen
-	if i != 0 {
-		colour = ColourRed
+	p.PrintExit(&tracer.Exit{Pid: p.pid, Tgid: p.pid, Status: i, Time: time.Now()})
+}
+
+// PrintExit prints the exit of a traced process or thread, finishing any syscall it was making
+func (p *Printer) PrintExit(exit *tracer.Exit) {
+	state := p.switchTo(exit.Pid)
+	defer delete(p.tracees, exit.Pid)
+	if exit.Tgid != 0 {
+		// the thread may not have made a syscall, so the printer may not know its process yet
+		state.pid = exit.Tgid
 	}
-	if p.inSyscall {
+	if p.encoder != nil {
+		p.encoder.processExit(exit)
+		state.inSyscall = false
+		return
+	}
+	if state.inSyscall {
+		if state.unfinished {
+			p.prefixLine(exit.Time)
+			p.PrintDim("<... %s resumed>", state.name)
+		}
 		p.PrintDim(" = ?\n")
+		p.open = 0
 	}
 	if p.multiline {
 		p.Print("\n")
 	}
-	p.PrintColour(colour, "Process exited with status %d\n", i)
+	p.prefixLine(exit.Time)
+	subject := "Process"
+	if exit.Tgid != 0 && exit.Tgid != exit.Pid {
+		subject = "Thread"
+	}
+	switch {
+	case exit.Signal != 0:
+		p.PrintColour(ColourRed, "%s killed by %s\n", subject, annotation.SignalToString(int(exit.Signal)))
+	case exit.Status != 0:
+		p.PrintColour(ColourRed, "%s exited with status %d\n", subject, exit.Status)
+	default:
+		p.PrintColour(ColourGreen, "%s exited with status %d\n", subject, exit.Status)
+	}
 }
 
 func (p *Printer) PrintAttach(pid int) {
+	p.pid = pid
+	p.switchTo(pid)
+	if p.encoder != nil {
+		p.encoder.attach(pid)
+		return
+	}
 	p.PrintColour(ColourYellow, "Attached to process %d\n", pid)
 	if p.multiline {
 		p.Print("\n")
@@ -144,6 +200,14 @@ func (p *Printer) PrintAttach(pid int) {
 }
 
 func (p *Printer) PrintDetach(pid int) {
+	if p.encoder != nil {
+		p.encoder.detach(pid)
+		return
+	}
+	if p.open != 0 {
+		p.PrintDim(" <detached ...>\n")
+		p.open = 0
+	}
 	p.PrintColour(ColourYellow, "Detached from process %d\n", pid)
 	if p.multiline {
 		p.Print("\n")
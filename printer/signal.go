@@ -2,13 +2,21 @@ package printer
 
 import (
 	"syscall"
+	"time"
 
 	"github.com/liamg/grace/tracer"
 	"github.com/liamg/grace/tracer/annotation"
 )
 
-func (p *Printer) PrintSignal(signal *tracer.SigInfo) {
-	p.PrefixEvent()
+// PrintSignal prints a signal received by the given thread
+func (p *Printer) PrintSignal(tid int, signal *tracer.SigInfo) {
+	p.interrupt()
+	p.switchTo(tid)
+	if p.encoder != nil {
+		p.encoder.signal(tid, signal)
+		return
+	}
+	p.prefixLine(time.Now())
 	p.PrintColour(ColourMagenta, "--> ")
 	p.PrintColour(
 		ColourCyan,
@@ -0,0 +1,309 @@
+package printer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/liamg/grace/tracer"
+	"github.com/liamg/grace/tracer/annotation"
+)
+
+// straceColumn is the column that strace aligns return values to
+const straceColumn = 40
+
+// straceEncoder renders events in the same format as strace, so output can be used with existing tooling
+type straceEncoder struct {
+	p         *Printer
+	w         io.Writer
+	tracees   map[int]*straceTracee
+	open      int // tid of the tracee with a partially written line, or zero
+	lastEntry time.Time
+}
+
+type straceTracee struct {
+	inSyscall  bool
+	progress   int  // number of arguments already written
+	remaining  bool // some arguments are only known once the syscall exits
+	separated  bool // the separator before the next argument has already been written
+	lineLen    int
+	unfinished bool
+}
+
+func newStraceEncoder(p *Printer) *straceEncoder {
+	return &straceEncoder{
+		p:       p,
+		w:       p.w,
+		tracees: make(map[int]*straceTracee),
+	}
+}
+
+func (s *straceEncoder) tracee(tid int) *straceTracee {
+	tracee, ok := s.tracees[tid]
+	if !ok {
+		tracee = &straceTracee{}
+		s.tracees[tid] = tracee
+	}
+	return tracee
+}
+
+func (s *straceEncoder) write(tracee *straceTracee, str string) {
+	_, _ = io.WriteString(s.w, str)
+	if i := strings.LastIndex(str, "\n"); i >= 0 {
+		tracee.lineLen = len(str) - i - 1
+	} else {
+		tracee.lineLen += len(str)
+	}
+}
+
+// interrupt marks the currently open line as unfinished if another tracee wants to write output
+func (s *straceEncoder) interrupt(tid int) {
+	if s.open == 0 || s.open == tid {
+		return
+	}
+	tracee := s.tracee(s.open)
+	// like strace, the separator before the arguments which are written at exit is written before the interruption
+	if tracee.remaining && tracee.progress > 0 {
+		s.write(tracee, ", ")
+		tracee.separated = true
+	}
+	s.write(tracee, " <unfinished ...>\n")
+	tracee.unfinished = true
+	s.open = 0
+}
+
+func (s *straceEncoder) prefix(tid int, at time.Time) string {
+	var prefix string
+	// like strace, we only prefix lines with the pid once there is more than one tracee
+	if len(s.tracees) > 1 {
+		prefix = fmt.Sprintf("[pid %5d] ", tid)
+	}
+	if s.p.absoluteTimestamps {
+		prefix += at.Format("15:04:05.000000") + " "
+	}
+	if s.p.relativeTimestamps {
+		var relative time.Duration
+		if !s.lastEntry.IsZero() {
+			relative = at.Sub(s.lastEntry)
+		}
+		prefix += fmt.Sprintf("%12.6f ", relative.Seconds())
+	}
+	return prefix
+}
+
+func (s *straceEncoder) syscallEnter(call *tracer.Syscall, _ bool) {
+	tid := call.Tid()
+	s.interrupt(tid)
+	tracee := s.tracee(tid)
+	tracee.lineLen = 0
+	tracee.progress = 0
+	tracee.remaining = !call.Complete()
+	tracee.separated = false
+	tracee.unfinished = false
+	tracee.inSyscall = true
+	s.write(tracee, s.prefix(tid, call.EnterTime())+call.Name()+"(")
+	s.lastEntry = call.EnterTime()
+	s.writeArgs(tracee, call, false)
+	s.open = tid
+}
+
+func (s *straceEncoder) syscallExit(call *tracer.Syscall, _ bool) {
+	tid := call.Tid()
+	s.interrupt(tid)
+	tracee := s.tracee(tid)
+	if tracee.unfinished {
+		s.write(tracee, fmt.Sprintf("%s<... %s resumed>", s.prefix(tid, call.ExitTime()), call.Name()))
+	}
+	s.writeArgs(tracee, call, true)
+	s.write(tracee, ")")
+	s.writeReturn(tracee, s.formatReturn(call))
+	tracee.inSyscall = false
+	tracee.unfinished = false
+	s.open = 0
+}
+
+func (s *straceEncoder) writeArgs(tracee *straceTracee, call *tracer.Syscall, exit bool) {
+	args := call.Args()
+	for tracee.progress < len(args) {
+		arg := args[tracee.progress]
+		if !arg.Known() {
+			break
+		}
+		if tracee.progress > 0 && !tracee.separated {
+			s.write(tracee, ", ")
+		}
+		tracee.separated = false
+		s.write(tracee, s.formatArg(&arg))
+		tracee.progress++
+	}
+}
+
+func (s *straceEncoder) writeReturn(tracee *straceTracee, ret string) {
+	if pad := straceColumn - 1 - tracee.lineLen; pad > 0 {
+		s.write(tracee, strings.Repeat(" ", pad))
+	}
+	s.write(tracee, " = "+ret+"\n")
+}
+
+func (s *straceEncoder) formatReturn(call *tracer.Syscall) string {
+	if errno := call.Errno(); errno != 0 {
+		name := annotation.ErrNoToString(errno)
+		desc := syscall.Errno(errno).Error()
+		if desc != "" {
+			// strace uses strerror(), which is capitalised
+			desc = strings.ToUpper(desc[:1]) + desc[1:]
+		}
+		if errno >= 512 && errno <= 516 {
+			// kernel-internal restart codes are never seen by the process, so strace shows these as '?'
+			return fmt.Sprintf("? %s (%s)", name, desc)
+		}
+		return fmt.Sprintf("-1 %s (%s)", name, desc)
+	}
+	ret := call.Return()
+	return s.formatArg(&ret)
+}
+
+func (s *straceEncoder) formatArg(arg *tracer.Arg) string {
+	var count int
+	return s.formatArgValue(arg, &count)
+}
+
+func (s *straceEncoder) formatArgValue(arg *tracer.Arg, count *int) string {
+
+	if s.p.rawOutput {
+		return fmt.Sprintf("%#x", arg.Raw())
+	}
+
+	if arg.ReplaceValueWithAnnotation() {
+		return arg.Annotation()
+	}
+
+	switch arg.Type() {
+	case tracer.ArgTypeData:
+		return straceQuote(arg.Data(), s.p.maxStringLen)
+	case tracer.ArgTypeAddress:
+		if arg.Raw() == 0 {
+			return "NULL"
+		}
+		return fmt.Sprintf("%#x", arg.Raw())
+	case tracer.ArgTypeObject:
+		obj := arg.Object()
+		if obj == nil {
+			return "NULL"
+		}
+		var props []string
+		for i, prop := range obj.Properties {
+			if s.p.maxObjectProperties > 0 && *count >= s.p.maxObjectProperties && i < len(obj.Properties) {
+				props = append(props, "...")
+				break
+			}
+			*count++
+			props = append(props, prop.Name()+"="+s.formatArgValue(&prop, count))
+		}
+		return "{" + strings.Join(props, ", ") + "}"
+	case tracer.ArgTypeArray:
+		var elements []string
+		for i, element := range arg.Array() {
+			if s.p.maxObjectProperties > 0 && *count >= s.p.maxObjectProperties && i < len(arg.Array()) {
+				elements = append(elements, "...")
+				break
+			}
+			*count++
+			elements = append(elements, s.formatArgValue(&element, count))
+		}
+		return "[" + strings.Join(elements, ", ") + "]"
+	default:
+		return fmt.Sprintf("%d", arg.Int())
+	}
+}
+
+// straceQuote quotes data in the same way as strace, using C escape sequences
+func straceQuote(data []byte, maxLen int) string {
+	var truncated bool
+	if maxLen > 0 && len(data) > maxLen {
+		data = data[:maxLen]
+		truncated = true
+	}
+	var builder strings.Builder
+	builder.WriteByte('"')
+	for i, c := range data {
+		switch c {
+		case '"':
+			builder.WriteString(`\"`)
+		case '\\':
+			builder.WriteString(`\\`)
+		case '\n':
+			builder.WriteString(`\n`)
+		case '\t':
+			builder.WriteString(`\t`)
+		case '\r':
+			builder.WriteString(`\r`)
+		case '\v':
+			builder.WriteString(`\v`)
+		case '\f':
+			builder.WriteString(`\f`)
+		default:
+			if c >= 32 && c < 127 {
+				builder.WriteByte(c)
+				continue
+			}
+			// use the shortest octal escape, unless the next character is a digit
+			if i+1 < len(data) && data[i+1] >= '0' && data[i+1] <= '7' {
+				builder.WriteString(fmt.Sprintf("\\%03o", c))
+			} else {
+				builder.WriteString(fmt.Sprintf("\\%o", c))
+			}
+		}
+	}
+	builder.WriteByte('"')
+	if truncated {
+		builder.WriteString("...")
+	}
+	return builder.String()
+}
+
+func (s *straceEncoder) signal(pid int, signal *tracer.SigInfo) {
+	s.interrupt(pid)
+	tracee := s.tracee(pid)
+	name := annotation.SignalToString(int(signal.Signo))
+	s.write(tracee, fmt.Sprintf(
+		"%s--- %s {si_signo=%s, si_code=%s, si_pid=%d, si_uid=%d} ---\n",
+		s.prefix(pid, time.Now()),
+		name,
+		name,
+		annotation.SignalCodeToString(syscall.Signal(signal.Signo), signal.Code),
+		signal.Pid,
+		signal.Uid,
+	))
+}
+
+func (s *straceEncoder) processExit(exit *tracer.Exit) {
+	pid := exit.Pid
+	s.interrupt(pid)
+	tracee := s.tracee(pid)
+	if tracee.inSyscall {
+		if tracee.unfinished {
+			s.write(tracee, s.prefix(pid, exit.Time)+"<... resumed>)")
+		} else {
+			s.write(tracee, ")")
+		}
+		s.writeReturn(tracee, "?")
+		tracee.inSyscall = false
+	}
+	s.open = 0
+	if exit.Signal != 0 {
+		s.write(tracee, fmt.Sprintf("%s+++ killed by %s +++\n", s.prefix(pid, exit.Time), annotation.SignalToString(int(exit.Signal))))
+		return
+	}
+	s.write(tracee, fmt.Sprintf("%s+++ exited with %d +++\n", s.prefix(pid, exit.Time), exit.Status))
+}
+
+func (s *straceEncoder) attach(int) {}
+
+func (s *straceEncoder) detach(int) {}
+
+func (s *straceEncoder) close() error {
+	return nil
+}
@@ -0,0 +1,169 @@
+package printer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/liamg/grace/internal/tracertest"
+	"github.com/liamg/grace/tracer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_StraceQuote(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   []byte
+		maxLen int
+		want   string
+	}{
+		{
+			name: "printable",
+			data: []byte("/etc/passwd"),
+			want: `"/etc/passwd"`,
+		},
+		{
+			name: "escapes",
+			data: []byte("a\"b\\c\n\t"),
+			want: `"a\"b\\c\n\t"`,
+		},
+		{
+			name: "short octal",
+			data: []byte{0x7f, 'E', 'L', 'F', 2, 1},
+			want: `"\177ELF\2\1"`,
+		},
+		{
+			name: "long octal before digit",
+			data: []byte{0, '1'},
+			want: `"\0001"`,
+		},
+		{
+			name:   "truncated",
+			data:   []byte("hello world"),
+			maxLen: 5,
+			want:   `"hello"...`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, straceQuote(test.data, test.maxLen))
+		})
+	}
+}
+
+func Test_StraceOutput(t *testing.T) {
+	tests := []struct {
+		name  string
+		print func(p *Printer)
+		want  string
+	}{
+		{
+			name: "syscall",
+			print: func(p *Printer) {
+				call := openatCall(t)
+				p.PrintSyscallEnter(call)
+				p.PrintSyscallExit(call)
+			},
+			want: `openat(AT_FDCWD, "/etc/passwd", O_RDONLY|O_CLOEXEC) = 3
+`,
+		},
+		{
+			name: "return value is aligned",
+			print: func(p *Printer) {
+				call := tracertest.Syscall{
+					Name:    "getpid",
+					Process: tracer.Process{Pid: 100},
+					Return:  tracertest.Arg{Type: tracer.ArgTypeInt, Raw: 100},
+				}.Build(t)
+				p.PrintSyscallEnter(call)
+				p.PrintSyscallExit(call)
+			},
+			want: `getpid()                                = 100
+`,
+		},
+		{
+			name: "error",
+			print: func(p *Printer) {
+				call := mmapCall(t)
+				p.PrintSyscallEnter(call)
+				p.PrintSyscallExit(call)
+			},
+			want: `mmap(NULL, 4096, PROT_READ|PROT_WRITE, MAP_PRIVATE|MAP_ANONYMOUS, -1, 0) = -1 ENOMEM (Cannot allocate memory)
+`,
+		},
+		{
+			name: "unfinished and resumed",
+			print: func(p *Printer) {
+				read := readCall(t)
+				p.PrintSyscallEnter(read)
+				open := openatCall(t)
+				p.PrintSyscallEnter(open)
+				p.PrintSyscallExit(open)
+				p.PrintSyscallExit(read)
+			},
+			want: `read(3, "root\377\n", 4096 <unfinished ...>
+[pid   100] openat(AT_FDCWD, "/etc/passwd", O_RDONLY|O_CLOEXEC) = 3
+[pid   101] <... read resumed>)         = 6
+`,
+		},
+		{
+			name: "unfinished and resumed with arguments written at exit",
+			print: func(p *Printer) {
+				exit := readCall(t)
+				enter := tracertest.Syscall{
+					Name:       "read",
+					Process:    exit.Process(),
+					Args:       []tracertest.Arg{{Name: "fd", Type: tracer.ArgTypeInt, Raw: 3}},
+					Entered:    exit.EnterTime(),
+					Running:    true,
+					Incomplete: true,
+				}.Build(t)
+				p.PrintSyscallEnter(enter)
+				open := openatCall(t)
+				p.PrintSyscallEnter(open)
+				p.PrintSyscallExit(open)
+				p.PrintSyscallExit(exit)
+			},
+			want: `read(3,  <unfinished ...>
+[pid   100] openat(AT_FDCWD, "/etc/passwd", O_RDONLY|O_CLOEXEC) = 3
+[pid   101] <... read resumed>"root\377\n", 4096) = 6
+`,
+		},
+		{
+			name: "exited",
+			print: func(p *Printer) {
+				p.PrintExit(&tracer.Exit{Pid: 100, Status: 3})
+			},
+			want: `+++ exited with 3 +++
+`,
+		},
+		{
+			name: "killed mid-syscall",
+			print: func(p *Printer) {
+				call := readCall(t)
+				p.PrintSyscallEnter(call)
+				p.PrintExit(&tracer.Exit{Pid: 101, Signal: 9})
+			},
+			want: `read(3, "root\377\n", 4096)             = ?
++++ killed by SIGKILL +++
+`,
+		},
+		{
+			name: "signal",
+			print: func(p *Printer) {
+				p.PrintSignal(100, segfault)
+			},
+			want: `--- SIGSEGV {si_signo=SIGSEGV, si_code=SEGV_MAPERR, si_pid=0, si_uid=0} ---
+`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buffer := bytes.NewBuffer(nil)
+			p := New(buffer)
+			require.NoError(t, p.SetFormat(FormatStrace))
+			test.print(p)
+			assert.Equal(t, test.want, buffer.String())
+		})
+	}
+}
@@ -7,25 +7,40 @@ import (
 )
 
 func (p *Printer) PrintSyscallEnter(syscall *tracer.Syscall) {
+	p.switchTo(syscall.Tid()).pid = syscall.Pid()
+	p.contextEnter(syscall)
 	p.printSyscallEnter(syscall, false)
 }
 
 func (p *Printer) printSyscallEnter(syscall *tracer.Syscall, overrideFilter bool) {
 
+	state := p.current
+
 	if !overrideFilter {
 		if p.filter != nil {
 			if !p.filter.Match(syscall, false) {
-				p.lastEntryMatchedFilter = false
+				state.lastEntryMatchedFilter = false
 				return
 			}
 		}
-		p.lastEntryMatchedFilter = true
+		state.lastEntryMatchedFilter = true
+		p.contextMatch(syscall)
 	}
 
-	p.PrefixEvent()
+	state.name = syscall.Name()
+	state.unfinished = false
+
+	if p.encoder != nil {
+		p.encoder.syscallEnter(syscall, p.dimmed)
+		state.inSyscall = true
+		return
+	}
 
-	p.colourIndex = 0
-	p.argProgress = 0
+	// use the time of entry, as the entry may not be printed until the syscall exits
+	p.prefixLine(syscall.EnterTime())
+
+	state.colourIndex = 0
+	state.argProgress = 0
 
 	if p.showNumbers {
 		p.PrintDim("%4s", fmt.Sprintf("%d ", syscall.Number()))
@@ -37,21 +52,46 @@ func (p *Printer) printSyscallEnter(syscall *tracer.Syscall, overrideFilter bool
 		p.PrintColour(ColourDefault, syscall.Name())
 	}
 	p.printRemainingArgs(syscall, false)
-	p.inSyscall = true
+	state.argsOpen = !syscall.Complete()
+	state.inSyscall = true
+	p.open = syscall.Tid()
 }
 
 func (p *Printer) PrintSyscallExit(syscall *tracer.Syscall) {
 
+	state := p.switchTo(syscall.Tid())
+	state.pid = syscall.Pid()
+
 	if p.filter != nil {
-		if !p.lastEntryMatchedFilter && !p.filter.Match(syscall, true) {
+		if !state.lastEntryMatchedFilter && !p.filter.Match(syscall, true) {
+			p.contextMiss(syscall)
 			return
 		}
 	}
 
-	if !p.lastEntryMatchedFilter {
+	if !state.lastEntryMatchedFilter {
+		p.contextMatch(syscall)
 		p.printSyscallEnter(syscall, true)
 	}
 
+	p.printSyscallExit(syscall)
+}
+
+func (p *Printer) printSyscallExit(syscall *tracer.Syscall) {
+	state := p.current
+	if p.encoder != nil {
+		p.encoder.syscallExit(syscall, p.dimmed)
+		state.inSyscall = false
+		return
+	}
+	if state.unfinished {
+		p.prefixLine(syscall.ExitTime())
+		p.PrintDim("<... %s resumed>", syscall.Name())
+		if state.argProgress < len(syscall.Args()) {
+			p.Print(" ")
+		}
+		state.unfinished = false
+	}
 	p.printRemainingArgs(syscall, true)
 	p.PrintDim(" = ")
 	ret := syscall.Return()
@@ -60,35 +100,37 @@ func (p *Printer) PrintSyscallExit(syscall *tracer.Syscall) {
 	if p.extraNewLine {
 		p.Print("\n")
 	}
-	p.inSyscall = false
+	state.inSyscall = false
+	p.open = 0
 }
 
 func (p *Printer) printRemainingArgs(syscall *tracer.Syscall, exit bool) {
+	state := p.current
 	if !exit {
 		p.PrintDim("(")
 	}
 	var remaining []tracer.Arg
-	if p.argProgress < len(syscall.Args()) {
-		remaining = syscall.Args()[p.argProgress:]
+	if state.argProgress < len(syscall.Args()) {
+		remaining = syscall.Args()[state.argProgress:]
 		for i, arg := range remaining {
 			if !arg.Known() {
 				break
 			}
-			if p.argProgress == 0 && p.multiline {
+			if state.argProgress == 0 && p.multiline {
 				p.Print("\n")
 			}
 			p.PrintArg(arg, exit)
 			if i < len(remaining)-1 || !syscall.Complete() {
 				p.PrintDim(", ")
 			}
-			p.argProgress++
+			state.argProgress++
 			if p.multiline {
 				p.Print("\n")
 			}
 		}
 	}
 
-	if ((exit && len(remaining) > 0) || (!exit && p.argProgress == len(syscall.Args()))) && syscall.Complete() {
+	if ((exit && len(remaining) > 0) || (!exit && state.argProgress == len(syscall.Args()))) && syscall.Complete() {
 		p.PrintDim(")")
 	}
 
@@ -0,0 +1,88 @@
+package printer
+
+import (
+	"fmt"
+	"time"
+)
+
+// traceeState holds the printing state of a single thread, so that output from several threads can be interleaved
+type traceeState struct {
+	pid                    int
+	colourIndex            int
+	argProgress            int
+	inSyscall              bool
+	name                   string // name of the syscall in progress
+	unfinished             bool   // the line for the syscall in progress was interrupted by another event
+	argsOpen               bool   // the line ends part way through the argument list
+	lastEntryMatchedFilter bool
+}
+
+// pidColours are used for the pid column, so that output from different processes can be told apart
+var pidColours = []Colour{
+	ColourCyan,
+	ColourMagenta,
+	ColourYellow,
+	ColourGreen,
+	ColourBlue,
+}
+
+// switchTo makes the given thread the current one, interrupting the partially printed line of any other thread
+func (p *Printer) switchTo(tid int) *traceeState {
+	if p.open != 0 && p.open != tid {
+		p.interrupt()
+	}
+	if p.tracees == nil {
+		p.tracees = make(map[int]*traceeState)
+	}
+	state, ok := p.tracees[tid]
+	if !ok {
+		state = &traceeState{pid: tid}
+		p.tracees[tid] = state
+		if len(p.tracees) > 1 {
+			p.showPids = true
+		}
+	}
+	p.current = state
+	p.currentTid = tid
+	return state
+}
+
+// interrupt ends a partially printed syscall line, so it can be resumed once the syscall exits
+func (p *Printer) interrupt() {
+	if p.open == 0 {
+		return
+	}
+	separator := " "
+	if state, ok := p.tracees[p.open]; ok {
+		state.unfinished = true
+		if state.argsOpen {
+			separator = ""
+		}
+	}
+	p.PrintDim("%s<unfinished ...>\n", separator)
+	p.open = 0
+}
+
+// prefixLine prints the pid column (once more than one thread has been seen) and any timestamps
+func (p *Printer) prefixLine(at time.Time) {
+	if p.showPids && p.current != nil {
+		p.printPidColumn(p.current.pid, p.currentTid)
+	}
+	p.prefixEventAt(at)
+}
+
+func (p *Printer) printPidColumn(pid int, tid int) {
+	label := fmt.Sprintf("%d", pid)
+	if tid != pid {
+		label = fmt.Sprintf("%d/%d", pid, tid)
+	}
+	if p.pidColours == nil {
+		p.pidColours = make(map[int]Colour)
+	}
+	colour, ok := p.pidColours[pid]
+	if !ok {
+		colour = pidColours[len(p.pidColours)%len(pidColours)]
+		p.pidColours[pid] = colour
+	}
+	p.PrintColour(colour, "%-11s ", label)
+}
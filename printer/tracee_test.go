@@ -0,0 +1,53 @@
+package printer
+
+import (
+	"bytes"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/liamg/grace/tracer"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PidColumn(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	p := New(buffer)
+	p.SetUseColours(false)
+
+	p.PrintAttach(100)
+	p.PrintSignal(100, &tracer.SigInfo{Signo: int32(syscall.SIGCHLD), Code: 1, Pid: 101})
+	// the pid column is only shown once there is more than one tracee, and a thread which exits before making a
+	// syscall is still shown as a thread of its process
+	p.PrintExit(&tracer.Exit{Pid: 102, Tgid: 100, Time: time.Now()})
+	p.PrintExit(&tracer.Exit{Pid: 100, Tgid: 100, Signal: syscall.SIGSEGV, Time: time.Now()})
+
+	assert.Equal(t, `Attached to process 100
+--> SIGNAL: SIGCHLD (code=CLD_EXITED, pid=101, uid=0) <--
+100/102     Thread exited with status 0
+100         Process killed by SIGSEGV
+`, buffer.String())
+}
+
+func Test_UnfinishedAndResumed(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	p := New(buffer)
+	p.SetUseColours(false)
+
+	// a partially printed read, which is interrupted by events from another tracee
+	state := p.switchTo(100)
+	state.inSyscall = true
+	state.name = "read"
+	state.argsOpen = true
+	p.open = 100
+	p.Print("read(fd: 0, ")
+
+	p.PrintExit(&tracer.Exit{Pid: 101, Status: 3, Time: time.Now()})
+	p.PrintExit(&tracer.Exit{Pid: 100, Signal: syscall.SIGKILL, Time: time.Now()})
+
+	assert.Equal(t, `read(fd: 0, <unfinished ...>
+101         Process exited with status 3
+100         <... read resumed> = ?
+100         Process killed by SIGKILL
+`, buffer.String())
+}
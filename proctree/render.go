@@ -0,0 +1,164 @@
+package proctree
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/liamg/grace/printer"
+	"github.com/liamg/grace/tracer/annotation"
+)
+
+const indentSize = 2
+
+// Print writes the final report: every process which was seen, as an indented tree
+func (t *Tree) Print() {
+	if t.live && len(t.roots) > 0 {
+		t.p.Print("\n")
+	}
+	roots := sortedByStart(t.roots)
+	for _, root := range roots {
+		t.printNode(root, "", "")
+	}
+}
+
+func (t *Tree) printNode(node *Node, prefix string, childPrefix string) {
+	if prefix != "" {
+		t.p.PrintDim("%s", prefix)
+	}
+	t.p.PrintColour(printer.ColourYellow, "%d", node.Pid)
+	t.p.Print(" %s", t.formatArgs(node))
+	if node.Cwd != "" {
+		t.p.PrintDim(" (%s)", node.Cwd)
+	}
+	t.p.Print("  ")
+	t.printStatus(node)
+	t.p.Print("  ")
+	t.printCounts(node)
+	t.p.Print("\n")
+
+	children := sortedByStart(node.Children)
+	for i, child := range children {
+		if i == len(children)-1 {
+			t.printNode(child, childPrefix+"└─ ", childPrefix+"   ")
+		} else {
+			t.printNode(child, childPrefix+"├─ ", childPrefix+"│  ")
+		}
+	}
+}
+
+// printStatus prints how long a process ran for and how it exited
+func (t *Tree) printStatus(node *Node) {
+	switch {
+	case node.Running() && node.exiting:
+		t.p.PrintColour(printer.ColourBlue, "exiting")
+		t.printExitStatus(node)
+	case node.Running():
+		t.p.PrintColour(printer.ColourBlue, "still running")
+	default:
+		t.p.PrintColour(printer.ColourBlue, "%s", formatDuration(node.Duration()))
+		t.printExitStatus(node)
+	}
+}
+
+func (t *Tree) printExitStatus(node *Node) {
+	switch {
+	case node.Signal != 0:
+		t.p.PrintColour(printer.ColourRed, " killed by %s", annotation.SignalToString(int(node.Signal)))
+	case node.Status != 0:
+		t.p.PrintColour(printer.ColourRed, " exit %d", node.Status)
+	default:
+		t.p.PrintColour(printer.ColourGreen, " exit 0")
+	}
+}
+
+func (t *Tree) printCounts(node *Node) {
+	t.p.PrintDim("%d syscalls", node.Syscalls)
+	if node.Errors > 0 {
+		t.p.PrintDim(", ")
+		t.p.PrintColour(printer.ColourRed, "%d errors", node.Errors)
+	}
+}
+
+func (t *Tree) prefixLive(node *Node, at time.Time) {
+	t.p.PrintDim("%12s ", formatDuration(at.Sub(t.started)))
+	t.p.Print("%s", strings.Repeat(" ", node.depth()*indentSize))
+	t.p.PrintColour(printer.ColourYellow, "%d", node.Pid)
+}
+
+func (t *Tree) printStart(node *Node) {
+	t.prefixLive(node, node.Started)
+	if node.Parent != nil {
+		t.p.PrintColour(printer.ColourGreen, " started")
+		t.p.PrintDim(" by %d", node.Parent.Pid)
+	} else {
+		t.p.PrintColour(printer.ColourGreen, " traced")
+	}
+	t.p.Print(" %s", t.formatArgs(node))
+	if node.Cwd != "" {
+		t.p.PrintDim(" (%s)", node.Cwd)
+	}
+	t.p.Print("\n")
+}
+
+func (t *Tree) printExec(node *Node, at time.Time) {
+	t.prefixLive(node, at)
+	t.p.PrintColour(printer.ColourCyan, " exec")
+	t.p.Print(" %s", t.formatArgs(node))
+	if node.Cwd != "" {
+		t.p.PrintDim(" (%s)", node.Cwd)
+	}
+	t.p.Print("\n")
+}
+
+func (t *Tree) printExit(node *Node) {
+	t.prefixLive(node, node.Ended)
+	switch {
+	case node.Signal != 0:
+		t.p.PrintColour(printer.ColourRed, " killed by %s", annotation.SignalToString(int(node.Signal)))
+	case node.Status != 0:
+		t.p.PrintColour(printer.ColourRed, " exited with status %d", node.Status)
+	default:
+		t.p.PrintColour(printer.ColourGreen, " exited with status 0")
+	}
+	t.p.PrintDim(" after %s, ", formatDuration(node.Duration()))
+	t.printCounts(node)
+	t.p.Print("\n")
+}
+
+// formatArgs formats the command line of a process, quoting arguments where necessary
+func (t *Tree) formatArgs(node *Node) string {
+	args := node.Args
+	if len(args) == 0 && node.Exe != "" {
+		args = []string{node.Exe}
+	}
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\n\"'\\$`") {
+			arg = strconv.Quote(arg)
+		}
+		quoted = append(quoted, arg)
+	}
+	output := strings.Join(quoted, " ")
+	if t.maxArgsLen > 0 && len(output) > t.maxArgsLen {
+		output = output[:t.maxArgsLen] + "..."
+	}
+	return output
+}
+
+func formatDuration(d time.Duration) string {
+	if d >= time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(time.Microsecond).String()
+}
+
+func sortedByStart(nodes []*Node) []*Node {
+	sorted := make([]*Node, len(nodes))
+	copy(sorted, nodes)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Started.Before(sorted[j].Started)
+	})
+	return sorted
+}
@@ -0,0 +1,216 @@
+package proctree
+
+import (
+	"io"
+	"syscall"
+	"time"
+
+	"github.com/liamg/grace/printer"
+	"github.com/liamg/grace/tracer"
+	"golang.org/x/sys/unix"
+)
+
+// Node is a single process in the tree
+type Node struct {
+	Pid      int
+	Parent   *Node
+	Children []*Node
+	Args     []string // the command line of the process, as of its last exec
+	Exe      string
+	Cwd      string // working directory at the time of the last exec
+	Started  time.Time
+	Ended    time.Time // zero if the process was still running when tracing stopped
+	Status   int
+	Signal   syscall.Signal // the signal which killed the process, or 0
+	Syscalls int
+	Errors   int
+	exiting  bool // exit_group has been called, but the process has not been reaped yet
+}
+
+// Running returns true if the process had not exited when tracing stopped
+func (n *Node) Running() bool {
+	return n.Ended.IsZero()
+}
+
+// Duration returns the time between the process first being seen and exiting
+func (n *Node) Duration() time.Duration {
+	if n.Running() {
+		return 0
+	}
+	return n.Ended.Sub(n.Started)
+}
+
+// Tree builds a process hierarchy from the syscalls and exits of traced processes
+type Tree struct {
+	roots      []*Node
+	nodes      map[int]*Node // processes which are still running, by pid
+	live       bool
+	started    time.Time
+	maxArgsLen int
+	p          *printer.Printer
+}
+
+// New creates a tree which writes live events (if enabled) and the final report to w
+func New(w io.Writer) *Tree {
+	p := printer.New(w)
+	return &Tree{
+		nodes: make(map[int]*Node),
+		p:     p,
+	}
+}
+
+func (t *Tree) SetUseColours(useColours bool) {
+	t.p.SetUseColours(useColours)
+}
+
+// SetLive enables printing of process creation, exec and exit as they happen
+func (t *Tree) SetLive(live bool) {
+	t.live = live
+}
+
+// SetStartTime sets the time which live event timestamps are calculated from
+func (t *Tree) SetStartTime(start time.Time) {
+	t.started = start
+}
+
+// SetMaxArgsLen sets the maximum length of the command line printed for each process (0 means no limit)
+func (t *Tree) SetMaxArgsLen(max int) {
+	t.maxArgsLen = max
+}
+
+// Roots returns the processes which have no traced parent
+func (t *Tree) Roots() []*Node {
+	return t.roots
+}
+
+func (t *Tree) HandleSyscallEnter(call *tracer.Syscall) {
+	if call.Number() != unix.SYS_EXIT_GROUP {
+		return
+	}
+	node := t.node(call.Process(), call.EnterTime())
+	node.exiting = true
+	if args := call.Args(); len(args) > 0 {
+		node.Status = args[0].Int()
+	}
+	// exit_group never returns, so this is the last syscall the process makes
+	node.Syscalls++
+}
+
+func (t *Tree) HandleSyscallExit(call *tracer.Syscall) {
+	node := t.node(call.Process(), call.EnterTime())
+	node.Syscalls++
+	if call.Errno() != 0 {
+		node.Errors++
+	}
+	switch call.Number() {
+	case unix.SYS_CLONE, unix.SYS_CLONE3, unix.SYS_FORK, unix.SYS_VFORK:
+		if child := call.Return().Int(); child > 0 {
+			t.forked(node, child)
+		}
+	case unix.SYS_EXECVE, unix.SYS_EXECVEAT:
+		if call.Errno() == 0 {
+			t.exec(node, call.Process(), execArgs(call), call.ExitTime())
+		}
+	}
+}
+
+// HandleExit records the exit of a process. Exits of threads other than the main thread of a process are ignored.
+func (t *Tree) HandleExit(exit *tracer.Exit) {
+	node, ok := t.nodes[exit.Pid]
+	if !ok {
+		return
+	}
+	delete(t.nodes, exit.Pid)
+	node.Ended = exit.Time
+	node.Signal = exit.Signal
+	if exit.Signal == 0 {
+		node.Status = exit.Status
+	}
+	if t.live {
+		t.printExit(node)
+	}
+}
+
+// node returns the running process with the pid of the given process, adding it to the tree if it is new
+func (t *Tree) node(proc tracer.Process, at time.Time) *Node {
+	if node, ok := t.nodes[proc.Pid]; ok {
+		return node
+	}
+	node := &Node{
+		Pid:     proc.Pid,
+		Args:    proc.Args,
+		Exe:     proc.Exe,
+		Cwd:     proc.Cwd,
+		Started: at,
+	}
+	if t.started.IsZero() {
+		t.started = at
+	}
+	t.nodes[proc.Pid] = node
+	if parent, ok := t.nodes[proc.PPid]; ok && proc.PPid != proc.Pid {
+		t.adopt(parent, node)
+	} else {
+		t.roots = append(t.roots, node)
+	}
+	if t.live {
+		t.printStart(node)
+	}
+	return node
+}
+
+// forked links a child to its parent, in case the child was seen before its parent process could be identified
+func (t *Tree) forked(parent *Node, child int) {
+	node, ok := t.nodes[child]
+	if !ok || node == parent || node.Parent != nil {
+		return
+	}
+	for i, root := range t.roots {
+		if root == node {
+			t.roots = append(t.roots[:i], t.roots[i+1:]...)
+			break
+		}
+	}
+	t.adopt(parent, node)
+}
+
+func (t *Tree) adopt(parent *Node, child *Node) {
+	child.Parent = parent
+	parent.Children = append(parent.Children, child)
+}
+
+func (t *Tree) exec(node *Node, proc tracer.Process, argv []string, at time.Time) {
+	node.Args = proc.Args
+	if len(node.Args) == 0 {
+		node.Args = argv
+	}
+	node.Exe = proc.Exe
+	node.Cwd = proc.Cwd
+	if t.live {
+		t.printExec(node, at)
+	}
+}
+
+// execArgs returns the argv passed to execve/execveat
+func execArgs(call *tracer.Syscall) []string {
+	index := 1
+	if call.Number() == unix.SYS_EXECVEAT {
+		index = 2
+	}
+	args := call.Args()
+	if len(args) <= index {
+		return nil
+	}
+	var argv []string
+	for _, arg := range args[index].Array() {
+		argv = append(argv, string(arg.Data()))
+	}
+	return argv
+}
+
+func (n *Node) depth() int {
+	var depth int
+	for parent := n.Parent; parent != nil; parent = parent.Parent {
+		depth++
+	}
+	return depth
+}
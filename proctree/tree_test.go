@@ -0,0 +1,110 @@
+package proctree
+
+import (
+	"bytes"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/liamg/grace/tracer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_TreeHierarchy(t *testing.T) {
+	start := time.Now()
+	tree := New(&bytes.Buffer{})
+
+	root := tree.node(tracer.Process{Pid: 100, PPid: 1, Args: []string{"make"}, Cwd: "/src"}, start)
+	child := tree.node(tracer.Process{Pid: 101, PPid: 100, Args: []string{"make"}}, start.Add(time.Millisecond))
+
+	// a child which is seen before its parent's clone returns, and whose parent could not be read from /proc
+	orphan := tree.node(tracer.Process{Pid: 102}, start.Add(2*time.Millisecond))
+	require.Len(t, tree.Roots(), 2)
+	tree.forked(root, 102)
+	require.Len(t, tree.Roots(), 1)
+
+	// threads and the process itself should not be adopted
+	tree.forked(root, 100)
+	tree.forked(root, 103)
+
+	assert.Equal(t, []*Node{child, orphan}, root.Children)
+	assert.Equal(t, root, orphan.Parent)
+	assert.Equal(t, 1, orphan.depth())
+
+	tree.exec(child, tracer.Process{Pid: 101, Cwd: "/src/lib"}, []string{"cc", "-c", "a.c"}, start)
+	assert.Equal(t, []string{"cc", "-c", "a.c"}, child.Args)
+	assert.Equal(t, "/src/lib", child.Cwd)
+
+	tree.HandleExit(&tracer.Exit{Pid: 101, Signal: syscall.SIGSEGV, Time: start.Add(time.Second)})
+	assert.Equal(t, syscall.SIGSEGV, child.Signal)
+	assert.Equal(t, time.Second-time.Millisecond, child.Duration())
+	assert.True(t, root.Running())
+
+	// once a process has exited, its pid can be reused by a new process
+	reused := tree.node(tracer.Process{Pid: 101, PPid: 100}, start.Add(2*time.Second))
+	assert.NotEqual(t, child, reused)
+	assert.Len(t, root.Children, 3)
+}
+
+func Test_TreeReport(t *testing.T) {
+	start := time.Now()
+	buffer := &bytes.Buffer{}
+	tree := New(buffer)
+	tree.SetUseColours(false)
+
+	root := tree.node(tracer.Process{Pid: 100, Args: []string{"sh", "-c", "cc a.c; cc b.c"}, Cwd: "/src"}, start)
+	root.Syscalls = 10
+	first := tree.node(tracer.Process{Pid: 101, PPid: 100, Args: []string{"cc", "a.c"}, Cwd: "/src"}, start)
+	first.Syscalls, first.Errors = 5, 2
+	tree.node(tracer.Process{Pid: 102, PPid: 101, Args: []string{"as"}, Cwd: "/src"}, start)
+	tree.node(tracer.Process{Pid: 103, PPid: 100, Args: []string{"cc", "b.c"}, Cwd: "/src"}, start.Add(time.Millisecond))
+	tree.HandleExit(&tracer.Exit{Pid: 101, Status: 1, Time: start.Add(1500 * time.Microsecond)})
+	tree.HandleExit(&tracer.Exit{Pid: 103, Signal: syscall.SIGKILL, Time: start.Add(2 * time.Second)})
+
+	tree.Print()
+	assert.Equal(t, `100 sh -c "cc a.c; cc b.c" (/src)  still running  10 syscalls
+├─ 101 cc a.c (/src)  1.5ms exit 1  5 syscalls, 2 errors
+│  └─ 102 as (/src)  still running  0 syscalls
+└─ 103 cc b.c (/src)  1.999s killed by SIGKILL  0 syscalls
+`, buffer.String())
+}
+
+func Test_FormatArgs(t *testing.T) {
+	tests := []struct {
+		name   string
+		node   Node
+		max    int
+		expect string
+	}{
+		{
+			name:   "plain arguments",
+			node:   Node{Args: []string{"ls", "-la", "/tmp"}},
+			expect: "ls -la /tmp",
+		},
+		{
+			name:   "arguments which need quoting",
+			node:   Node{Args: []string{"sh", "-c", "echo $HOME", ""}},
+			expect: `sh -c "echo $HOME" ""`,
+		},
+		{
+			name:   "falls back to the executable",
+			node:   Node{Exe: "/usr/bin/true"},
+			expect: "/usr/bin/true",
+		},
+		{
+			name:   "truncated",
+			node:   Node{Args: []string{"cc", "-O2", "-c", "main.c"}},
+			max:    8,
+			expect: "cc -O2 -...",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tree := New(&bytes.Buffer{})
+			tree.SetMaxArgsLen(test.max)
+			assert.Equal(t, test.expect, tree.formatArgs(&test.node))
+		})
+	}
+}
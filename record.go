@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/liamg/grace/tracer"
+	"github.com/spf13/cobra"
+)
+
+var recordCmd = &cobra.Command{
+	Use:     "record -o [file] [flags] [command [args]]",
+	Example: `grace record -o trace.grace -- cat /etc/passwd`,
+	Short:   "Record a trace to a file, so it can be replayed later with different options",
+	Args:    cobra.ArbitraryArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+
+		if len(args) == 0 && flagPID == 0 {
+			return cmd.Help()
+		}
+		if flagOutputFile == "" {
+			return fmt.Errorf("an output file must be specified with --output-file/-o")
+		}
+
+		t, err := createTracer(args, os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		f, err := os.Create(flagOutputFile)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = f.Close() }()
+
+		command := args
+		if flagPID > 0 {
+			command = tracer.CommandLine(flagPID)
+		}
+		if err := t.Record(f, command); err != nil {
+			return fmt.Errorf("failed to start recording: %w", err)
+		}
+
+		startErr := t.Start()
+		count, err := t.StopRecording()
+		if err != nil {
+			return fmt.Errorf("failed to write recording: %w", err)
+		}
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Recorded %d events to %s\n", count, flagOutputFile)
+		return startErr
+	},
+}
+
+var replayCmd = &cobra.Command{
+	Use:     "replay [file] [flags]",
+	Example: `grace replay -f "name=openat" trace.grace`,
+	Short:   "Replay a trace which was recorded with 'grace record', using any output and filter options",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer func() { _ = f.Close() }()
+
+		t, header, err := tracer.Replay(f)
+		if err != nil {
+			return err
+		}
+
+		return run(cmd, t, header.Started)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(recordCmd)
+	rootCmd.AddCommand(replayCmd)
+}
@@ -3,119 +3,103 @@ package main
 import (
 	"fmt"
 	"io"
-	"sort"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
 	"time"
 
-	"github.com/aquasecurity/table"
+	"github.com/liamg/grace/summary"
 	"github.com/liamg/grace/tracer"
+	"golang.org/x/term"
 )
 
-func configureSummary(t *tracer.Tracer, w io.Writer, sortKey string) {
-
-	tracker := &tracker{
-		counts:    make(map[string]int),
-		errors:    make(map[string]int),
-		durations: make(map[string]time.Duration),
-		starts:    make(map[string]time.Time),
-	}
-
-	t.SetSyscallEnterHandler(tracker.recordEnter)
-	t.SetSyscallExitHandler(tracker.recordExit)
-	t.SetDetachHandler(func(i int) {
-		tracker.print(w, sortKey)
-	})
-}
-
-type tracker struct {
-	counts    map[string]int
-	errors    map[string]int
-	durations map[string]time.Duration
-	starts    map[string]time.Time
-}
-
-func (t *tracker) recordEnter(s *tracer.Syscall) {
-	t.starts[s.Name()] = time.Now()
-}
-
-func (t *tracker) recordExit(s *tracer.Syscall) {
-	stop := time.Now()
-	if start, ok := t.starts[s.Name()]; ok {
-		t.durations[s.Name()] += stop.Sub(start)
-		delete(t.starts, s.Name())
+// configureSummary sets up a summary which is printed when the tracer detaches, or when grace receives SIGUSR1. If the
+// trace is also printed, snapshots are printed by the tracer after the next syscall, so they can't split its output.
+func configureSummary(t *tracer.Tracer, w io.Writer) error {
+	s := summary.New(w)
+	if err := s.SetSortKey(flagSortKey); err != nil {
+		return err
 	}
-	if s.Return().Int() < 0 {
-		t.errors[s.Name()]++
+	if err := s.SetFormat(summary.Format(flagSummaryFormat)); err != nil {
+		return err
 	}
-	t.counts[s.Name()]++
-}
-
-func (t *tracker) print(w io.Writer, sortKey string) {
-
-	tab := table.New(w)
-	tab.SetRowLines(false)
-	tab.AddHeaders("time %", "seconds", "usecs/call", "count", "errors", "syscall")
-	tab.SetAlignment(table.AlignRight, table.AlignRight, table.AlignRight, table.AlignRight, table.AlignRight, table.AlignLeft)
-	tab.SetLineStyle(table.StyleBlue)
-
-	var total time.Duration
-	for _, duration := range t.durations {
-		total += duration
+	if err := s.SetShowErrors(flagSummaryErrors || flagSummaryErrorsBy != "", summary.ErrorTarget(flagSummaryErrorsBy)); err != nil {
+		return err
 	}
-
-	type row struct {
-		sortKey int
-		cols    []string
-		name    string
+	if err := s.SetGroupBy(summary.GroupBy(flagSummaryBy)); err != nil {
+		return err
 	}
+	s.SetShowHistograms(flagSummaryHistogram)
 
-	var rows []row
-
-	for name, count := range t.counts {
-
-		duration := t.durations[name]
-
-		percent := float64(duration) * 100 / float64(total)
-
-		var key int
-		switch sortKey {
-		case "count":
-			key = count
-		case "time":
-			key = int(percent * 100000)
-		case "seconds":
-			key = int(duration)
-		case "errors":
-			key = t.errors[name]
-
+	var terminal *os.File
+	if flagSummaryLive > 0 {
+		if flagSummaryFormat != string(summary.FormatTable) {
+			return fmt.Errorf("--summary-live can only be used with the table summary format")
+		}
+		if flagWithTrace {
+			return fmt.Errorf("--summary-live cannot be used with --with-trace, as the summary would be drawn over the trace")
+		}
+		if f, ok := w.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+			terminal = f
+			s.SetRedraw(true)
 		}
-
-		rows = append(rows, row{
-			name:    name,
-			sortKey: key,
-			cols: []string{
-				fmt.Sprintf("%.2f", percent),
-				fmt.Sprintf("%.6f", duration.Seconds()),
-				fmt.Sprintf("%d", duration.Microseconds()/int64(count)),
-				fmt.Sprintf("%d", count),
-				fmt.Sprintf("%d", t.errors[name]),
-				name,
-			},
-		})
 	}
 
-	if sortKey == "" {
-		sort.Slice(rows, func(i, j int) bool {
-			return rows[i].name < rows[j].name
-		})
-	} else {
-		sort.Slice(rows, func(i, j int) bool {
-			return rows[i].sortKey > rows[j].sortKey
-		})
-	}
+	var snapshotPending int32
+	snapshots := make(chan os.Signal, 1)
+	signal.Notify(snapshots, syscall.SIGUSR1)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		var ticks <-chan time.Time
+		if flagSummaryLive > 0 {
+			ticker := time.NewTicker(time.Duration(flagSummaryLive) * time.Second)
+			defer ticker.Stop()
+			ticks = ticker.C
+		}
+		for {
+			select {
+			case <-done:
+				return
+			case <-snapshots:
+				if flagWithTrace {
+					atomic.StoreInt32(&snapshotPending, 1)
+				} else {
+					s.Print()
+				}
+			case <-ticks:
+				var maxRows int
+				if terminal != nil {
+					if _, height, err := term.GetSize(int(terminal.Fd())); err == nil {
+						// leave room for the heading and the borders of the table
+						maxRows = height - 6
+					}
+				}
+				s.PrintLive(maxRows)
+			}
+		}
+	}()
 
-	for _, row := range rows {
-		tab.AddRow(row.cols...)
-	}
+	t.AddSyscallExitHandler(func(call *tracer.Syscall) {
+		s.HandleSyscallExit(call)
+		// this handler runs after the printer's, which has just finished the line for this syscall
+		if atomic.CompareAndSwapInt32(&snapshotPending, 1, 0) {
+			s.Print()
+		}
+	})
+	t.AddDetachHandler(func(int) {
+		signal.Stop(snapshots)
+		close(done)
+		<-stopped
+		s.Print()
+	})
+	return nil
+}
 
-	tab.Render()
+// summaryEnabled returns true if any of the flags which configure the summary were given
+func summaryEnabled() bool {
+	return flagSummarise || flagSummaryHistogram || flagSummaryErrors || flagSummaryErrorsBy != "" || flagSummaryBy != "" ||
+		flagSummaryLive > 0 || flagSummaryFormat != string(summary.FormatTable)
 }
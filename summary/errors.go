@@ -0,0 +1,192 @@
+package summary
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/aquasecurity/table"
+	"github.com/liamg/grace/tracer"
+	"github.com/liamg/grace/tracer/annotation"
+)
+
+// ErrorTarget is what failed calls are grouped by in the error breakdown, in addition to the syscall and error
+type ErrorTarget string
+
+const (
+	ErrorTargetNone ErrorTarget = ""
+	ErrorTargetPath ErrorTarget = "path"
+	ErrorTargetFd   ErrorTarget = "fd"
+)
+
+// failure identifies a group of failed calls in the error breakdown
+type failure struct {
+	syscall string
+	errno   int
+	target  string
+}
+
+// ErrorCount is a row of the error breakdown: the number of calls to a syscall which failed with a particular error
+type ErrorCount struct {
+	Syscall     string
+	Errno       string
+	Description string
+	Target      string // the path or fd the calls were made with, if the breakdown is grouped by one
+	Count       int
+}
+
+// jsonError is a row of the error breakdown in machine-readable output
+type jsonError struct {
+	Syscall     string `json:"syscall"`
+	Errno       string `json:"errno"`
+	Description string `json:"description,omitempty"`
+	Path        string `json:"path,omitempty"`
+	Fd          string `json:"fd,omitempty"`
+	Count       int    `json:"count"`
+}
+
+// SetShowErrors enables a breakdown of the errors returned by each syscall beneath the table. If a target is given,
+// failures are also grouped by the path or file descriptor the syscall was made with.
+func (s *Summary) SetShowErrors(show bool, by ErrorTarget) error {
+	switch by {
+	case ErrorTargetNone, ErrorTargetPath, ErrorTargetFd:
+	default:
+		return fmt.Errorf("invalid error grouping '%s': must be one of %s, %s", by, ErrorTargetPath, ErrorTargetFd)
+	}
+	if show && s.format == FormatCSV {
+		return errErrorsCSV
+	}
+	s.showErrors = show
+	s.errorsBy = by
+	return nil
+}
+
+func errnoName(errno int) string {
+	if errno == 0 {
+		return "unknown"
+	}
+	return annotation.ErrNoToString(errno)
+}
+
+func errnoDescription(errno int) string {
+	if errno == 0 {
+		return ""
+	}
+	return syscall.Errno(errno).Error()
+}
+
+// errorTarget returns the path or file descriptor a failed call is grouped by in the error breakdown
+func (s *Summary) errorTarget(call *tracer.Syscall) string {
+	switch s.errorsBy {
+	case ErrorTargetPath:
+		if paths := call.Paths(); len(paths) > 0 {
+			return paths[0]
+		}
+	case ErrorTargetFd:
+		for _, arg := range call.Args() {
+			if arg.Type() != tracer.ArgTypeInt || !strings.Contains(arg.Name(), "fd") {
+				continue
+			}
+			if annotation := arg.Annotation(); annotation != "" {
+				return fmt.Sprintf("%d -> %s", arg.Int(), annotation)
+			}
+			return fmt.Sprintf("%d", arg.Int())
+		}
+	}
+	return ""
+}
+
+// ErrorCounts returns the error breakdown. Syscalls are in the same order as the summary table, and the errors for
+// each syscall are ordered from most to least frequent.
+func (s *Summary) ErrorCounts() []ErrorCount {
+	order := make(map[string]int)
+	for i, stats := range s.sorted() {
+		order[stats.Name] = i
+	}
+	var counts []ErrorCount
+	for f, count := range s.failures {
+		counts = append(counts, ErrorCount{
+			Syscall:     f.syscall,
+			Errno:       errnoName(f.errno),
+			Description: errnoDescription(f.errno),
+			Target:      f.target,
+			Count:       count,
+		})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		a, b := counts[i], counts[j]
+		if a.Syscall != b.Syscall {
+			return order[a.Syscall] < order[b.Syscall]
+		}
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Errno != b.Errno {
+			return a.Errno < b.Errno
+		}
+		return a.Target < b.Target
+	})
+	return counts
+}
+
+// jsonErrors returns the error breakdown for machine-readable output, naming the target after what it is
+func (s *Summary) jsonErrors() []jsonError {
+	var rows []jsonError
+	for _, count := range s.ErrorCounts() {
+		row := jsonError{
+			Syscall:     count.Syscall,
+			Errno:       count.Errno,
+			Description: count.Description,
+			Count:       count.Count,
+		}
+		switch s.errorsBy {
+		case ErrorTargetPath:
+			row.Path = count.Target
+		case ErrorTargetFd:
+			row.Fd = count.Target
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func (s *Summary) printErrors() {
+	counts := s.ErrorCounts()
+	if len(counts) == 0 {
+		return
+	}
+
+	_, _ = fmt.Fprintln(s.w)
+
+	headers := []string{"count", "% of calls", "syscall", "error"}
+	alignment := []table.Alignment{table.AlignRight, table.AlignRight, table.AlignLeft, table.AlignLeft}
+	if s.errorsBy != ErrorTargetNone {
+		headers = append(headers, string(s.errorsBy))
+		alignment = append(alignment, table.AlignLeft)
+	}
+	headers = append(headers, "description")
+	alignment = append(alignment, table.AlignLeft)
+
+	tab := table.New(s.w)
+	tab.SetRowLines(false)
+	tab.AddHeaders(headers...)
+	tab.SetAlignment(alignment...)
+	tab.SetLineStyle(table.StyleBlue)
+
+	for _, count := range counts {
+		row := []string{
+			fmt.Sprintf("%d", count.Count),
+			fmt.Sprintf("%.2f", float64(count.Count)*100/float64(s.syscalls[count.Syscall].Count)),
+			count.Syscall,
+			count.Errno,
+		}
+		if s.errorsBy != ErrorTargetNone {
+			row = append(row, count.Target)
+		}
+		row = append(row, count.Description)
+		tab.AddRow(row...)
+	}
+
+	tab.Render()
+}
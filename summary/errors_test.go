@@ -0,0 +1,84 @@
+package summary
+
+import (
+	"bytes"
+	"encoding/json"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fail records a failed call in the same way as HandleSyscallExit
+func fail(s *Summary, name string, errno syscall.Errno, target string) {
+	s.failures[failure{syscall: name, errno: int(errno), target: target}]++
+	s.record(name, time.Microsecond, errnoName(int(errno)))
+}
+
+func Test_ErrorCounts(t *testing.T) {
+	s := New(&bytes.Buffer{})
+	require.NoError(t, s.SetShowErrors(true, ErrorTargetPath))
+	require.NoError(t, s.SetSortKey("errors"))
+
+	for i := 0; i < 3; i++ {
+		fail(s, "openat", syscall.ENOENT, "/lib/libc.so.6")
+	}
+	fail(s, "openat", syscall.EACCES, "/etc/shadow")
+	fail(s, "openat", syscall.ENOENT, "/etc/ld.so.preload")
+	s.record("openat", time.Microsecond, "")
+	fail(s, "connect", syscall.ECONNREFUSED, "")
+	s.record("read", time.Microsecond, "")
+
+	assert.Equal(t, map[string]int{"ENOENT": 4, "EACCES": 1}, s.syscalls["openat"].Errnos)
+	assert.Equal(t, []ErrorCount{
+		{Syscall: "openat", Errno: "ENOENT", Description: "no such file or directory", Target: "/lib/libc.so.6", Count: 3},
+		{Syscall: "openat", Errno: "EACCES", Description: "permission denied", Target: "/etc/shadow", Count: 1},
+		{Syscall: "openat", Errno: "ENOENT", Description: "no such file or directory", Target: "/etc/ld.so.preload", Count: 1},
+		{Syscall: "connect", Errno: "ECONNREFUSED", Description: "connection refused", Count: 1},
+	}, s.ErrorCounts())
+
+	assert.Error(t, s.SetShowErrors(true, "inode"))
+}
+
+func Test_ErrorsJSON(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	s := New(buffer)
+	require.NoError(t, s.SetFormat(FormatJSON))
+	require.NoError(t, s.SetShowErrors(true, ErrorTargetFd))
+	fail(s, "read", syscall.EBADF, "9")
+	s.Print()
+
+	var output struct {
+		Syscalls []struct {
+			Syscall     string         `json:"syscall"`
+			Errors      int            `json:"errors"`
+			TimePercent float64        `json:"time_percent"`
+			TotalNs     int64          `json:"total_ns"`
+			Errnos      map[string]int `json:"errnos"`
+		} `json:"syscalls"`
+		Errors []map[string]interface{} `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &output))
+	require.Len(t, output.Syscalls, 1)
+	assert.Equal(t, "read", output.Syscalls[0].Syscall)
+	assert.Equal(t, 1, output.Syscalls[0].Errors)
+	assert.Equal(t, float64(100), output.Syscalls[0].TimePercent)
+	assert.Equal(t, int64(time.Microsecond), output.Syscalls[0].TotalNs)
+	assert.Equal(t, map[string]int{"EBADF": 1}, output.Syscalls[0].Errnos)
+	assert.Equal(t, []map[string]interface{}{
+		{"syscall": "read", "errno": "EBADF", "description": "bad file descriptor", "fd": "9", "count": float64(1)},
+	}, output.Errors)
+
+	assert.Error(t, s.SetFormat("xml"))
+}
+
+func Test_JSONErrorsNameTheTarget(t *testing.T) {
+	s := New(&bytes.Buffer{})
+	require.NoError(t, s.SetShowErrors(true, ErrorTargetFd))
+	fail(s, "read", syscall.EBADF, "7")
+	assert.Equal(t, []jsonError{
+		{Syscall: "read", Errno: "EBADF", Description: "bad file descriptor", Fd: "7", Count: 1},
+	}, s.jsonErrors())
+}
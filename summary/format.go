@@ -0,0 +1,124 @@
+package summary
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Format is the format a summary is printed in
+type Format string
+
+const (
+	FormatTable Format = "table"
+	FormatJSON  Format = "json"
+	FormatCSV   Format = "csv"
+)
+
+var errErrorsCSV = errors.New("the error breakdown cannot be written as csv, use the json format instead")
+
+// SetFormat sets the format the summary is printed in. Durations are written in nanoseconds in the json and csv
+// formats, so they can be processed and compared without parsing.
+func (s *Summary) SetFormat(format Format) error {
+	switch format {
+	case FormatTable, FormatJSON, FormatCSV:
+	default:
+		return fmt.Errorf("invalid summary format '%s': must be one of %s, %s, %s", format, FormatTable, FormatJSON, FormatCSV)
+	}
+	if format == FormatCSV && s.showErrors {
+		return errErrorsCSV
+	}
+	s.format = format
+	return nil
+}
+
+func totalOf(all []*Stats) time.Duration {
+	var total time.Duration
+	for _, stats := range all {
+		total += stats.Total
+	}
+	return total
+}
+
+func percentOf(d time.Duration, total time.Duration) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(d) * 100 / float64(total)
+}
+
+// printCSV writes a row for each syscall. If the summary is grouped by process or thread, the rows for each of them
+// come first, followed by the combined rows which have no pid.
+func (s *Summary) printCSV() {
+	w := csv.NewWriter(s.w)
+	defer w.Flush()
+
+	header := []string{"syscall", "count", "errors", "time_percent", "total_ns", "min_ns", "avg_ns", "p50_ns", "p95_ns", "p99_ns", "max_ns", "errnos"}
+	if s.groupBy != GroupByNone {
+		header = append([]string{"pid", "tid", "comm"}, header...)
+	}
+	_ = w.Write(header)
+
+	if s.groupBy != GroupByNone {
+		for _, task := range s.Tasks() {
+			tid := ""
+			if task.Tid != 0 {
+				tid = fmt.Sprintf("%d", task.Tid)
+			}
+			for _, row := range csvRows(task.Syscalls.sorted(s.sortKey)) {
+				_ = w.Write(append([]string{fmt.Sprintf("%d", task.Pid), tid, task.Comm}, row...))
+			}
+		}
+	}
+
+	for _, row := range csvRows(s.sorted()) {
+		if s.groupBy != GroupByNone {
+			row = append([]string{"", "", ""}, row...)
+		}
+		_ = w.Write(row)
+	}
+}
+
+func csvRows(all []*Stats) [][]string {
+	total := totalOf(all)
+	var rows [][]string
+	for _, stats := range all {
+		rows = append(rows, []string{
+			stats.Name,
+			fmt.Sprintf("%d", stats.Count),
+			fmt.Sprintf("%d", stats.Errors),
+			fmt.Sprintf("%.2f", percentOf(stats.Total, total)),
+			fmt.Sprintf("%d", stats.Total.Nanoseconds()),
+			fmt.Sprintf("%d", stats.Min.Nanoseconds()),
+			fmt.Sprintf("%d", stats.Avg().Nanoseconds()),
+			fmt.Sprintf("%d", stats.Percentile(50).Nanoseconds()),
+			fmt.Sprintf("%d", stats.Percentile(95).Nanoseconds()),
+			fmt.Sprintf("%d", stats.Percentile(99).Nanoseconds()),
+			fmt.Sprintf("%d", stats.Max.Nanoseconds()),
+			formatErrnos(stats.Errnos),
+		})
+	}
+	return rows
+}
+
+// formatErrnos formats error counts as a single field e.g. ENOENT=12;EACCES=1
+func formatErrnos(errnos map[string]int) string {
+	names := make([]string, 0, len(errnos))
+	for name := range errnos {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if errnos[names[i]] != errnos[names[j]] {
+			return errnos[names[i]] > errnos[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s=%d", name, errnos[name])
+	}
+	return strings.Join(parts, ";")
+}
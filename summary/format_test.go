@@ -0,0 +1,69 @@
+package summary
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/liamg/grace/tracer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CSV(t *testing.T) {
+	tests := []struct {
+		name     string
+		groupBy  GroupBy
+		expected string
+	}{
+		{
+			name: "combined",
+			expected: `syscall,count,errors,time_percent,total_ns,min_ns,avg_ns,p50_ns,p95_ns,p99_ns,max_ns,errnos
+openat,3,2,75.00,3000,1000,1000,1000,1000,1000,1000,ENOENT=2
+read,1,0,25.00,1000,1000,1000,1000,1000,1000,1000,
+`,
+		},
+		{
+			name:    "by thread",
+			groupBy: GroupByThread,
+			expected: `pid,tid,comm,syscall,count,errors,time_percent,total_ns,min_ns,avg_ns,p50_ns,p95_ns,p99_ns,max_ns,errnos
+100,100,cat,openat,3,2,75.00,3000,1000,1000,1000,1000,1000,1000,ENOENT=2
+100,100,cat,read,1,0,25.00,1000,1000,1000,1000,1000,1000,1000,
+,,,openat,3,2,75.00,3000,1000,1000,1000,1000,1000,1000,ENOENT=2
+,,,read,1,0,25.00,1000,1000,1000,1000,1000,1000,1000,
+`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buffer := &bytes.Buffer{}
+			s := New(buffer)
+			require.NoError(t, s.SetFormat(FormatCSV))
+			require.NoError(t, s.SetGroupBy(test.groupBy))
+			task := s.task(tracer.Process{Pid: 100, Tid: 100, Comm: "cat"})
+			for _, errno := range []string{"ENOENT", "ENOENT", ""} {
+				s.record("openat", time.Microsecond, errno)
+				task.Syscalls.add("openat", time.Microsecond, errno)
+			}
+			s.record("read", time.Microsecond, "")
+			task.Syscalls.add("read", time.Microsecond, "")
+			s.Print()
+			assert.Equal(t, test.expected, buffer.String())
+		})
+	}
+}
+
+func Test_CSVRejectsErrorBreakdown(t *testing.T) {
+	s := New(&bytes.Buffer{})
+	require.NoError(t, s.SetFormat(FormatCSV))
+	assert.Error(t, s.SetShowErrors(true, ErrorTargetNone))
+
+	s = New(&bytes.Buffer{})
+	require.NoError(t, s.SetShowErrors(true, ErrorTargetPath))
+	assert.Error(t, s.SetFormat(FormatCSV))
+}
+
+func Test_FormatErrnos(t *testing.T) {
+	assert.Equal(t, "", formatErrnos(nil))
+	assert.Equal(t, "ENOENT=12;EACCES=1;EPERM=1", formatErrnos(map[string]int{"EPERM": 1, "ENOENT": 12, "EACCES": 1}))
+}
@@ -0,0 +1,141 @@
+package summary
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// subBucketBits is the number of bits of precision kept for each duration. Each power of two is split into
+// 2^subBucketBits linear buckets, so percentiles are accurate to within ~6% without storing every sample.
+const subBucketBits = 4
+
+const subBuckets = 1 << subBucketBits
+
+// histogram counts durations in log-linear buckets
+type histogram struct {
+	counts []uint64
+	total  uint64
+}
+
+func bucketOf(d time.Duration) int {
+	v := uint64(d)
+	if d < 0 {
+		v = 0
+	}
+	if v < subBuckets {
+		return int(v)
+	}
+	shift := bits.Len64(v) - subBucketBits - 1
+	return (shift+1)*subBuckets + int(v>>shift) - subBuckets
+}
+
+// bucketBounds returns the range of durations [lower, upper) counted by the given bucket
+func bucketBounds(index int) (time.Duration, time.Duration) {
+	if index < subBuckets {
+		return time.Duration(index), time.Duration(index + 1)
+	}
+	shift := index/subBuckets - 1
+	sub := uint64(index%subBuckets + subBuckets)
+	return time.Duration(sub << shift), time.Duration((sub + 1) << shift)
+}
+
+func (h *histogram) add(d time.Duration) {
+	index := bucketOf(d)
+	if index >= len(h.counts) {
+		counts := make([]uint64, index+1)
+		copy(counts, h.counts)
+		h.counts = counts
+	}
+	h.counts[index]++
+	h.total++
+}
+
+// percentile returns the upper bound of the bucket containing the given percentile (0-100)
+func (h *histogram) percentile(p float64) time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	rank := uint64(math.Ceil(p / 100 * float64(h.total)))
+	if rank == 0 {
+		rank = 1
+	}
+	var seen uint64
+	for index, count := range h.counts {
+		seen += count
+		if seen >= rank {
+			_, upper := bucketBounds(index)
+			return upper - 1
+		}
+	}
+	return 0
+}
+
+// powers returns the number of durations in each power of two range, starting from [2^first, 2^(first+1))
+func (h *histogram) powers() (int, []uint64) {
+	var counts []uint64
+	first := -1
+	for index, count := range h.counts {
+		if count == 0 {
+			continue
+		}
+		lower, _ := bucketBounds(index)
+		power := bits.Len64(uint64(lower)) - 1
+		if power < 0 {
+			power = 0
+		}
+		if first == -1 {
+			first = power
+		}
+		for len(counts) <= power-first {
+			counts = append(counts, 0)
+		}
+		counts[power-first] += count
+	}
+	return first, counts
+}
+
+// histogramWidth is the width of the largest bar in an ASCII histogram
+const histogramWidth = 40
+
+// render writes an ASCII histogram of durations, with a row for each power of two
+func (h *histogram) render(w io.Writer) {
+	first, counts := h.powers()
+	var max uint64
+	for _, count := range counts {
+		if count > max {
+			max = count
+		}
+	}
+	for i, count := range counts {
+		lower := time.Duration(1) << (first + i)
+		upper := lower << 1
+		if first+i == 0 {
+			lower = 0
+		}
+		width := int(math.Round(float64(count) * histogramWidth / float64(max)))
+		if width == 0 && count > 0 {
+			width = 1
+		}
+		_, _ = fmt.Fprintf(
+			w,
+			"  %s %10d |%s%s|\n",
+			padLeft(fmt.Sprintf("[%s, %s)", formatDuration(lower), formatDuration(upper)), 20),
+			count,
+			strings.Repeat("█", width),
+			strings.Repeat(" ", histogramWidth-width),
+		)
+	}
+}
+
+// padLeft pads a string to the given width in runes, as durations in microseconds contain a multibyte character
+func padLeft(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return strings.Repeat(" ", width-n) + s
+	}
+	return s
+}
@@ -0,0 +1,95 @@
+package summary
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type jsonSummary struct {
+	Syscalls []jsonSyscall `json:"syscalls"`
+	Tasks    []jsonTask    `json:"tasks,omitempty"`
+	Errors   []jsonError   `json:"errors,omitempty"`
+}
+
+// jsonTask is a process or thread, depending on how the summary is grouped
+type jsonTask struct {
+	Pid         int           `json:"pid"`
+	Tid         int           `json:"tid,omitempty"`
+	Comm        string        `json:"comm"`
+	Count       int           `json:"count"`
+	Errors      int           `json:"errors"`
+	TimePercent float64       `json:"time_percent"`
+	TotalNs     int64         `json:"total_ns"`
+	Syscalls    []jsonSyscall `json:"syscalls"`
+}
+
+type jsonSyscall struct {
+	Syscall     string         `json:"syscall"`
+	Count       int            `json:"count"`
+	Errors      int            `json:"errors"`
+	TimePercent float64        `json:"time_percent"`
+	TotalNs     int64          `json:"total_ns"`
+	MinNs       int64          `json:"min_ns"`
+	AvgNs       int64          `json:"avg_ns"`
+	P50Ns       int64          `json:"p50_ns"`
+	P95Ns       int64          `json:"p95_ns"`
+	P99Ns       int64          `json:"p99_ns"`
+	MaxNs       int64          `json:"max_ns"`
+	Errnos      map[string]int `json:"errnos,omitempty"`
+}
+
+func (s *Summary) printJSON() {
+	output := jsonSummary{
+		Syscalls: convertJSONSyscalls(s.sorted()),
+	}
+	if s.groupBy != GroupByNone {
+		tasks := s.Tasks()
+		var total time.Duration
+		for _, task := range tasks {
+			total += task.Total()
+		}
+		for _, task := range tasks {
+			output.Tasks = append(output.Tasks, jsonTask{
+				Pid:         task.Pid,
+				Tid:         task.Tid,
+				Comm:        task.Comm,
+				Count:       task.Count(),
+				Errors:      task.Errors(),
+				TimePercent: percentOf(task.Total(), total),
+				TotalNs:     int64(task.Total()),
+				Syscalls:    convertJSONSyscalls(task.Syscalls.sorted(s.sortKey)),
+			})
+		}
+	}
+	if s.showErrors {
+		output.Errors = s.jsonErrors()
+	}
+	encoder := json.NewEncoder(s.w)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(output)
+}
+
+func convertJSONSyscalls(all []*Stats) []jsonSyscall {
+	syscalls := []jsonSyscall{}
+	total := totalOf(all)
+	for _, stats := range all {
+		syscall := jsonSyscall{
+			Syscall:     stats.Name,
+			Count:       stats.Count,
+			Errors:      stats.Errors,
+			TimePercent: percentOf(stats.Total, total),
+			TotalNs:     int64(stats.Total),
+			MinNs:       int64(stats.Min),
+			AvgNs:       int64(stats.Avg()),
+			P50Ns:       int64(stats.Percentile(50)),
+			P95Ns:       int64(stats.Percentile(95)),
+			P99Ns:       int64(stats.Percentile(99)),
+			MaxNs:       int64(stats.Max),
+		}
+		if len(stats.Errnos) > 0 {
+			syscall.Errnos = stats.Errnos
+		}
+		syscalls = append(syscalls, syscall)
+	}
+	return syscalls
+}
@@ -0,0 +1,124 @@
+package summary
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aquasecurity/table"
+)
+
+// liveState holds the totals at the time of the last live update, so rates can be calculated for the next one
+type liveState struct {
+	at     time.Time
+	counts map[string]liveCounts
+	redraw bool
+}
+
+type liveCounts struct {
+	count  int
+	errors int
+	total  time.Duration
+}
+
+// liveRow is a syscall in a live update
+type liveRow struct {
+	stats      *Stats
+	callRate   float64
+	errorRate  float64
+	busyPerSec time.Duration // time spent in the syscall per second of wall time
+}
+
+// SetRedraw clears the screen before each live update, so the table is redrawn in place like top
+func (s *Summary) SetRedraw(redraw bool) {
+	s.live.redraw = redraw
+}
+
+// PrintLive writes a table of the rate of each syscall since the last live update, alongside the totals so far. If
+// maxRows is more than zero, only that many syscalls are shown.
+func (s *Summary) PrintLive(maxRows int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.printLive(time.Now(), maxRows)
+}
+
+// liveRows returns the rates of each syscall since the last live update, ordered by the sort key or by call rate
+func (s *Summary) liveRows(now time.Time) []liveRow {
+	elapsed := now.Sub(s.live.at).Seconds()
+	if elapsed <= 0 {
+		elapsed = 1
+	}
+	var rows []liveRow
+	for _, stats := range s.sorted() {
+		previous := s.live.counts[stats.Name]
+		rows = append(rows, liveRow{
+			stats:      stats,
+			callRate:   float64(stats.Count-previous.count) / elapsed,
+			errorRate:  float64(stats.Errors-previous.errors) / elapsed,
+			busyPerSec: time.Duration(float64(stats.Total-previous.total) / elapsed),
+		})
+	}
+	if s.sortKey == "" {
+		sort.SliceStable(rows, func(i, j int) bool {
+			return rows[i].callRate > rows[j].callRate
+		})
+	}
+	return rows
+}
+
+func (s *Summary) printLive(now time.Time, maxRows int) {
+
+	rows := s.liveRows(now)
+
+	var count int
+	var callRate, errorRate float64
+	for _, row := range rows {
+		count += row.stats.Count
+		callRate += row.callRate
+		errorRate += row.errorRate
+	}
+
+	if s.live.redraw {
+		_, _ = fmt.Fprint(s.w, "\x1b[H\x1b[2J")
+	} else {
+		_, _ = fmt.Fprintln(s.w)
+	}
+	_, _ = fmt.Fprintf(
+		s.w,
+		"%s  %d syscalls, %.1f calls/s, %.1f errors/s over the last %s\n",
+		now.Format("15:04:05"), count, callRate, errorRate, now.Sub(s.live.at).Round(time.Millisecond),
+	)
+
+	tab := table.New(s.w)
+	tab.SetRowLines(false)
+	tab.AddHeaders("calls/s", "errors/s", "busy/s", "count", "errors", "seconds", "avg", "p99", "max", "syscall")
+	tab.SetAlignment(
+		table.AlignRight, table.AlignRight, table.AlignRight, table.AlignRight, table.AlignRight,
+		table.AlignRight, table.AlignRight, table.AlignRight, table.AlignRight, table.AlignLeft,
+	)
+	tab.SetLineStyle(table.StyleBlue)
+
+	for i, row := range rows {
+		if maxRows > 0 && i >= maxRows {
+			break
+		}
+		tab.AddRow(
+			fmt.Sprintf("%.1f", row.callRate),
+			fmt.Sprintf("%.1f", row.errorRate),
+			formatDuration(row.busyPerSec),
+			fmt.Sprintf("%d", row.stats.Count),
+			fmt.Sprintf("%d", row.stats.Errors),
+			fmt.Sprintf("%.6f", row.stats.Total.Seconds()),
+			formatDuration(row.stats.Avg()),
+			formatDuration(row.stats.Percentile(99)),
+			formatDuration(row.stats.Max),
+			row.stats.Name,
+		)
+	}
+	tab.Render()
+
+	s.live.at = now
+	for name, stats := range s.syscalls {
+		s.live.counts[name] = liveCounts{count: stats.Count, errors: stats.Errors, total: stats.Total}
+	}
+}
@@ -0,0 +1,66 @@
+package summary
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_LiveRates(t *testing.T) {
+	s := New(&bytes.Buffer{})
+	start := s.live.at
+
+	for i := 0; i < 10; i++ {
+		s.record("read", time.Millisecond, "")
+	}
+	s.record("openat", time.Millisecond, "ENOENT")
+
+	rows := s.liveRows(start.Add(2 * time.Second))
+	require.Len(t, rows, 2)
+	assert.Equal(t, "read", rows[0].stats.Name)
+	assert.Equal(t, 5.0, rows[0].callRate)
+	assert.Equal(t, 0.0, rows[0].errorRate)
+	assert.Equal(t, 5*time.Millisecond, rows[0].busyPerSec)
+	assert.Equal(t, 0.5, rows[1].callRate)
+	assert.Equal(t, 0.5, rows[1].errorRate)
+
+	// rates are calculated since the last live update, but totals are kept
+	s.printLive(start.Add(2*time.Second), 0)
+	for i := 0; i < 4; i++ {
+		s.record("openat", time.Millisecond, "ENOENT")
+	}
+	rows = s.liveRows(start.Add(3 * time.Second))
+	require.Len(t, rows, 2)
+	assert.Equal(t, "openat", rows[0].stats.Name)
+	assert.Equal(t, 4.0, rows[0].callRate)
+	assert.Equal(t, 4.0, rows[0].errorRate)
+	assert.Equal(t, 5, rows[0].stats.Count)
+	assert.Equal(t, 0.0, rows[1].callRate)
+	assert.Equal(t, 10, rows[1].stats.Count)
+
+	// an explicit sort key takes precedence over the call rate
+	require.NoError(t, s.SetSortKey("count"))
+	rows = s.liveRows(start.Add(3 * time.Second))
+	assert.Equal(t, "read", rows[0].stats.Name)
+}
+
+func Test_LiveOutput(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	s := New(buffer)
+	s.SetRedraw(true)
+	for _, name := range []string{"read", "read", "read", "write", "write", "close"} {
+		s.record(name, time.Millisecond, "")
+	}
+	s.printLive(s.live.at.Add(time.Second), 2)
+
+	output := buffer.String()
+	assert.True(t, strings.HasPrefix(output, "\x1b[H\x1b[2J"))
+	assert.Contains(t, output, "6 syscalls, 6.0 calls/s, 0.0 errors/s over the last 1s\n")
+	assert.Contains(t, output, " read ")
+	assert.Contains(t, output, " write ")
+	assert.NotContains(t, output, " close ")
+}
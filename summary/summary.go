@@ -0,0 +1,133 @@
+package summary
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/liamg/grace/tracer"
+)
+
+// Summary collects timing statistics for each syscall, and prints them as a table when the trace ends. It is safe
+// to print the summary while syscalls are being recorded.
+type Summary struct {
+	mu             sync.Mutex
+	w              io.Writer
+	syscalls       syscallStats
+	tasks          map[taskKey]*Task
+	groupBy        GroupBy
+	failures       map[failure]int
+	sortKey        string
+	format         Format
+	showHistograms bool
+	showErrors     bool
+	errorsBy       ErrorTarget
+	live           liveState
+}
+
+// Stats holds the statistics for a single syscall
+type Stats struct {
+	Name   string
+	Count  int
+	Errors int
+	Total  time.Duration
+	Min    time.Duration
+	Max    time.Duration
+	Errnos map[string]int // number of failed calls for each error name
+	hist   histogram
+}
+
+// New creates a summary which will be printed to the given writer
+func New(w io.Writer) *Summary {
+	return &Summary{
+		w:        w,
+		syscalls: make(syscallStats),
+		tasks:    make(map[taskKey]*Task),
+		failures: make(map[failure]int),
+		format:   FormatTable,
+		live: liveState{
+			at:     time.Now(),
+			counts: make(map[string]liveCounts),
+		},
+	}
+}
+
+// SetSortKey sets the column used to order the rows of the table, which are otherwise ordered by syscall name
+func (s *Summary) SetSortKey(key string) error {
+	if _, ok := sortKeys[key]; !ok && key != "" {
+		return fmt.Errorf("invalid sort key '%s': must be one of %s", key, sortKeyNames())
+	}
+	s.sortKey = key
+	return nil
+}
+
+// SetShowHistograms enables a latency histogram for each syscall beneath the table
+func (s *Summary) SetShowHistograms(show bool) {
+	s.showHistograms = show
+}
+
+// HandleSyscallExit records a completed syscall
+func (s *Summary) HandleSyscallExit(call *tracer.Syscall) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var errno string
+	if number := call.Errno(); number != 0 {
+		s.failures[failure{syscall: call.Name(), errno: number, target: s.errorTarget(call)}]++
+		errno = errnoName(number)
+	}
+	s.record(call.Name(), call.Duration(), errno)
+	if s.groupBy != GroupByNone {
+		s.task(call.Process()).Syscalls.add(call.Name(), call.Duration(), errno)
+	}
+}
+
+// record adds a call to the combined stats for a syscall - errno is the name of the error the call failed with, if any
+func (s *Summary) record(name string, duration time.Duration, errno string) {
+	s.syscalls.add(name, duration, errno)
+}
+
+// syscallStats holds the stats for each syscall, by name
+type syscallStats map[string]*Stats
+
+func (m syscallStats) add(name string, duration time.Duration, errno string) {
+	stats, ok := m[name]
+	if !ok {
+		stats = &Stats{Name: name, Min: duration, Max: duration, Errnos: make(map[string]int)}
+		m[name] = stats
+	}
+	stats.Count++
+	if errno != "" {
+		stats.Errors++
+		stats.Errnos[errno]++
+	}
+	stats.Total += duration
+	if duration < stats.Min {
+		stats.Min = duration
+	}
+	if duration > stats.Max {
+		stats.Max = duration
+	}
+	stats.hist.add(duration)
+}
+
+// Avg returns the mean time spent in the syscall
+func (s *Stats) Avg() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(s.Count)
+}
+
+// Percentile returns the time within which the given percentage (0-100) of calls completed. This is approximate, but is
+// always within the range of times that were actually seen.
+func (s *Stats) Percentile(p float64) time.Duration {
+	d := s.hist.percentile(p)
+	if d < s.Min {
+		return s.Min
+	}
+	if d > s.Max {
+		return s.Max
+	}
+	return d
+}
@@ -0,0 +1,142 @@
+package summary
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_HistogramBuckets(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration time.Duration
+	}{
+		{name: "zero", duration: 0},
+		{name: "small", duration: 7},
+		{name: "first log bucket", duration: 16},
+		{name: "microseconds", duration: 12345},
+		{name: "milliseconds", duration: 7654321},
+		{name: "seconds", duration: 3 * time.Second},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lower, upper := bucketBounds(bucketOf(test.duration))
+			assert.LessOrEqual(t, lower, test.duration)
+			assert.Greater(t, upper, test.duration)
+			// buckets should be no wider than 1/16th of their lower bound
+			assert.LessOrEqual(t, upper-lower, lower/subBuckets+1)
+		})
+	}
+}
+
+func Test_Percentiles(t *testing.T) {
+	s := New(&bytes.Buffer{})
+	for i := 1; i <= 100; i++ {
+		var errno string
+		if i%10 == 0 {
+			errno = "EAGAIN"
+		}
+		s.record("read", time.Duration(i)*time.Millisecond, errno)
+	}
+	// a single slow call should show up in the tail but not the median
+	s.record("read", time.Second, "")
+
+	stats := s.syscalls["read"]
+	assert.Equal(t, 101, stats.Count)
+	assert.Equal(t, 10, stats.Errors)
+	assert.Equal(t, map[string]int{"EAGAIN": 10}, stats.Errnos)
+	assert.Equal(t, time.Millisecond, stats.Min)
+	assert.Equal(t, time.Second, stats.Max)
+	assert.Equal(t, (5050*time.Millisecond+time.Second)/101, stats.Avg())
+
+	tests := []struct {
+		percentile float64
+		expected   time.Duration
+	}{
+		{percentile: 0, expected: time.Millisecond},
+		{percentile: 50, expected: 51 * time.Millisecond},
+		{percentile: 95, expected: 96 * time.Millisecond},
+		{percentile: 99, expected: 100 * time.Millisecond},
+		{percentile: 100, expected: time.Second},
+	}
+	for _, test := range tests {
+		actual := stats.Percentile(test.percentile)
+		assert.InEpsilon(t, float64(test.expected), float64(actual), 1.0/subBuckets, "p%v", test.percentile)
+		assert.GreaterOrEqual(t, actual, stats.Min)
+		assert.LessOrEqual(t, actual, stats.Max)
+	}
+}
+
+func Test_SortKeys(t *testing.T) {
+	s := New(&bytes.Buffer{})
+	s.record("read", time.Millisecond, "")
+	s.record("read", time.Millisecond, "")
+	s.record("write", 5*time.Millisecond, "EBADF")
+	s.record("close", time.Microsecond, "")
+
+	tests := []struct {
+		key      string
+		expected []string
+	}{
+		{key: "", expected: []string{"close", "read", "write"}},
+		{key: "count", expected: []string{"read", "close", "write"}},
+		{key: "time", expected: []string{"write", "read", "close"}},
+		{key: "errors", expected: []string{"write", "close", "read"}},
+		{key: "p99", expected: []string{"write", "read", "close"}},
+	}
+	for _, test := range tests {
+		t.Run(test.key, func(t *testing.T) {
+			require.NoError(t, s.SetSortKey(test.key))
+			var names []string
+			for _, stats := range s.sorted() {
+				names = append(names, stats.Name)
+			}
+			assert.Equal(t, test.expected, names)
+		})
+	}
+
+	assert.Error(t, s.SetSortKey("colour"))
+}
+
+func Test_HistogramOutput(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	s := New(buffer)
+	s.SetShowHistograms(true)
+	for i := 0; i < 10; i++ {
+		s.record("read", 1500*time.Nanosecond, "")
+	}
+	s.record("read", 5*time.Microsecond, "")
+	s.Print()
+
+	output := buffer.String()
+	assert.Contains(t, output, "read (11 calls, p50 1.53µs, p99 5µs, max 5µs)\n")
+
+	lines := strings.Split(output[strings.Index(output, "read (11 calls"):], "\n")
+	require.Len(t, lines, 5)
+	assert.Equal(t, "      [1.02µs, 2.05µs)         10 |"+strings.Repeat("█", 40)+"|", lines[1])
+	assert.Equal(t, "       [2.05µs, 4.1µs)          0 |"+strings.Repeat(" ", 40)+"|", lines[2])
+	assert.Equal(t, "       [4.1µs, 8.19µs)          1 |"+strings.Repeat("█", 4)+strings.Repeat(" ", 36)+"|", lines[3])
+}
+
+func Test_FormatDuration(t *testing.T) {
+	tests := []struct {
+		duration time.Duration
+		expected string
+	}{
+		{duration: 0, expected: "0ns"},
+		{duration: 999, expected: "999ns"},
+		{duration: 1000, expected: "1µs"},
+		{duration: 1234, expected: "1.23µs"},
+		{duration: 12345, expected: "12.3µs"},
+		{duration: 123456, expected: "123µs"},
+		{duration: 2 * time.Millisecond, expected: "2ms"},
+		{duration: 1500 * time.Millisecond, expected: "1.5s"},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expected, formatDuration(test.duration))
+	}
+}
@@ -0,0 +1,162 @@
+package summary
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aquasecurity/table"
+)
+
+// sortKeys maps each sort key to a function which returns the value to sort by (largest first)
+var sortKeys = map[string]func(*Stats) int64{
+	"time":    func(s *Stats) int64 { return int64(s.Total) },
+	"seconds": func(s *Stats) int64 { return int64(s.Total) },
+	"count":   func(s *Stats) int64 { return int64(s.Count) },
+	"errors":  func(s *Stats) int64 { return int64(s.Errors) },
+	"min":     func(s *Stats) int64 { return int64(s.Min) },
+	"avg":     func(s *Stats) int64 { return int64(s.Avg()) },
+	"p50":     func(s *Stats) int64 { return int64(s.Percentile(50)) },
+	"p95":     func(s *Stats) int64 { return int64(s.Percentile(95)) },
+	"p99":     func(s *Stats) int64 { return int64(s.Percentile(99)) },
+	"max":     func(s *Stats) int64 { return int64(s.Max) },
+}
+
+func sortKeyNames() string {
+	var names []string
+	for name := range sortKeys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// sorted returns the combined stats for each syscall, ordered by the sort key
+func (s *Summary) sorted() []*Stats {
+	return s.syscalls.sorted(s.sortKey)
+}
+
+// sorted returns the stats for each syscall, ordered by the given sort key
+func (m syscallStats) sorted(sortKey string) []*Stats {
+	var all []*Stats
+	for _, stats := range m {
+		all = append(all, stats)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Name < all[j].Name
+	})
+	if key, ok := sortKeys[sortKey]; ok {
+		sort.SliceStable(all, func(i, j int) bool {
+			return key(all[i]) > key(all[j])
+		})
+	}
+	return all
+}
+
+// Print writes the summary in the configured format
+func (s *Summary) Print() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch s.format {
+	case FormatJSON:
+		s.printJSON()
+	case FormatCSV:
+		s.printCSV()
+	default:
+		s.printTable()
+	}
+}
+
+// printTable writes the summary table, followed by the error breakdown and a histogram for each syscall if enabled.
+// If the summary is grouped by process or thread, a table for each is written first.
+func (s *Summary) printTable() {
+
+	all := s.sorted()
+
+	if s.groupBy != GroupByNone {
+		s.printTasks()
+		plural := "processes"
+		if s.groupBy == GroupByThread {
+			plural = "threads"
+		}
+		_, _ = fmt.Fprintf(s.w, "\ntotal for all %s\n", plural)
+	}
+
+	s.renderSyscalls(all)
+
+	if s.showErrors {
+		s.printErrors()
+	}
+
+	if !s.showHistograms {
+		return
+	}
+	for _, stats := range all {
+		_, _ = fmt.Fprintf(s.w, "\n%s (%d calls, p50 %s, p99 %s, max %s)\n",
+			stats.Name, stats.Count, formatDuration(stats.Percentile(50)), formatDuration(stats.Percentile(99)), formatDuration(stats.Max))
+		stats.hist.render(s.w)
+	}
+}
+
+// renderSyscalls writes a table of the given syscall stats
+func (s *Summary) renderSyscalls(all []*Stats) {
+
+	tab := table.New(s.w)
+	tab.SetRowLines(false)
+	tab.AddHeaders("time %", "seconds", "count", "errors", "min", "avg", "p50", "p95", "p99", "max", "syscall")
+	tab.SetAlignment(
+		table.AlignRight, table.AlignRight, table.AlignRight, table.AlignRight, table.AlignRight, table.AlignRight,
+		table.AlignRight, table.AlignRight, table.AlignRight, table.AlignRight, table.AlignLeft,
+	)
+	tab.SetLineStyle(table.StyleBlue)
+
+	total := totalOf(all)
+	for _, stats := range all {
+		tab.AddRow(
+			fmt.Sprintf("%.2f", percentOf(stats.Total, total)),
+			fmt.Sprintf("%.6f", stats.Total.Seconds()),
+			fmt.Sprintf("%d", stats.Count),
+			fmt.Sprintf("%d", stats.Errors),
+			formatDuration(stats.Min),
+			formatDuration(stats.Avg()),
+			formatDuration(stats.Percentile(50)),
+			formatDuration(stats.Percentile(95)),
+			formatDuration(stats.Percentile(99)),
+			formatDuration(stats.Max),
+			stats.Name,
+		)
+	}
+
+	tab.Render()
+}
+
+// formatDuration formats a duration compactly, with 3 significant figures
+func formatDuration(d time.Duration) string {
+	switch {
+	case d < time.Microsecond:
+		return fmt.Sprintf("%dns", d.Nanoseconds())
+	case d < time.Millisecond:
+		return trimFloat(float64(d)/float64(time.Microsecond)) + "µs"
+	case d < time.Second:
+		return trimFloat(float64(d)/float64(time.Millisecond)) + "ms"
+	default:
+		return trimFloat(d.Seconds()) + "s"
+	}
+}
+
+func trimFloat(f float64) string {
+	var s string
+	switch {
+	case f >= 100:
+		s = fmt.Sprintf("%.0f", f)
+	case f >= 10:
+		s = fmt.Sprintf("%.1f", f)
+	default:
+		s = fmt.Sprintf("%.2f", f)
+	}
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
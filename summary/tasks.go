@@ -0,0 +1,176 @@
+package summary
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aquasecurity/table"
+	"github.com/liamg/grace/tracer"
+)
+
+// GroupBy determines whether the summary is also split by process or by thread
+type GroupBy string
+
+const (
+	GroupByNone    GroupBy = ""
+	GroupByProcess GroupBy = "process"
+	GroupByThread  GroupBy = "thread"
+)
+
+// Task is a process or thread which made syscalls, depending on how the summary is grouped
+type Task struct {
+	Pid      int
+	Tid      int // zero if the summary is grouped by process
+	Comm     string
+	Syscalls syscallStats
+}
+
+type taskKey struct {
+	pid int
+	tid int
+}
+
+// SetGroupBy splits the summary by process or by thread, in addition to the combined total for all of them
+func (s *Summary) SetGroupBy(by GroupBy) error {
+	switch by {
+	case GroupByNone, GroupByProcess, GroupByThread:
+	default:
+		return fmt.Errorf("invalid summary grouping '%s': must be one of %s, %s", by, GroupByProcess, GroupByThread)
+	}
+	s.groupBy = by
+	return nil
+}
+
+// task returns the process or thread which the given process made its syscalls as
+func (s *Summary) task(proc tracer.Process) *Task {
+	key := taskKey{pid: proc.Pid}
+	if s.groupBy == GroupByThread {
+		key.tid = proc.Tid
+	}
+	task, ok := s.tasks[key]
+	if !ok {
+		task = &Task{Pid: key.pid, Tid: key.tid, Syscalls: make(syscallStats)}
+		s.tasks[key] = task
+	}
+	// a process is named after its main thread, and the name can change after an exec or prctl(PR_SET_NAME)
+	if proc.Comm != "" && (task.Comm == "" || s.groupBy == GroupByThread || proc.Tid == proc.Pid) {
+		task.Comm = proc.Comm
+	}
+	return task
+}
+
+// Count returns the number of syscalls made by the task
+func (t *Task) Count() int {
+	var count int
+	for _, stats := range t.Syscalls {
+		count += stats.Count
+	}
+	return count
+}
+
+// Errors returns the number of failed syscalls made by the task
+func (t *Task) Errors() int {
+	var errors int
+	for _, stats := range t.Syscalls {
+		errors += stats.Errors
+	}
+	return errors
+}
+
+// Total returns the total time the task spent in syscalls
+func (t *Task) Total() time.Duration {
+	var total time.Duration
+	for _, stats := range t.Syscalls {
+		total += stats.Total
+	}
+	return total
+}
+
+func (t *Task) String() string {
+	label := fmt.Sprintf("pid %d", t.Pid)
+	if t.Tid != 0 {
+		label += fmt.Sprintf(" tid %d", t.Tid)
+	}
+	if t.Comm != "" {
+		label += fmt.Sprintf(" (%s)", t.Comm)
+	}
+	return label
+}
+
+// taskSortKeys maps the sort keys which apply to processes and threads to a function which returns the value to sort
+// by (largest first). Any other sort key orders them by id.
+var taskSortKeys = map[string]func(*Task) int64{
+	"time":    func(t *Task) int64 { return int64(t.Total()) },
+	"seconds": func(t *Task) int64 { return int64(t.Total()) },
+	"count":   func(t *Task) int64 { return int64(t.Count()) },
+	"errors":  func(t *Task) int64 { return int64(t.Errors()) },
+}
+
+// Tasks returns each process or thread which made syscalls, ordered by the sort key
+func (s *Summary) Tasks() []*Task {
+	var tasks []*Task
+	for _, task := range s.tasks {
+		tasks = append(tasks, task)
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		if tasks[i].Pid != tasks[j].Pid {
+			return tasks[i].Pid < tasks[j].Pid
+		}
+		return tasks[i].Tid < tasks[j].Tid
+	})
+	if key, ok := taskSortKeys[s.sortKey]; ok {
+		sort.SliceStable(tasks, func(i, j int) bool {
+			return key(tasks[i]) > key(tasks[j])
+		})
+	}
+	return tasks
+}
+
+// printTasks writes a table comparing each process or thread, followed by a summary table for each of them
+func (s *Summary) printTasks() {
+
+	tasks := s.Tasks()
+
+	headers := []string{"time %", "seconds", "count", "errors", "pid"}
+	alignment := []table.Alignment{table.AlignRight, table.AlignRight, table.AlignRight, table.AlignRight, table.AlignRight}
+	if s.groupBy == GroupByThread {
+		headers = append(headers, "tid")
+		alignment = append(alignment, table.AlignRight)
+	}
+	headers = append(headers, "comm")
+	alignment = append(alignment, table.AlignLeft)
+
+	tab := table.New(s.w)
+	tab.SetRowLines(false)
+	tab.AddHeaders(headers...)
+	tab.SetAlignment(alignment...)
+	tab.SetLineStyle(table.StyleBlue)
+
+	var total time.Duration
+	for _, task := range tasks {
+		total += task.Total()
+	}
+
+	for _, task := range tasks {
+		row := []string{
+			fmt.Sprintf("%.2f", percentOf(task.Total(), total)),
+			fmt.Sprintf("%.6f", task.Total().Seconds()),
+			fmt.Sprintf("%d", task.Count()),
+			fmt.Sprintf("%d", task.Errors()),
+			fmt.Sprintf("%d", task.Pid),
+		}
+		if s.groupBy == GroupByThread {
+			row = append(row, fmt.Sprintf("%d", task.Tid))
+		}
+		row = append(row, task.Comm)
+		tab.AddRow(row...)
+	}
+
+	tab.Render()
+
+	for _, task := range tasks {
+		_, _ = fmt.Fprintf(s.w, "\n%s\n", task)
+		s.renderSyscalls(task.Syscalls.sorted(s.sortKey))
+	}
+}
@@ -0,0 +1,81 @@
+package summary
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/liamg/grace/tracer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Tasks(t *testing.T) {
+	tests := []struct {
+		name     string
+		groupBy  GroupBy
+		sortKey  string
+		expected []string
+	}{
+		{name: "by process", groupBy: GroupByProcess, expected: []string{"pid 100 (server)", "pid 200 (sh)"}},
+		{name: "by process and time", groupBy: GroupByProcess, sortKey: "time", expected: []string{"pid 200 (sh)", "pid 100 (server)"}},
+		{name: "by thread", groupBy: GroupByThread, expected: []string{"pid 100 tid 100 (server)", "pid 100 tid 101 (worker)", "pid 200 tid 200 (sh)"}},
+		{name: "by thread and count", groupBy: GroupByThread, sortKey: "count", expected: []string{"pid 100 tid 101 (worker)", "pid 200 tid 200 (sh)", "pid 100 tid 100 (server)"}},
+		{name: "latency keys order by id", groupBy: GroupByThread, sortKey: "p99", expected: []string{"pid 100 tid 100 (server)", "pid 100 tid 101 (worker)", "pid 200 tid 200 (sh)"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := New(&bytes.Buffer{})
+			require.NoError(t, s.SetGroupBy(test.groupBy))
+			require.NoError(t, s.SetSortKey(test.sortKey))
+
+			// the worker thread is seen first, but the process should be named after its main thread
+			worker := s.task(tracer.Process{Pid: 100, Tid: 101, Comm: "worker"})
+			worker.Syscalls.add("futex", time.Millisecond, "")
+			worker.Syscalls.add("futex", time.Millisecond, "EAGAIN")
+			worker.Syscalls.add("read", time.Millisecond, "")
+			s.task(tracer.Process{Pid: 100, Tid: 100, Comm: "server"}).Syscalls.add("accept4", time.Millisecond, "")
+			s.task(tracer.Process{Pid: 200, Tid: 200, Comm: "sh"}).Syscalls.add("wait4", time.Second, "")
+			s.task(tracer.Process{Pid: 200, Tid: 200, Comm: "sh"}).Syscalls.add("wait4", time.Second, "")
+
+			var labels []string
+			for _, task := range s.Tasks() {
+				labels = append(labels, task.String())
+			}
+			assert.Equal(t, test.expected, labels)
+		})
+	}
+
+	assert.Error(t, New(&bytes.Buffer{}).SetGroupBy("cgroup"))
+}
+
+func Test_TaskTotals(t *testing.T) {
+	s := New(&bytes.Buffer{})
+	require.NoError(t, s.SetGroupBy(GroupByProcess))
+	task := s.task(tracer.Process{Pid: 100, Tid: 100, Comm: "sh"})
+	task.Syscalls.add("openat", time.Millisecond, "ENOENT")
+	task.Syscalls.add("openat", 2*time.Millisecond, "")
+	task.Syscalls.add("read", 3*time.Millisecond, "")
+
+	assert.Equal(t, 3, task.Count())
+	assert.Equal(t, 1, task.Errors())
+	assert.Equal(t, 6*time.Millisecond, task.Total())
+}
+
+func Test_TasksTable(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	s := New(buffer)
+	require.NoError(t, s.SetGroupBy(GroupByProcess))
+	s.task(tracer.Process{Pid: 100, Tid: 100, Comm: "make"}).Syscalls.add("wait4", time.Second, "")
+	s.task(tracer.Process{Pid: 101, Tid: 101, Comm: "cc"}).Syscalls.add("read", time.Millisecond, "")
+	s.record("wait4", time.Second, "")
+	s.record("read", time.Millisecond, "")
+	s.Print()
+
+	output := buffer.String()
+	first := strings.Index(output, "\npid 100 (make)\n")
+	second := strings.Index(output, "\npid 101 (cc)\n")
+	total := strings.Index(output, "\ntotal for all processes\n")
+	assert.True(t, first > 0 && second > first && total > second, output)
+}
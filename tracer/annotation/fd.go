@@ -2,19 +2,19 @@ package annotation
 
 import (
 	"fmt"
-	"os"
 	"strconv"
 	"strings"
 	"syscall"
 
 	"github.com/liamg/grace/tracer/netw"
+	"github.com/liamg/grace/tracer/procfs"
 
 	"golang.org/x/sys/unix"
 )
 
 func AnnotateFd(arg Arg, pid int) {
 
-	if path, err := os.Readlink(fmt.Sprintf("/proc/%d/fd/%d", pid, arg.Raw())); err == nil {
+	if path, err := procfs.Readlink(fmt.Sprintf("/proc/%d/fd/%d", pid, arg.Raw())); err == nil {
 
 		switch {
 		case strings.HasPrefix(path, "socket:["):
@@ -0,0 +1,6 @@
+package tracer
+
+import "syscall"
+
+// peekData reads the memory of a tracee. It is replaced when recording or replaying a trace.
+var peekData = syscall.PtracePeekData
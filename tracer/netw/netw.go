@@ -1,5 +1,11 @@
 package netw
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 func ListConnections() ([]Connection, error) {
 	tcpConnections, err := ListTCPConnections()
 	if err != nil {
@@ -15,3 +21,30 @@ func ListConnections() ([]Connection, error) {
 	}
 	return append(append(tcpConnections, udpConnections...), icmpConnections...), nil
 }
+
+// FindConnection returns the TCP, UDP or ICMP connection for the socket with the given inode
+func FindConnection(inode int) (*Connection, error) {
+	conns, err := ListConnections()
+	if err != nil {
+		return nil, err
+	}
+	for _, conn := range conns {
+		if conn.INode == inode {
+			conn := conn
+			return &conn, nil
+		}
+	}
+	return nil, fmt.Errorf("no connection found for socket inode %d", inode)
+}
+
+// ParseSocketLink returns the inode of a socket from the target of a /proc/<pid>/fd/<fd> link, e.g. "socket:[1234]"
+func ParseSocketLink(link string) (int, bool) {
+	if !strings.HasPrefix(link, "socket:[") || !strings.HasSuffix(link, "]") {
+		return 0, false
+	}
+	inode, err := strconv.Atoi(link[len("socket:[") : len(link)-1])
+	if err != nil {
+		return 0, false
+	}
+	return inode, true
+}
@@ -3,9 +3,10 @@ package netw
 import (
 	"fmt"
 	"net"
-	"os"
 	"strconv"
 	"strings"
+
+	"github.com/liamg/grace/tracer/procfs"
 )
 
 type Connection struct {
@@ -49,7 +50,7 @@ func parseBaseProtocol(protocol string) ([]Connection, error) {
 func parseFile(protocol string) ([]Connection, error) {
 
 	file := netPath + protocol
-	data, err := os.ReadFile(file)
+	data, err := procfs.ReadFile(file)
 	if err != nil {
 		return nil, err
 	}
@@ -125,23 +126,13 @@ func parseIPAndPortFromHex(hex string) (net.IP, int, error) {
 			hexToByte(rawip[0:2]),
 		)
 	case 32:
-		ip = []byte{
-			hexToByte(rawip[30:32]),
-			hexToByte(rawip[28:30]),
-			hexToByte(rawip[26:28]),
-			hexToByte(rawip[24:26]),
-			hexToByte(rawip[22:24]),
-			hexToByte(rawip[20:22]),
-			hexToByte(rawip[18:20]),
-			hexToByte(rawip[16:18]),
-			hexToByte(rawip[14:16]),
-			hexToByte(rawip[12:14]),
-			hexToByte(rawip[10:12]),
-			hexToByte(rawip[8:10]),
-			hexToByte(rawip[6:8]),
-			hexToByte(rawip[4:6]),
-			hexToByte(rawip[2:4]),
-			hexToByte(rawip[0:2]),
+		// four 32-bit words, each in host byte order
+		ip = make(net.IP, net.IPv6len)
+		for word := 0; word < 4; word++ {
+			for i := 0; i < 4; i++ {
+				offset := word*8 + (3-i)*2
+				ip[word*4+i] = hexToByte(rawip[offset : offset+2])
+			}
 		}
 	default:
 		return nil, 0, fmt.Errorf("invalid ipv4 hex '%s'", hex)
@@ -0,0 +1,39 @@
+package netw
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIPAndPortFromHex(t *testing.T) {
+	tests := []struct {
+		hex  string
+		ip   string
+		port int
+	}{
+		{hex: "0100007F:1538", ip: "127.0.0.1", port: 5432},
+		{hex: "00000000000000000000000001000000:0050", ip: "::1", port: 80},
+		{hex: "B80D0120000000000000000001000000:01BB", ip: "2001:db8::1", port: 443},
+		{hex: "0000000000000000FFFF00000100007F:0016", ip: "127.0.0.1", port: 22},
+	}
+	for _, test := range tests {
+		t.Run(test.hex, func(t *testing.T) {
+			ip, port, err := parseIPAndPortFromHex(test.hex)
+			require.NoError(t, err)
+			assert.True(t, net.ParseIP(test.ip).Equal(ip), "%s != %s", ip, test.ip)
+			assert.Equal(t, test.port, port)
+		})
+	}
+}
+
+func TestParseSocketLink(t *testing.T) {
+	inode, ok := ParseSocketLink("socket:[26544]")
+	assert.True(t, ok)
+	assert.Equal(t, 26544, inode)
+
+	_, ok = ParseSocketLink("/etc/passwd")
+	assert.False(t, ok)
+}
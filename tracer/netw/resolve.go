@@ -0,0 +1,43 @@
+package netw
+
+import (
+	"fmt"
+
+	"github.com/liamg/grace/tracer/procfs"
+)
+
+// Resolver finds the file or socket which a file descriptor refers to. Connections are cached by socket inode, as
+// reading the connection tables is expensive.
+type Resolver struct {
+	sockets map[int]*Connection
+}
+
+// NewResolver creates a resolver with an empty cache
+func NewResolver() *Resolver {
+	return &Resolver{
+		sockets: make(map[int]*Connection),
+	}
+}
+
+// Resolve returns the target of the /proc link for the given descriptor, such as a path or "socket:[1234]". If the
+// descriptor is a TCP, UDP or ICMP socket, its connection is returned too.
+func (r *Resolver) Resolve(pid int, fd int) (string, *Connection, error) {
+	link, err := procfs.Readlink(fmt.Sprintf("/proc/%d/fd/%d", pid, fd))
+	if err != nil {
+		return "", nil, err
+	}
+	inode, ok := ParseSocketLink(link)
+	if !ok {
+		return link, nil, nil
+	}
+	if conn, ok := r.sockets[inode]; ok {
+		return link, conn, nil
+	}
+	conn, _ := FindConnection(inode)
+	// sockets of other families never have a connection table entry, and the remote end of a connected socket can't
+	// change, but an unconnected socket may be connected later
+	if conn == nil || conn.RemotePort != 0 {
+		r.sockets[inode] = conn
+	}
+	return link, conn, nil
+}
@@ -0,0 +1,124 @@
+package tracer
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/liamg/grace/tracer/procfs"
+	"golang.org/x/sys/unix"
+)
+
+// Process describes the traced task which made a syscall
+type Process struct {
+	Pid  int      // thread group id
+	Tid  int      // id of the traced thread
+	PPid int      // id of the parent process
+	Comm string   // contents of /proc/<tid>/comm
+	Exe  string   // target of /proc/<pid>/exe
+	Cwd  string   // target of /proc/<pid>/cwd
+	Args []string // contents of /proc/<pid>/cmdline
+}
+
+func readProcess(tid int) *Process {
+	proc := &Process{
+		Pid: tid,
+		Tid: tid,
+	}
+	if tgid, ppid, err := readStatus(tid); err == nil {
+		proc.Pid = tgid
+		proc.PPid = ppid
+	}
+	if comm, err := procfs.ReadFile(fmt.Sprintf("/proc/%d/comm", tid)); err == nil {
+		proc.Comm = strings.TrimSpace(string(comm))
+	}
+	if exe, err := procfs.Readlink(fmt.Sprintf("/proc/%d/exe", proc.Pid)); err == nil {
+		proc.Exe = exe
+	}
+	if cwd, err := procfs.Readlink(fmt.Sprintf("/proc/%d/cwd", proc.Pid)); err == nil {
+		proc.Cwd = cwd
+	}
+	proc.Args = CommandLine(proc.Pid)
+	return proc
+}
+
+// CommandLine returns the arguments of the given process, or nil if they cannot be read
+func CommandLine(pid int) []string {
+	cmdline, err := procfs.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+	if err != nil {
+		return nil
+	}
+	return parseCmdline(cmdline)
+}
+
+// readStatus reads the thread group id and parent process id of the given thread
+func readStatus(tid int) (int, int, error) {
+	status, err := procfs.ReadFile(fmt.Sprintf("/proc/%d/status", tid))
+	if err != nil {
+		return 0, 0, err
+	}
+	tgid, ppid := -1, 0
+	scanner := bufio.NewScanner(bytes.NewReader(status))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "Tgid:"):
+			if tgid, err = strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "Tgid:"))); err != nil {
+				return 0, 0, err
+			}
+		case strings.HasPrefix(line, "PPid:"):
+			if ppid, err = strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "PPid:"))); err != nil {
+				return 0, 0, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, 0, err
+	}
+	if tgid == -1 {
+		return 0, 0, fmt.Errorf("no tgid found for %d", tid)
+	}
+	return tgid, ppid, nil
+}
+
+// parseCmdline splits the NUL separated contents of /proc/<pid>/cmdline
+func parseCmdline(data []byte) []string {
+	var args []string
+	for len(data) > 0 {
+		end := bytes.IndexByte(data, 0)
+		if end == -1 {
+			return append(args, string(data))
+		}
+		args = append(args, string(data[:end]))
+		data = data[end+1:]
+	}
+	return args
+}
+
+// process returns the cached process information for the given tid, reading it from /proc if necessary
+func (t *Tracer) process(tid int) *Process {
+	if t.processes == nil {
+		t.processes = make(map[int]*Process)
+	}
+	if proc, ok := t.processes[tid]; ok {
+		return proc
+	}
+	proc := readProcess(tid)
+	t.processes[tid] = proc
+	return proc
+}
+
+// refreshProcess drops cached information for the given tid, e.g. after an execve or chdir
+func (t *Tracer) refreshProcess(tid int) {
+	delete(t.processes, tid)
+}
+
+func isExec(number int) bool {
+	return number == unix.SYS_EXECVE || number == unix.SYS_EXECVEAT
+}
+
+func isChdir(number int) bool {
+	return number == unix.SYS_CHDIR || number == unix.SYS_FCHDIR
+}
@@ -0,0 +1,37 @@
+package tracer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseCmdline(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect []string
+	}{
+		{input: "", expect: nil},
+		{input: "cat\x00", expect: []string{"cat"}},
+		{input: "sh\x00-c\x00echo hi\x00", expect: []string{"sh", "-c", "echo hi"}},
+		{input: "cc\x00\x00-c\x00", expect: []string{"cc", "", "-c"}},
+		{input: "renamed by setproctitle", expect: []string{"renamed by setproctitle"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			assert.Equal(t, test.expect, parseCmdline([]byte(test.input)))
+		})
+	}
+}
+
+func Test_ProcessIsForgottenOnExit(t *testing.T) {
+	tracer := New(100)
+	tracer.processes = map[int]*Process{
+		100: {Pid: 100, Tid: 100, Comm: "parent"},
+		101: {Pid: 100, Tid: 101, Comm: "parent"},
+	}
+	tracer.handleExit(&Exit{Pid: 101})
+	assert.NotContains(t, tracer.processes, 101)
+	assert.Contains(t, tracer.processes, 100)
+}
@@ -0,0 +1,73 @@
+package procfs
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sync"
+)
+
+// Source provides access to files under /proc. It can be replaced so that traces can be recorded and replayed.
+type Source interface {
+	Readlink(path string) (string, error)
+	ReadFile(path string) ([]byte, error)
+	// ReadAt reads from the given offset of a file, such as the target of a file descriptor link, without reading
+	// the whole file. Reaching the end of the file is not an error.
+	ReadAt(path string, out []byte, offset int64) (int, error)
+}
+
+type osSource struct{}
+
+func (osSource) Readlink(path string) (string, error) {
+	return os.Readlink(path)
+}
+
+func (osSource) ReadFile(path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
+
+func (osSource) ReadAt(path string, out []byte, offset int64) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = f.Close() }()
+	count, err := f.ReadAt(out, offset)
+	if errors.Is(err, io.EOF) {
+		err = nil
+	}
+	return count, err
+}
+
+// OS is the default source, which reads from the real /proc filesystem
+var OS Source = osSource{}
+
+var (
+	current      = OS
+	currentMutex sync.RWMutex
+)
+
+// SetSource replaces the source used for all /proc access
+func SetSource(source Source) {
+	currentMutex.Lock()
+	defer currentMutex.Unlock()
+	current = source
+}
+
+func getSource() Source {
+	currentMutex.RLock()
+	defer currentMutex.RUnlock()
+	return current
+}
+
+func Readlink(path string) (string, error) {
+	return getSource().Readlink(path)
+}
+
+func ReadFile(path string) ([]byte, error) {
+	return getSource().ReadFile(path)
+}
+
+func ReadAt(path string, out []byte, offset int64) (int, error) {
+	return getSource().ReadAt(path, out, offset)
+}
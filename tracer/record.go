@@ -0,0 +1,218 @@
+package tracer
+
+import (
+	"bufio"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"syscall"
+	"time"
+
+	"github.com/liamg/grace/tracer/procfs"
+)
+
+// RecordingVersion is incremented whenever a breaking change is made to the recording format
+const RecordingVersion = 1
+
+// recordingMagic prefixes every recording, so we can give a useful error when trying to replay something else
+const recordingMagic = "GRACE-TRACE\n"
+
+// Header holds metadata about a recording
+type Header struct {
+	Version int
+	Kernel  string
+	Arch    string
+	Command []string
+	Pid     int
+	Started time.Time
+}
+
+type EventType int
+
+const (
+	EventAttach EventType = iota
+	EventDetach
+	EventSyscall
+	EventSignal
+	EventProcessExit
+)
+
+// Event is a single recorded ptrace stop, along with everything that was read from the tracee whilst handling it
+type Event struct {
+	Type   EventType
+	Time   time.Time
+	Pid    int
+	Regs   *syscall.PtraceRegs
+	Signal *SigInfo
+	Status int
+	// Tgid is the process of the thread which exited, for EventProcessExit
+	Tgid int
+	// ExitSignal is the signal which killed the thread, for EventProcessExit
+	ExitSignal int
+	Memory     []MemoryRegion
+	Files      []ProcFile // changes to /proc since the previous event
+}
+
+// MemoryRegion is a contiguous block of tracee memory which was read during decoding
+type MemoryRegion struct {
+	Pid  int
+	Addr uintptr
+	Data []byte
+}
+
+// ProcFile is the result of reading a file or link under /proc
+type ProcFile struct {
+	Path    string
+	Link    bool
+	Data    []byte
+	Missing bool
+	// Ranged is set when only part of the file was read, starting at Offset
+	Ranged bool
+	Offset int64
+}
+
+func (f ProcFile) key() string {
+	switch {
+	case f.Link:
+		return "link:" + f.Path
+	case f.Ranged:
+		return fmt.Sprintf("range:%s@%d", f.Path, f.Offset)
+	default:
+		return "file:" + f.Path
+	}
+}
+
+type recorder struct {
+	w       *bufio.Writer
+	enc     *gob.Encoder
+	memory  []MemoryRegion
+	files   []ProcFile
+	known   map[string]ProcFile
+	err     error
+	written int
+}
+
+// Record writes every event seen by the tracer to the given writer, so it can be replayed later with Replay.
+// Handlers are still called as normal whilst recording.
+func (t *Tracer) Record(w io.Writer, command []string) error {
+	r := &recorder{
+		w:     bufio.NewWriter(w),
+		known: make(map[string]ProcFile),
+	}
+	if _, err := r.w.WriteString(recordingMagic); err != nil {
+		return err
+	}
+	r.enc = gob.NewEncoder(r.w)
+	header := Header{
+		Version: RecordingVersion,
+		Kernel:  kernelRelease(),
+		Arch:    runtime.GOARCH,
+		Command: command,
+		Pid:     t.pid,
+		Started: time.Now(),
+	}
+	if err := r.enc.Encode(header); err != nil {
+		return err
+	}
+	t.recorder = r
+	peekData = r.peekData
+	procfs.SetSource(r)
+	return nil
+}
+
+// StopRecording flushes the recording, returning the number of events written
+func (t *Tracer) StopRecording() (int, error) {
+	r := t.recorder
+	if r == nil {
+		return 0, nil
+	}
+	t.recorder = nil
+	peekData = syscall.PtracePeekData
+	procfs.SetSource(procfs.OS)
+	if r.err != nil {
+		return r.written, r.err
+	}
+	return r.written, r.w.Flush()
+}
+
+func (r *recorder) write(event Event) {
+	if r.err != nil {
+		return
+	}
+	event.Memory = r.memory
+	event.Files = r.files
+	r.memory = nil
+	r.files = nil
+	if err := r.enc.Encode(event); err != nil {
+		r.err = fmt.Errorf("failed to write event: %w", err)
+		return
+	}
+	r.written++
+}
+
+func (r *recorder) peekData(pid int, addr uintptr, out []byte) (int, error) {
+	count, err := syscall.PtracePeekData(pid, addr, out)
+	if count > 0 {
+		r.captureMemory(pid, addr, out[:count])
+	}
+	return count, err
+}
+
+func (r *recorder) captureMemory(pid int, addr uintptr, data []byte) {
+	// strings are read a byte at a time, so merge contiguous reads into a single region
+	if len(r.memory) > 0 {
+		last := &r.memory[len(r.memory)-1]
+		if last.Pid == pid && last.Addr+uintptr(len(last.Data)) == addr {
+			last.Data = append(last.Data, data...)
+			return
+		}
+	}
+	r.memory = append(r.memory, MemoryRegion{
+		Pid:  pid,
+		Addr: addr,
+		Data: append([]byte{}, data...),
+	})
+}
+
+func (r *recorder) Readlink(path string) (string, error) {
+	link, err := procfs.OS.Readlink(path)
+	r.captureFile(ProcFile{Path: path, Link: true, Data: []byte(link), Missing: err != nil})
+	return link, err
+}
+
+func (r *recorder) ReadFile(path string) ([]byte, error) {
+	data, err := procfs.OS.ReadFile(path)
+	r.captureFile(ProcFile{Path: path, Data: data, Missing: err != nil})
+	return data, err
+}
+
+func (r *recorder) ReadAt(path string, out []byte, offset int64) (int, error) {
+	count, err := procfs.OS.ReadAt(path, out, offset)
+	r.captureFile(ProcFile{Path: path, Ranged: true, Offset: offset, Data: append([]byte{}, out[:count]...), Missing: err != nil})
+	return count, err
+}
+
+func (r *recorder) captureFile(file ProcFile) {
+	if known, ok := r.known[file.key()]; ok && known.Missing == file.Missing && string(known.Data) == string(file.Data) {
+		return
+	}
+	r.known[file.key()] = file
+	r.files = append(r.files, file)
+}
+
+func kernelRelease() string {
+	data, err := os.ReadFile("/proc/sys/kernel/osrelease")
+	if err != nil {
+		return ""
+	}
+	return string(trimNewline(data))
+}
+
+func trimNewline(data []byte) []byte {
+	for len(data) > 0 && (data[len(data)-1] == '\n' || data[len(data)-1] == 0) {
+		data = data[:len(data)-1]
+	}
+	return data
+}
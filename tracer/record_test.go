@@ -0,0 +1,87 @@
+package tracer
+
+import (
+	"bytes"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RecordedMemoryIsMerged(t *testing.T) {
+	r := &recorder{}
+	r.captureMemory(1, 0x1000, []byte("ab"))
+	r.captureMemory(1, 0x1002, []byte("c"))
+	r.captureMemory(1, 0x2000, []byte("x"))
+	r.captureMemory(2, 0x2001, []byte("y"))
+	require.Len(t, r.memory, 3)
+	assert.Equal(t, []byte("abc"), r.memory[0].Data)
+}
+
+func Test_ReplayedMemory(t *testing.T) {
+	p := &player{
+		memory: []MemoryRegion{
+			{Pid: 1, Addr: 0x1000, Data: []byte("hello world")},
+		},
+	}
+
+	out := make([]byte, 5)
+	count, err := p.peekData(1, 0x1006, out)
+	require.NoError(t, err)
+	assert.Equal(t, 5, count)
+	assert.Equal(t, []byte("world"), out)
+
+	_, err = p.peekData(1, 0x1008, out)
+	assert.ErrorIs(t, err, syscall.EIO)
+
+	_, err = p.peekData(2, 0x1000, out)
+	assert.ErrorIs(t, err, syscall.EIO)
+}
+
+func Test_RecordingRoundTrip(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	tracer := New(123)
+	require.NoError(t, tracer.Record(buffer, []string{"cat", "/etc/passwd"}))
+	tracer.recorder.captureFile(ProcFile{Path: "/proc/123/comm", Data: []byte("cat\n")})
+	tracer.recorder.captureFile(ProcFile{Path: "/proc/123/comm", Data: []byte("cat\n")})
+	tracer.handleAttach(123)
+	tracer.handleExit(&Exit{Pid: 124, Signal: syscall.SIGKILL})
+	tracer.handleExit(&Exit{Pid: 123, Status: 7})
+	count, err := tracer.StopRecording()
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	replayed, header, err := Replay(buffer)
+	require.NoError(t, err)
+	assert.Equal(t, 123, header.Pid)
+	assert.Equal(t, []string{"cat", "/etc/passwd"}, header.Command)
+
+	var attached, status int
+	var exits []Exit
+	replayed.SetAttachHandler(func(pid int) { attached = pid })
+	replayed.SetProcessExitHandler(func(s int) { status = s })
+	replayed.SetExitHandler(func(exit *Exit) { exits = append(exits, *exit) })
+	require.NoError(t, replayed.Start())
+	assert.Equal(t, 123, attached)
+	assert.Equal(t, 7, status)
+	require.Len(t, exits, 2)
+	assert.Equal(t, syscall.SIGKILL, exits[0].Signal)
+	assert.Equal(t, 124, exits[0].Pid)
+	assert.Len(t, replayed.player.files, 1)
+}
+
+func Test_ReplayedRangedReads(t *testing.T) {
+	p := &player{files: make(map[string]ProcFile)}
+	file := ProcFile{Path: "/proc/1/fd/4", Ranged: true, Offset: 6, Data: []byte("world")}
+	p.files[file.key()] = file
+
+	out := make([]byte, 5)
+	count, err := p.ReadAt("/proc/1/fd/4", out, 6)
+	require.NoError(t, err)
+	assert.Equal(t, 5, count)
+	assert.Equal(t, []byte("world"), out)
+
+	_, err = p.ReadAt("/proc/1/fd/4", out, 0)
+	assert.Error(t, err)
+}
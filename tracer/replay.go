@@ -0,0 +1,134 @@
+package tracer
+
+import (
+	"bufio"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"syscall"
+
+	"github.com/liamg/grace/tracer/procfs"
+)
+
+type player struct {
+	dec    *gob.Decoder
+	memory []MemoryRegion
+	files  map[string]ProcFile
+}
+
+// Replay creates a tracer which replays a recording created with Record. Events are decoded in the same way as
+// they are during a live trace, and passed to the same handlers.
+func Replay(r io.Reader) (*Tracer, *Header, error) {
+	reader := bufio.NewReader(r)
+	magic := make([]byte, len(recordingMagic))
+	if _, err := io.ReadFull(reader, magic); err != nil || string(magic) != recordingMagic {
+		return nil, nil, fmt.Errorf("not a grace recording")
+	}
+	dec := gob.NewDecoder(reader)
+	var header Header
+	if err := dec.Decode(&header); err != nil {
+		return nil, nil, fmt.Errorf("failed to read recording header: %w", err)
+	}
+	if header.Version != RecordingVersion {
+		return nil, nil, fmt.Errorf("unsupported recording version %d (expected %d)", header.Version, RecordingVersion)
+	}
+	if header.Arch != runtime.GOARCH {
+		return nil, nil, fmt.Errorf("recording was made on %s and cannot be replayed on %s", header.Arch, runtime.GOARCH)
+	}
+	return &Tracer{
+		pid: header.Pid,
+		player: &player{
+			dec:   dec,
+			files: make(map[string]ProcFile),
+		},
+	}, &header, nil
+}
+
+func (p *player) run(t *Tracer) error {
+	peekData = p.peekData
+	procfs.SetSource(p)
+	defer func() {
+		peekData = syscall.PtracePeekData
+		procfs.SetSource(procfs.OS)
+	}()
+
+	for {
+		var event Event
+		if err := p.dec.Decode(&event); err != nil {
+			if err == io.EOF || errors.Is(err, io.ErrUnexpectedEOF) {
+				// recordings of processes which were killed may be truncated
+				return nil
+			}
+			return fmt.Errorf("failed to read event: %w", err)
+		}
+		p.memory = event.Memory
+		for _, file := range event.Files {
+			p.files[file.key()] = file
+		}
+		switch event.Type {
+		case EventAttach:
+			t.handleAttach(event.Pid)
+		case EventDetach:
+			t.handleDetach(event.Pid)
+		case EventSignal:
+			if event.Signal != nil {
+				t.handleSignal(event.Pid, event.Signal)
+			}
+		case EventProcessExit:
+			t.handleExit(&Exit{
+				Pid:    event.Pid,
+				Tgid:   event.Tgid,
+				Status: event.Status,
+				Signal: syscall.Signal(event.ExitSignal),
+				Time:   event.Time,
+			})
+		case EventSyscall:
+			if event.Regs == nil {
+				return fmt.Errorf("syscall event is missing registers")
+			}
+			if err := t.handleSyscall(event.Pid, event.Regs, event.Time); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unknown event type %d", event.Type)
+		}
+	}
+}
+
+func (p *player) peekData(pid int, addr uintptr, out []byte) (int, error) {
+	end := addr + uintptr(len(out))
+	for _, region := range p.memory {
+		if region.Pid != pid || addr < region.Addr || end > region.Addr+uintptr(len(region.Data)) {
+			continue
+		}
+		return copy(out, region.Data[addr-region.Addr:]), nil
+	}
+	return 0, syscall.EIO
+}
+
+func (p *player) Readlink(path string) (string, error) {
+	file, ok := p.files[ProcFile{Path: path, Link: true}.key()]
+	if !ok || file.Missing {
+		return "", &os.PathError{Op: "readlink", Path: path, Err: os.ErrNotExist}
+	}
+	return string(file.Data), nil
+}
+
+func (p *player) ReadFile(path string) ([]byte, error) {
+	file, ok := p.files[ProcFile{Path: path}.key()]
+	if !ok || file.Missing {
+		return nil, &os.PathError{Op: "open", Path: path, Err: os.ErrNotExist}
+	}
+	return file.Data, nil
+}
+
+func (p *player) ReadAt(path string, out []byte, offset int64) (int, error) {
+	file, ok := p.files[ProcFile{Path: path, Ranged: true, Offset: offset}.key()]
+	if !ok || file.Missing {
+		return 0, &os.PathError{Op: "open", Path: path, Err: os.ErrNotExist}
+	}
+	return copy(out, file.Data), nil
+}
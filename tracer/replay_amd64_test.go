@@ -0,0 +1,101 @@
+//go:build amd64
+
+package tracer
+
+import (
+	"bytes"
+	"encoding/gob"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+// writeRecording creates a recording containing the given events, as Record would
+func writeRecording(t *testing.T, pid int, events []Event) *bytes.Buffer {
+	buffer := bytes.NewBufferString(recordingMagic)
+	enc := gob.NewEncoder(buffer)
+	require.NoError(t, enc.Encode(Header{
+		Version: RecordingVersion,
+		Arch:    runtime.GOARCH,
+		Pid:     pid,
+	}))
+	for _, event := range events {
+		require.NoError(t, enc.Encode(event))
+	}
+	return buffer
+}
+
+func Test_ReplayDecodesSyscalls(t *testing.T) {
+	started := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	openat := &syscall.PtraceRegs{
+		Orig_rax: unix.SYS_OPENAT,
+		Rdi:      uint64(0xffffffffffffff9c), // AT_FDCWD
+		Rsi:      0x1000,
+		Rdx:      unix.O_RDONLY | unix.O_CLOEXEC,
+	}
+	openatExit := *openat
+	openatExit.Rax = 3
+	read := &syscall.PtraceRegs{
+		Orig_rax: unix.SYS_READ,
+		Rdi:      3,
+		Rsi:      0x2000,
+		Rdx:      4096,
+	}
+	readExit := *read
+	readExit.Rax = 5
+
+	recording := writeRecording(t, 123, []Event{
+		{Type: EventAttach, Pid: 123},
+		{
+			Type:   EventSyscall,
+			Time:   started,
+			Pid:    123,
+			Regs:   openat,
+			Memory: []MemoryRegion{{Pid: 123, Addr: 0x1000, Data: []byte("/etc/passwd\x00")}},
+		},
+		{Type: EventSyscall, Time: started.Add(time.Millisecond), Pid: 123, Regs: &openatExit},
+		{
+			Type:  EventSyscall,
+			Time:  started.Add(2 * time.Millisecond),
+			Pid:   123,
+			Regs:  read,
+			Files: []ProcFile{{Path: "/proc/123/fd/3", Link: true, Data: []byte("/etc/passwd")}},
+		},
+		{
+			Type:   EventSyscall,
+			Time:   started.Add(3 * time.Millisecond),
+			Pid:    123,
+			Regs:   &readExit,
+			Memory: []MemoryRegion{{Pid: 123, Addr: 0x2000, Data: []byte("root:")}},
+		},
+	})
+
+	replayed, _, err := Replay(recording)
+	require.NoError(t, err)
+	var calls []*Syscall
+	replayed.SetSyscallExitHandler(func(call *Syscall) { calls = append(calls, call) })
+	require.NoError(t, replayed.Start())
+	require.Len(t, calls, 2)
+
+	open := calls[0]
+	assert.Equal(t, "openat", open.Name())
+	require.Len(t, open.Args(), 3)
+	assert.Equal(t, "AT_FDCWD", open.Args()[0].Annotation())
+	assert.Equal(t, []byte("/etc/passwd"), open.Args()[1].Data())
+	assert.Equal(t, 3, open.Return().Int())
+	assert.Equal(t, []string{"/etc/passwd"}, open.Paths())
+	assert.Equal(t, time.Millisecond, open.Duration())
+
+	readCall := calls[1]
+	assert.Equal(t, "read", readCall.Name())
+	require.Len(t, readCall.Args(), 3)
+	assert.Equal(t, 3, readCall.Args()[0].Int())
+	assert.Equal(t, []byte("root:"), readCall.Args()[1].Data())
+	assert.Equal(t, 5, readCall.Return().Int())
+	assert.Equal(t, []string{"/etc/passwd"}, readCall.Paths())
+}
@@ -8,6 +8,7 @@ import (
 	"golang.org/x/sys/unix"
 )
 
+// SigInfo holds the leading fields of a siginfo_t
 type SigInfo struct {
 	Signo  int32
 	Errno  int32
@@ -17,11 +18,15 @@ type SigInfo struct {
 	Uid    int32
 }
 
+// siginfoSize is the size of siginfo_t, which the kernel always writes in full
+const siginfoSize = 128
+
 func getSignalInfo(pid int) (*SigInfo, error) {
-	var info SigInfo
-	_, _, e1 := syscall.Syscall6(syscall.SYS_PTRACE, uintptr(unix.PTRACE_GETSIGINFO), uintptr(pid), 0, uintptr(unsafe.Pointer(&info)), 0, 0)
+	var raw [siginfoSize]byte
+	_, _, e1 := syscall.Syscall6(syscall.SYS_PTRACE, uintptr(unix.PTRACE_GETSIGINFO), uintptr(pid), 0, uintptr(unsafe.Pointer(&raw[0])), 0, 0)
 	if e1 != 0 {
 		return nil, fmt.Errorf("ptrace get signal info failed: %v", e1)
 	}
+	info := *(*SigInfo)(unsafe.Pointer(&raw[0]))
 	return &info, nil
 }
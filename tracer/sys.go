@@ -2,12 +2,15 @@ package tracer
 
 import (
 	"fmt"
-	"os"
 	"strings"
+	"time"
+
+	"github.com/liamg/grace/tracer/procfs"
 )
 
 type Syscall struct {
 	pid      int
+	process  *Process
 	number   int
 	rawArgs  [6]uintptr
 	args     []Arg
@@ -16,6 +19,8 @@ type Syscall struct {
 	unknown  bool
 	paths    []string
 	complete bool
+	entered  time.Time
+	exited   time.Time
 }
 
 type SyscallMetadata struct {
@@ -29,6 +34,45 @@ func (s *Syscall) Number() int {
 	return s.number
 }
 
+// Pid returns the id of the process (thread group) which made the syscall
+func (s *Syscall) Pid() int {
+	if s.process == nil {
+		return s.pid
+	}
+	return s.process.Pid
+}
+
+// Tid returns the id of the thread which made the syscall
+func (s *Syscall) Tid() int {
+	return s.pid
+}
+
+// Process returns information about the process which made the syscall
+func (s *Syscall) Process() Process {
+	if s.process == nil {
+		return Process{Pid: s.pid, Tid: s.pid}
+	}
+	return *s.process
+}
+
+// EnterTime returns the time at which the syscall was entered
+func (s *Syscall) EnterTime() time.Time {
+	return s.entered
+}
+
+// ExitTime returns the time at which the syscall exited, or the zero time if it has not exited yet
+func (s *Syscall) ExitTime() time.Time {
+	return s.exited
+}
+
+// Duration returns the time spent in the syscall, which is only known once it has exited
+func (s *Syscall) Duration() time.Duration {
+	if s.exited.IsZero() || s.entered.IsZero() {
+		return 0
+	}
+	return s.exited.Sub(s.entered)
+}
+
 func (s *Syscall) Paths() []string {
 	return s.paths
 }
@@ -49,6 +93,18 @@ func (s *Syscall) Return() Arg {
 	return s.ret
 }
 
+// maxErrno is the largest error number the kernel returns. Any return value from -maxErrno to -1 is an error, including
+// from syscalls which otherwise return addresses (such as mmap).
+const maxErrno = 4095
+
+// Errno returns the error number if the syscall failed, or zero otherwise
+func (s *Syscall) Errno() int {
+	if ret := s.ret.Int(); ret < 0 && ret >= -maxErrno {
+		return -ret
+	}
+	return 0
+}
+
 func (s *Syscall) Unknown() bool {
 	return s.unknown
 }
@@ -101,7 +157,7 @@ func (s *Syscall) populate(exit bool) error {
 		if argMeta.Type == argTypeString && (strings.Contains(argMeta.Name, "path") || strings.Contains(argMeta.Name, "file")) {
 			s.paths = append(s.paths, string(arg.Data()))
 		} else if argMeta.Type == ArgTypeInt && strings.Contains(argMeta.Name, "fd") {
-			if path, err := os.Readlink(fmt.Sprintf("/proc/%d/fd/%d", s.pid, arg.Raw())); err == nil {
+			if path, err := procfs.Readlink(fmt.Sprintf("/proc/%d/fd/%d", s.pid, arg.Raw())); err == nil {
 				s.paths = append(s.paths, path)
 			}
 		}
@@ -439,8 +439,9 @@ var (
 					Annotator: annotation.AnnotateFd,
 				},
 				{
-					Name: "iov",
-					Type: argTypeIovecArray,
+					Name:        "iov",
+					Type:        argTypeIovecArray,
+					Destination: true,
 				},
 				{
 					Name: "iovcnt",
@@ -813,7 +814,7 @@ var (
 				},
 				{
 					Name: "offset",
-					Type: ArgTypeUnsignedInt,
+					Type: argTypeUnsignedInt64Ptr,
 				},
 				{
 					Name: "count",
@@ -913,6 +914,16 @@ var (
 					Type:      ArgTypeInt,
 					Annotator: annotation.AnnotateMsgFlags,
 				},
+				{
+					Name:     "addr",
+					Type:     argTypeSockaddr,
+					Optional: true,
+				},
+				{
+					Name:     "addrlen",
+					Type:     ArgTypeInt,
+					Optional: true,
+				},
 			},
 		},
 		unix.SYS_RECVFROM: {
@@ -39,3 +39,46 @@ func checkSyscall(t *testing.T, number int, meta SyscallMetadata) {
 		}
 	}
 }
+
+func Test_SyscallErrno(t *testing.T) {
+	errnoRaw := func(errno int) uintptr {
+		return uintptr(-errno)
+	}
+	tests := []struct {
+		name string
+		ret  Arg
+		want int
+	}{
+		{
+			name: "error code",
+			ret:  Arg{t: ArgTypeErrorCode, raw: errnoRaw(2)},
+			want: 2,
+		},
+		{
+			name: "address returned on failure",
+			ret:  Arg{t: ArgTypeAddress, raw: errnoRaw(12)},
+			want: 12,
+		},
+		{
+			name: "largest error number",
+			ret:  Arg{t: ArgTypeAddress, raw: errnoRaw(4095)},
+			want: 4095,
+		},
+		{
+			name: "high address",
+			ret:  Arg{t: ArgTypeAddress, raw: errnoRaw(4096)},
+			want: 0,
+		},
+		{
+			name: "success",
+			ret:  Arg{t: ArgTypeInt, raw: 3},
+			want: 0,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			call := &Syscall{ret: test.ret}
+			assert.Equal(t, test.want, call.Errno())
+		})
+	}
+}
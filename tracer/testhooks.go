@@ -0,0 +1,49 @@
+package tracer
+
+import (
+	"time"
+
+	"github.com/liamg/grace/internal/testhooks"
+)
+
+func init() {
+	testhooks.NewSyscall = func(number int, process Process, args []Arg, ret *Arg, paths []string, complete bool, entered time.Time, exited time.Time) *Syscall {
+		call := &Syscall{
+			pid:      process.Tid,
+			process:  &process,
+			number:   number,
+			args:     args,
+			paths:    paths,
+			complete: complete,
+			entered:  entered,
+			exited:   exited,
+		}
+		if ret != nil {
+			call.ret = *ret
+			call.rawRet = ret.raw
+		}
+		return call
+	}
+	testhooks.NewArg = func(name string, t ArgType, raw uintptr, data []byte, annotation string, array []Arg, obj *Object) Arg {
+		return Arg{
+			name:       name,
+			t:          t,
+			raw:        raw,
+			data:       data,
+			annotation: annotation,
+			replace:    annotation != "",
+			bitSize:    bitSize,
+			obj:        obj,
+			array:      array,
+			known:      true,
+		}
+	}
+	testhooks.SyscallNumber = func(name string) (int, bool) {
+		for number, meta := range sysMap {
+			if meta.Name == name {
+				return number, true
+			}
+		}
+		return 0, false
+	}
+}
@@ -6,24 +6,52 @@ import (
 	"os/exec"
 	"os/signal"
 	"runtime"
+	"strconv"
+	"sync/atomic"
 	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
 )
 
 type Tracer struct {
 	handlers struct {
-		syscallExit  func(*Syscall)
-		syscallEnter func(*Syscall)
-		signal       func(*SigInfo)
-		processExit  func(int)
-		attach       func(int)
-		detach       func(int)
+		syscallExit  []func(*Syscall)
+		syscallEnter []func(*Syscall)
+		signal       []func(int, *SigInfo)
+		processExit  []func(int)
+		exit         []func(*Exit)
+		attach       []func(int)
+		detach       []func(int)
 	}
 	pid            int
 	cmd            *exec.Cmd
-	isExit         bool
-	lastCall       *Syscall
-	lastSignal     int
+	followForks    bool
+	tracees        map[int]*tracee
 	receivedSignal syscall.Signal
+	killed         bool  // true once the tracees have been killed after an interrupt
+	stopped        int32 // set atomically once Stop has been called or tracing has finished
+	processes      map[int]*Process
+	recorder       *recorder
+	player         *player
+}
+
+// tracee holds the state of a single traced thread
+type tracee struct {
+	isExit   bool
+	lastCall *Syscall
+	started  bool // false until a new child has reported its initial SIGSTOP
+	tgid     int  // id of the process the thread belongs to, or 0 if it is not known
+	stopped  bool // true if the thread was left in a ptrace-stop when tracing finished
+}
+
+// Exit describes how a traced thread finished
+type Exit struct {
+	Pid    int            // id of the thread which exited - this is the thread group id for the main thread of a process
+	Tgid   int            // id of the process the thread belonged to, or 0 if it is not known
+	Status int            // exit status, if the thread exited normally
+	Signal syscall.Signal // the signal which killed the thread, or 0 if it exited normally
+	Time   time.Time
 }
 
 func New(pid int) *Tracer {
@@ -33,18 +61,25 @@ func New(pid int) *Tracer {
 }
 
 func FromCommand(suppressOutput bool, command string, args ...string) (*Tracer, error) {
-
-	runtime.LockOSThread()
-
 	cmd := exec.Command(command, args...)
 	cmd.Stdin = os.Stdin
 	if !suppressOutput {
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
 	}
-	cmd.SysProcAttr = &syscall.SysProcAttr{
-		Ptrace: true,
+	return FromCmd(cmd)
+}
+
+// FromCmd starts the given command so that it is traced from the start. Its input and output are left as the caller
+// configured them.
+func FromCmd(cmd *exec.Cmd) (*Tracer, error) {
+
+	runtime.LockOSThread()
+
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
 	}
+	cmd.SysProcAttr.Ptrace = true
 	if err := cmd.Start(); err != nil {
 		return nil, err
 	}
@@ -54,192 +89,492 @@ func FromCommand(suppressOutput bool, command string, args ...string) (*Tracer,
 	}, nil
 }
 
+// SetFollowForks enables tracing of child processes and threads created with clone, fork and vfork
+func (t *Tracer) SetFollowForks(follow bool) {
+	t.followForks = follow
+}
+
+// SetSyscallExitHandler sets a handler which is called when a syscall exits, replacing any handlers which were
+// already set or added
 func (t *Tracer) SetSyscallExitHandler(handler func(*Syscall)) {
-	t.handlers.syscallExit = handler
+	t.handlers.syscallExit = []func(*Syscall){handler}
 }
 
+// AddSyscallExitHandler adds a handler which is called when a syscall exits, after any handlers which were already
+// set or added
+func (t *Tracer) AddSyscallExitHandler(handler func(*Syscall)) {
+	t.handlers.syscallExit = append(t.handlers.syscallExit, handler)
+}
+
+// SetSyscallEnterHandler sets a handler which is called when a syscall is entered, replacing any handlers which were
+// already set or added
 func (t *Tracer) SetSyscallEnterHandler(handler func(*Syscall)) {
-	t.handlers.syscallEnter = handler
+	t.handlers.syscallEnter = []func(*Syscall){handler}
+}
+
+// AddSyscallEnterHandler adds a handler which is called when a syscall is entered, after any handlers which were
+// already set or added
+func (t *Tracer) AddSyscallEnterHandler(handler func(*Syscall)) {
+	t.handlers.syscallEnter = append(t.handlers.syscallEnter, handler)
 }
 
-func (t *Tracer) SetSignalHandler(handler func(*SigInfo)) {
-	t.handlers.signal = handler
+// SetSignalHandler sets a handler which is called with the id of the thread which received a signal, and details of
+// the signal
+func (t *Tracer) SetSignalHandler(handler func(int, *SigInfo)) {
+	t.handlers.signal = []func(int, *SigInfo){handler}
 }
 
+// AddSignalHandler adds a handler which is called when a thread receives a signal, after any handlers which were
+// already set or added
+func (t *Tracer) AddSignalHandler(handler func(int, *SigInfo)) {
+	t.handlers.signal = append(t.handlers.signal, handler)
+}
+
+// SetProcessExitHandler sets a handler which is called with the exit status of the traced process
 func (t *Tracer) SetProcessExitHandler(handler func(int)) {
-	t.handlers.processExit = handler
+	t.handlers.processExit = []func(int){handler}
+}
+
+// AddProcessExitHandler adds a handler which is called with the exit status of the traced process, after any handlers
+// which were already set or added
+func (t *Tracer) AddProcessExitHandler(handler func(int)) {
+	t.handlers.processExit = append(t.handlers.processExit, handler)
+}
+
+// SetExitHandler sets a handler which is called whenever any traced thread exits or is killed
+func (t *Tracer) SetExitHandler(handler func(*Exit)) {
+	t.handlers.exit = []func(*Exit){handler}
+}
+
+// AddExitHandler adds a handler which is called whenever any traced thread exits or is killed, after any handlers
+// which were already set or added
+func (t *Tracer) AddExitHandler(handler func(*Exit)) {
+	t.handlers.exit = append(t.handlers.exit, handler)
 }
 
+// SetAttachHandler sets a handler which is called when the tracer attaches, replacing any handlers which were already
+// set or added
 func (t *Tracer) SetAttachHandler(handler func(int)) {
-	t.handlers.attach = handler
+	t.handlers.attach = []func(int){handler}
+}
+
+// AddAttachHandler adds a handler which is called when the tracer attaches, after any handlers which were already set
+// or added
+func (t *Tracer) AddAttachHandler(handler func(int)) {
+	t.handlers.attach = append(t.handlers.attach, handler)
 }
 
+// SetDetachHandler sets a handler which is called when the tracer detaches, replacing any handlers which were already
+// set or added
 func (t *Tracer) SetDetachHandler(handler func(int)) {
-	t.handlers.detach = handler
+	t.handlers.detach = []func(int){handler}
+}
+
+// AddDetachHandler adds a handler which is called when the tracer detaches, after any handlers which were already set
+// or added
+func (t *Tracer) AddDetachHandler(handler func(int)) {
+	t.handlers.detach = append(t.handlers.detach, handler)
 }
 
 func (t *Tracer) Start() error {
 
+	if t.player != nil {
+		return t.player.run(t)
+	}
+
 	runtime.LockOSThread()
 
+	// the process may be reaped once tracing has finished, so it must not be signalled by Stop
+	defer atomic.StoreInt32(&t.stopped, 1)
+
 	if _, err := os.FindProcess(t.pid); err != nil {
 		return fmt.Errorf("could not find process with pid %d: %w", t.pid, err)
 	}
 
+	tids := []int{t.pid}
 	if t.cmd == nil {
-		if err := syscall.PtraceAttach(t.pid); err == syscall.EPERM {
-			return fmt.Errorf("could not attach to process with pid %d: %w - check your permissions", t.pid, err)
-		} else if err != nil {
-			return err
+		if t.followForks {
+			tids = listThreads(t.pid)
+		}
+		for i, tid := range tids {
+			if err := syscall.PtraceAttach(tid); err == syscall.EPERM {
+				return fmt.Errorf("could not attach to process with pid %d: %w - check your permissions", t.pid, err)
+			} else if err != nil {
+				if i > 0 && err == syscall.ESRCH {
+					// the thread exited before we could attach to it
+					tids[i] = 0
+					continue
+				}
+				return err
+			}
 		}
-
 	}
 
-	if t.handlers.attach != nil {
-		t.handlers.attach(t.pid)
-	}
+	t.handleAttach(t.pid)
 
-	status := syscall.WaitStatus(0)
-	if _, err := syscall.Wait4(t.pid, &status, 0, nil); err != nil {
-		return err
+	t.tracees = make(map[int]*tracee)
+	for _, tid := range tids {
+		if tid == 0 {
+			continue
+		}
+		status := syscall.WaitStatus(0)
+		if _, err := syscall.Wait4(tid, &status, syscall.WALL, nil); err != nil {
+			return err
+		}
+		t.tracees[tid] = &tracee{started: true, tgid: t.pid}
 	}
 
-	defer func() {
-		if t.handlers.detach != nil {
-			t.handlers.detach(t.pid)
-		}
-	}()
+	defer t.handleDetach(t.pid)
 
 	if t.cmd == nil {
-		defer func() {
-			_ = syscall.PtraceDetach(t.pid)
-			_, _ = syscall.Wait4(t.pid, &status, 0, nil)
-
-		}()
+		defer t.detachAll()
 	}
 
-	// deliver SIGTRAP|0x80
-	if err := syscall.PtraceSetOptions(t.pid, syscall.PTRACE_O_TRACESYSGOOD); err != nil {
-		return err
+	// deliver SIGTRAP|0x80 for syscall stops, and report execs as events rather than SIGTRAP
+	options := syscall.PTRACE_O_TRACESYSGOOD | syscall.PTRACE_O_TRACEEXEC
+	if t.followForks {
+		options |= syscall.PTRACE_O_TRACECLONE | syscall.PTRACE_O_TRACEFORK | syscall.PTRACE_O_TRACEVFORK
+	}
+	for tid := range t.tracees {
+		if err := syscall.PtraceSetOptions(tid, options); err != nil {
+			return err
+		}
 	}
 
 	signalChan := make(chan os.Signal, 1)
 	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGHUP, syscall.SIGTERM, syscall.SIGPIPE, syscall.SIGQUIT)
 	go func() {
 		for sig := range signalChan {
-			t.receivedSignal = sig.(syscall.Signal)
-			_ = syscall.Kill(t.pid, syscall.SIGSTOP)
+			t.interrupt(sig.(syscall.Signal))
 		}
 	}()
 
+	for tid := range t.tracees {
+		if err := syscall.PtraceSyscall(tid, 0); err != nil {
+			return fmt.Errorf("could not intercept syscall: %w", err)
+		}
+	}
+
 	return t.loop()
 }
 
-var errExited = fmt.Errorf("process exited")
-
 func (t *Tracer) loop() error {
-	for {
-		if err := t.waitForSyscall(); err != nil {
-			if err == errExited {
-				return nil
-			}
+	for len(t.tracees) > 0 {
+		status := syscall.WaitStatus(0)
+		tid, err := syscall.Wait4(-1, &status, syscall.WALL, nil)
+		if err == syscall.EINTR {
+			continue
+		}
+		if err == syscall.ECHILD {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("wait failed: %w", err)
+		}
+		if err := t.handleStop(tid, status); err != nil {
 			return err
 		}
 		if t.receivedSignal > 0 {
-			break
+			if t.cmd == nil {
+				break
+			}
+			if !t.killed {
+				// we started the command, so it's killed rather than being left stopped without a tracer
+				t.killAll()
+			}
 		}
 	}
 	return nil
 }
 
-func (t *Tracer) waitForSyscall() error {
+// Stop ends the trace from another goroutine, in the same way as an interrupt. A command started by the tracer is
+// killed, along with any children being traced, and processes which were attached to are detached from.
+func (t *Tracer) Stop() {
+	if !atomic.CompareAndSwapInt32(&t.stopped, 0, 1) {
+		return
+	}
+	t.interrupt(syscall.SIGINT)
+}
 
-	// intercept syscall
-	err := syscall.PtraceSyscall(t.pid, t.lastSignal)
-	if err != nil {
-		return fmt.Errorf("could not intercept syscall: %w", err)
+func (t *Tracer) interrupt(sig syscall.Signal) {
+	t.receivedSignal = sig
+	_ = syscall.Kill(t.pid, syscall.SIGSTOP)
+}
+
+// killAll kills every tracee. Their exits are reported as usual.
+func (t *Tracer) killAll() {
+	t.killed = true
+	for tid := range t.tracees {
+		_ = unix.Tgkill(t.process(tid).Pid, tid, unix.SIGKILL)
 	}
+}
 
-	// wait for a syscall
-	status := syscall.WaitStatus(0)
-	_, err = syscall.Wait4(t.pid, &status, 0, nil)
-	if err != nil {
-		return fmt.Errorf("wait failed: %w", err)
+// handleStop handles a single state change of a tracee, and resumes it until the next syscall or signal
+func (t *Tracer) handleStop(tid int, status syscall.WaitStatus) error {
+
+	state, ok := t.tracees[tid]
+	if !ok {
+		// new children can report their initial stop before their parent reports the fork
+		state = &tracee{tgid: threadGroup(tid)}
+		t.tracees[tid] = state
+	}
+
+	if status.Exited() || status.Signaled() {
+		delete(t.tracees, tid)
+		exit := &Exit{Pid: tid, Tgid: state.tgid, Time: time.Now()}
+		if status.Signaled() {
+			exit.Signal = status.Signal()
+		} else {
+			exit.Status = status.ExitStatus()
+		}
+		t.handleExit(exit)
+		return nil
 	}
 
-	if status.TrapCause() != -1 {
+	if !status.Stopped() {
 		return nil
 	}
 
-	if status.Exited() {
-		if t.handlers.processExit != nil {
-			t.handlers.processExit(status.ExitStatus())
+	deliver := 0
+	switch sig := status.StopSignal(); {
+	case sig == syscall.SIGTRAP|0x80:
+		now := time.Now()
+		regs := &syscall.PtraceRegs{}
+		if err := syscall.PtraceGetRegs(tid, regs); err != nil {
+			if err == syscall.ESRCH {
+				// killed whilst stopped - we'll see the exit next
+				return nil
+			}
+			return fmt.Errorf("failed to read registers: %w", err)
+		}
+		if err := t.handleSyscall(tid, regs, now); err != nil {
+			return err
 		}
-		return errExited
+	case status.TrapCause() > 0:
+		t.handleEvent(tid, status.TrapCause())
+	case sig == syscall.SIGSTOP && !state.started:
+		// the initial stop of a new child, which is not a real signal
+	case sig == syscall.SIGSTOP && t.receivedSignal != 0:
+		// we stopped the tracee ourselves, so leave it stopped
+		state.stopped = true
+		return nil
+	default:
+		info, err := getSignalInfo(tid)
+		if err != nil {
+			// group-stop: there is no signal to deliver
+			break
+		}
+		t.handleSignal(tid, info)
+		deliver = int(sig)
 	}
+	state.started = true
 
-	if status.StopSignal() != syscall.SIGTRAP|0x80 {
+	if err := syscall.PtraceSyscall(tid, deliver); err != nil && err != syscall.ESRCH {
+		return fmt.Errorf("could not intercept syscall: %w", err)
+	}
+	return nil
+}
 
-		if t.handlers.signal != nil {
-			info, err := getSignalInfo(t.pid)
-			if err != nil {
-				return err
+// handleEvent handles a PTRACE_EVENT stop
+func (t *Tracer) handleEvent(tid int, event int) {
+	msg, err := syscall.PtraceGetEventMsg(tid)
+	if err != nil {
+		return
+	}
+	switch event {
+	case syscall.PTRACE_EVENT_FORK, syscall.PTRACE_EVENT_VFORK, syscall.PTRACE_EVENT_CLONE:
+		child := int(msg)
+		if _, ok := t.tracees[child]; !ok {
+			t.tracees[child] = &tracee{tgid: threadGroup(child)}
+		}
+	case syscall.PTRACE_EVENT_EXEC:
+		// when a thread other than the leader execs, it takes over the thread group id
+		if former := int(msg); former != tid {
+			if state, ok := t.tracees[former]; ok {
+				t.tracees[tid] = state
+				delete(t.tracees, former)
 			}
-			t.handlers.signal(info)
+			t.refreshProcess(former)
 		}
+	}
+}
 
-		if sig := status.StopSignal(); sig == syscall.SIGSTOP || sig == syscall.SIGTSTP || sig == syscall.SIGTTIN || sig == syscall.SIGTTOU {
-			if t.receivedSignal != 0 {
-				return nil
+// detachAll detaches from every tracee, so that attached processes can continue without us
+func (t *Tracer) detachAll() {
+	for tid, state := range t.tracees {
+		if !state.stopped {
+			// a tracee must be stopped before we can detach from it - if we were interrupted, the main thread has
+			// already been sent a SIGSTOP
+			if tid != t.pid || t.receivedSignal == 0 {
+				if err := unix.Tgkill(t.process(tid).Pid, tid, unix.SIGSTOP); err != nil {
+					continue
+				}
 			}
-			t.lastSignal = int(status.StopSignal())
-		} else if t.lastSignal != 0 {
-			if status.StopSignal() == syscall.SIGCONT {
-				t.lastSignal = 0
-				return nil
+			if !t.waitForStop(tid) {
+				continue
 			}
-			if err = syscall.PtraceSyscall(t.pid, t.lastSignal); err != nil && err != syscall.ESRCH {
-				return err
-			}
-			return nil
 		}
-		return nil
+		// detaching without a signal discards the SIGSTOP, so the tracee continues as if nothing happened
+		_ = syscall.PtraceDetach(tid)
 	}
+}
 
-	// read registers
-	regs := &syscall.PtraceRegs{}
-	if err := syscall.PtraceGetRegs(t.pid, regs); err != nil {
-		return fmt.Errorf("failed to read registers: %w", err)
+// waitForStop resumes the given tracee until it reports a SIGSTOP. It returns false if the tracee exited first.
+func (t *Tracer) waitForStop(tid int) bool {
+	for {
+		status := syscall.WaitStatus(0)
+		if _, err := syscall.Wait4(tid, &status, syscall.WALL, nil); err != nil || status.Exited() || status.Signaled() {
+			return false
+		}
+		sig := status.StopSignal()
+		if sig == syscall.SIGSTOP {
+			return true
+		}
+		deliver := 0
+		if sig != syscall.SIGTRAP|0x80 && status.TrapCause() <= 0 {
+			deliver = int(sig)
+		}
+		if err := syscall.PtraceSyscall(tid, deliver); err != nil {
+			return false
+		}
 	}
+}
+
+// threadGroup returns the id of the process the given thread belongs to, or 0 if it cannot be read. It must be read
+// whilst the thread exists, as /proc no longer has it once the thread's exit has been reported.
+func threadGroup(tid int) int {
+	tgid, _, err := readStatus(tid)
+	if err != nil {
+		return 0
+	}
+	return tgid
+}
+
+// listThreads returns the ids of every thread in the given process
+func listThreads(pid int) []int {
+	tids := []int{pid}
+	entries, err := os.ReadDir(fmt.Sprintf("/proc/%d/task", pid))
+	if err != nil {
+		return tids
+	}
+	for _, entry := range entries {
+		if tid, err := strconv.Atoi(entry.Name()); err == nil && tid != pid {
+			tids = append(tids, tid)
+		}
+	}
+	return tids
+}
+
+// tracee returns the state for the given thread, creating it if necessary
+func (t *Tracer) tracee(tid int) *tracee {
+	if t.tracees == nil {
+		t.tracees = make(map[int]*tracee)
+	}
+	state, ok := t.tracees[tid]
+	if !ok {
+		state = &tracee{started: true}
+		t.tracees[tid] = state
+	}
+	return state
+}
+
+func (t *Tracer) handleExit(exit *Exit) {
+	if t.recorder != nil {
+		t.recorder.write(Event{Type: EventProcessExit, Time: exit.Time, Pid: exit.Pid, Tgid: exit.Tgid, Status: exit.Status, ExitSignal: int(exit.Signal)})
+	}
+	for _, handler := range t.handlers.exit {
+		handler(exit)
+	}
+	if exit.Pid == t.pid && exit.Signal == 0 {
+		for _, handler := range t.handlers.processExit {
+			handler(exit.Status)
+		}
+	}
+	// the tid may be reused by a new process, so don't keep information about this one
+	t.refreshProcess(exit.Pid)
+}
+
+func (t *Tracer) handleSignal(pid int, info *SigInfo) {
+	if t.recorder != nil {
+		t.recorder.write(Event{Type: EventSignal, Time: time.Now(), Pid: pid, Signal: info})
+	}
+	for _, handler := range t.handlers.signal {
+		handler(pid, info)
+	}
+}
+
+func (t *Tracer) handleAttach(pid int) {
+	if t.recorder != nil {
+		t.recorder.write(Event{Type: EventAttach, Time: time.Now(), Pid: pid})
+	}
+	for _, handler := range t.handlers.attach {
+		handler(pid)
+	}
+}
+
+func (t *Tracer) handleDetach(pid int) {
+	if t.recorder != nil {
+		t.recorder.write(Event{Type: EventDetach, Time: time.Now(), Pid: pid})
+	}
+	for _, handler := range t.handlers.detach {
+		handler(pid)
+	}
+}
+
+// handleSyscall decodes a syscall from the registers of a tracee, and passes it to the relevant handler
+func (t *Tracer) handleSyscall(tid int, regs *syscall.PtraceRegs, now time.Time) error {
 
 	call := parseSyscall(regs)
-	call.pid = t.pid
+	call.pid = tid
+	call.process = t.process(tid)
+
+	state := t.tracee(tid)
+
+	// rt_sigreturn restores the registers of the interrupted code, which clears the syscall number before the exit stop
+	if call.number == -1 && state.isExit && state.lastCall != nil {
+		call.number = state.lastCall.number
+	}
 
 	if call.number == -1 {
 		return fmt.Errorf("expecting syscall but received -1 - did we miss a signal?")
 	}
 
-	if t.isExit && t.lastCall != nil {
-		if call.number == t.lastCall.number {
-			call.args = t.lastCall.args
-			call.paths = t.lastCall.paths
+	if state.isExit && state.lastCall != nil {
+		if call.number == state.lastCall.number {
+			call.args = state.lastCall.args
+			call.paths = state.lastCall.paths
+			call.entered = state.lastCall.entered
+			call.exited = now
 		} else {
-			return fmt.Errorf("syscall exit mismatch: %d != %d - this is likely a bug in grace due to an unprocessed signal", call.number, t.lastCall.number)
+			return fmt.Errorf("syscall exit mismatch: %d != %d - this is likely a bug in grace due to an unprocessed signal", call.number, state.lastCall.number)
 		}
 	}
 
-	if err := call.populate(t.isExit); err != nil {
+	if !state.isExit {
+		call.entered = now
+	}
+
+	if err := call.populate(state.isExit); err != nil {
 		return fmt.Errorf("populate failed: %w", err)
 	}
 
-	if t.isExit {
-		if t.handlers.syscallExit != nil {
-			t.handlers.syscallExit(call)
-		}
-	} else if t.handlers.syscallEnter != nil {
-		t.handlers.syscallEnter(call)
+	// comm, exe and args are replaced by a successful exec, and cwd by chdir, so make sure we pick up the new values
+	if state.isExit && (isExec(call.number) || isChdir(call.number)) && call.ret.Int() == 0 {
+		t.refreshProcess(call.pid)
+		call.process = t.process(call.pid)
+	}
+
+	handlers := t.handlers.syscallEnter
+	if state.isExit {
+		handlers = t.handlers.syscallExit
+	}
+	for _, handler := range handlers {
+		handler(call)
+	}
+	if t.recorder != nil {
+		t.recorder.write(Event{Type: EventSyscall, Time: now, Pid: tid, Regs: regs})
 	}
-	t.lastCall = call
-	t.isExit = !t.isExit
+	state.lastCall = call
+	state.isExit = !state.isExit
 	return nil
 }
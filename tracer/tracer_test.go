@@ -0,0 +1,29 @@
+package tracer
+
+import (
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_HandlersAreChained(t *testing.T) {
+	tracer := New(100)
+
+	var calls []string
+	tracer.AddExitHandler(func(exit *Exit) { calls = append(calls, "first exit") })
+	tracer.AddExitHandler(func(exit *Exit) { calls = append(calls, "second exit") })
+	tracer.AddProcessExitHandler(func(status int) { calls = append(calls, "process exit") })
+	tracer.AddDetachHandler(func(pid int) { calls = append(calls, "first detach") })
+	tracer.AddDetachHandler(func(pid int) { calls = append(calls, "second detach") })
+
+	tracer.handleExit(&Exit{Pid: 100})
+	tracer.handleDetach(100)
+	assert.Equal(t, []string{"first exit", "second exit", "process exit", "first detach", "second detach"}, calls)
+
+	// setting a handler replaces any which were added
+	calls = nil
+	tracer.SetExitHandler(func(exit *Exit) { calls = append(calls, "replacement exit") })
+	tracer.handleExit(&Exit{Pid: 101, Signal: syscall.SIGKILL})
+	assert.Equal(t, []string{"replacement exit"}, calls)
+}
@@ -79,6 +79,26 @@ const (
 	argEndInternal
 )
 
+var publicTypeNames = map[ArgType]string{
+	ArgTypeUnknown:      "unknown",
+	ArgTypeData:         "data",
+	ArgTypeInt:          "int",
+	ArgTypeLong:         "long",
+	ArgTypeAddress:      "address",
+	ArgTypeUnsignedInt:  "uint",
+	ArgTypeUnsignedLong: "ulong",
+	ArgTypeObject:       "object",
+	ArgTypeErrorCode:    "errno",
+	ArgTypeArray:        "array",
+}
+
+func (t ArgType) String() string {
+	if name, ok := publicTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("internal_%d", t)
+}
+
 type typeHandler func(arg *Arg, metadata ArgMetadata, raw, next, prev, ret uintptr, pid int) error
 
 var typesRegistry = map[ArgType]typeHandler{}
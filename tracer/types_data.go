@@ -2,7 +2,6 @@ package tracer
 
 import (
 	"fmt"
-	"syscall"
 )
 
 func init() {
@@ -51,7 +50,7 @@ func readSize(pid int, addr uintptr, size uintptr) ([]byte, error) {
 		return nil, nil
 	}
 	data := make([]byte, size)
-	count, err := syscall.PtracePeekData(pid, addr, data)
+	count, err := peekData(pid, addr, data)
 	if err != nil {
 		return nil, fmt.Errorf("read of 0x%x (%d) failed: %w", addr, size, err)
 	}
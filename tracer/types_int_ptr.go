@@ -7,7 +7,7 @@ import (
 func init() {
 	registerTypeHandler(argTypeUnsignedIntPtr, func(arg *Arg, metadata ArgMetadata, raw, next, prev, ret uintptr, pid int) error {
 		var underlying uint32
-		if buf, err := readSize(pid, next, unsafe.Sizeof(underlying)); err == nil {
+		if buf, err := readSize(pid, raw, unsafe.Sizeof(underlying)); err == nil {
 			arg.raw = uintptr(decodeInt(buf))
 			arg.t = ArgTypeUnsignedInt
 		} else {
@@ -21,7 +21,7 @@ func init() {
 	})
 	registerTypeHandler(argTypeUnsignedInt64Ptr, func(arg *Arg, metadata ArgMetadata, raw, next, prev, ret uintptr, pid int) error {
 		var underlying uint64
-		if buf, err := readSize(pid, next, unsafe.Sizeof(underlying)); err == nil {
+		if buf, err := readSize(pid, raw, unsafe.Sizeof(underlying)); err == nil {
 			arg.raw = uintptr(decodeInt(buf))
 			arg.t = ArgTypeUnsignedInt
 		} else {
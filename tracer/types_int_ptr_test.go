@@ -0,0 +1,47 @@
+package tracer
+
+import (
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_IntPointersAreReadFromTheirOwnAddress(t *testing.T) {
+	memory := map[uintptr][]byte{
+		0x1000: {16, 0, 0, 0},
+		0x2000: {0, 0x10, 0, 0, 0, 0, 0, 0},
+		0x3000: {1, 0, 0, 0, 0, 0, 0, 0},
+	}
+	peekData = func(pid int, addr uintptr, out []byte) (int, error) {
+		data, ok := memory[addr]
+		if !ok {
+			return 0, syscall.EIO
+		}
+		return copy(out, data), nil
+	}
+	defer func() { peekData = syscall.PtracePeekData }()
+
+	tests := []struct {
+		name     string
+		argType  ArgType
+		raw      uintptr
+		next     uintptr
+		wantType ArgType
+		want     uintptr
+	}{
+		{name: "socklen_t", argType: argTypeUnsignedIntPtr, raw: 0x1000, next: 0x3000, wantType: ArgTypeUnsignedInt, want: 16},
+		{name: "loff_t", argType: argTypeUnsignedInt64Ptr, raw: 0x2000, next: 0x3000, wantType: ArgTypeUnsignedInt, want: 0x1000},
+		{name: "NULL socklen_t", argType: argTypeUnsignedIntPtr, next: 0x3000, wantType: ArgTypeAddress},
+		{name: "NULL loff_t", argType: argTypeUnsignedInt64Ptr, next: 0x3000, wantType: ArgTypeAddress},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			arg := &Arg{t: test.argType, raw: test.raw}
+			require.NoError(t, getHandler(test.argType)(arg, ArgMetadata{Type: test.argType}, test.raw, test.next, 0, 0, 1))
+			assert.Equal(t, test.wantType, arg.t)
+			assert.Equal(t, test.want, arg.raw)
+		})
+	}
+}
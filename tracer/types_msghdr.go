@@ -105,21 +105,34 @@ func convertMMsghdrs(mmsghdrs []mmsghdr, pid int) []Arg {
 
 func convertMsgHdr(hdr *msghdr, pid int) (*Object, error) {
 
-	rawFamily, err := readSize(pid, hdr.Name, unsafe.Sizeof(syscall.RawSockaddrInet4{}.Family))
-	if err != nil {
-		return nil, err
+	name := Arg{
+		name: "name",
+		t:    ArgTypeAddress,
 	}
 
-	family := decodeInt(rawFamily)
+	// the name is optional, e.g. for connected sockets
+	if hdr.Name != 0 && hdr.Namelen > 0 {
+		rawFamily, err := readSize(pid, hdr.Name, unsafe.Sizeof(syscall.RawSockaddrInet4{}.Family))
+		if err != nil {
+			return nil, err
+		}
 
-	rawSockAddr, err := readSize(pid, hdr.Name, hdr.Namelen)
-	if err != nil {
-		return nil, err
-	}
+		family := decodeInt(rawFamily)
 
-	obj, err := convertSockAddr(family, rawSockAddr)
-	if err != nil {
-		return nil, err
+		rawSockAddr, err := readSize(pid, hdr.Name, hdr.Namelen)
+		if err != nil {
+			return nil, err
+		}
+
+		obj, err := convertSockAddr(family, rawSockAddr)
+		if err != nil {
+			return nil, err
+		}
+		name.t = ArgTypeObject
+		name.obj = obj
+	} else {
+		name.annotation = "NULL"
+		name.replace = true
 	}
 
 	iovecBytes, err := readSize(pid, hdr.Iov, unsafe.Sizeof(iovec{})*hdr.Iovlen)
@@ -137,14 +150,29 @@ func convertMsgHdr(hdr *msghdr, pid int) (*Object, error) {
 		return nil, err
 	}
 
-	controlBytes, err := readSize(pid, hdr.Control, hdr.Controllen)
-	if err != nil {
-		return nil, err
+	control := Arg{
+		name:       "control",
+		t:          ArgTypeAddress,
+		annotation: "NULL",
+		replace:    true,
 	}
 
-	var control unix.Cmsghdr
-	if err := decodeStruct(controlBytes, &control); err != nil {
-		return nil, err
+	// ancillary data is optional too
+	if hdr.Control != 0 && hdr.Controllen >= unsafe.Sizeof(unix.Cmsghdr{}) {
+		controlBytes, err := readSize(pid, hdr.Control, hdr.Controllen)
+		if err != nil {
+			return nil, err
+		}
+
+		var cmsghdr unix.Cmsghdr
+		if err := decodeStruct(controlBytes, &cmsghdr); err != nil {
+			return nil, err
+		}
+		control = Arg{
+			name: "control",
+			t:    ArgTypeObject,
+			obj:  convertCmsghdr(cmsghdr, pid),
+		}
 	}
 
 	flags := Arg{
@@ -157,21 +185,13 @@ func convertMsgHdr(hdr *msghdr, pid int) (*Object, error) {
 	return &Object{
 		Name: "msghdr",
 		Properties: []Arg{
-			{
-				name: "name",
-				t:    ArgTypeObject,
-				obj:  obj,
-			},
+			name,
 			{
 				name:  "iovec",
 				t:     ArgTypeArray,
 				array: vecs,
 			},
-			{
-				name: "control",
-				t:    ArgTypeObject,
-				obj:  convertCmsghdr(control, pid),
-			},
+			control,
 			flags,
 		},
 	}, nil
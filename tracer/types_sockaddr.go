@@ -2,6 +2,7 @@ package tracer
 
 import (
 	"fmt"
+	"net"
 	"syscall"
 	"unsafe"
 
@@ -89,7 +90,7 @@ func convertSockAddr(family int64, raw []byte) (*Object, error) {
 				{
 					name: "addr",
 					t:    ArgTypeData,
-					data: []byte(fmt.Sprintf("%x:%x:%x:%x:%x:%x:%x:%x", target.Addr[0], target.Addr[1], target.Addr[2], target.Addr[3], target.Addr[4], target.Addr[5], target.Addr[6], target.Addr[7])),
+					data: []byte(net.IP(target.Addr[:]).String()),
 				},
 				{
 					name: "flowinfo",
@@ -2,7 +2,6 @@ package tracer
 
 import (
 	"fmt"
-	"syscall"
 	"unsafe"
 )
 
@@ -59,7 +58,7 @@ func readString(pid int, addr uintptr) (string, error) {
 	}
 	data := make([]byte, 1)
 	for {
-		if _, err := syscall.PtracePeekData(pid, addr, data); err != nil {
+		if _, err := peekData(pid, addr, data); err != nil {
 			return "", fmt.Errorf("read of 0x%x failed: %w", addr, err)
 		}
 		if data[0] == 0 {
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"io"
+	"time"
+
+	"github.com/liamg/grace/proctree"
+	"github.com/liamg/grace/tracer"
+)
+
+// configureTree sets up a process tree which is printed live as processes start, exec and exit, and again in full
+// when the tracer detaches
+func configureTree(t *tracer.Tracer, w io.Writer, started time.Time, useColours bool) {
+
+	tree := proctree.New(w)
+	tree.SetUseColours(useColours)
+	tree.SetLive(true)
+	tree.SetStartTime(started)
+	if !flagVerbose {
+		tree.SetMaxArgsLen(flagMaxStringLen * 8)
+	}
+
+	t.AddSyscallEnterHandler(tree.HandleSyscallEnter)
+	t.AddSyscallExitHandler(tree.HandleSyscallExit)
+	t.AddExitHandler(tree.HandleExit)
+	t.AddDetachHandler(func(int) {
+		tree.Print()
+	})
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/liamg/grace/tracer"
+	"github.com/liamg/grace/tui"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var tuiCmd = &cobra.Command{
+	Use:     "tui [flags] [command [args]]",
+	Example: `grace tui -- make -j8`,
+	Short:   "Trace a command or process in a full-screen interactive interface",
+	Args:    cobra.ArbitraryArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+
+		if len(args) == 0 && flagPID == 0 {
+			return cmd.Help()
+		}
+		if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+			return fmt.Errorf("the interactive interface requires a terminal")
+		}
+
+		title := strings.Join(args, " ")
+		if flagPID > 0 {
+			title = strings.Join(tracer.CommandLine(flagPID), " ")
+		}
+		app := tui.New(title)
+		if err := app.SetFilter(flagFilter); err != nil {
+			return fmt.Errorf("failed to parse filter: %w", err)
+		}
+
+		// the traced command can't share our terminal, as its output would corrupt the interface and it would
+		// compete with us for key presses, so its input is /dev/null and its output is discarded
+		flagForwardIO = false
+
+		// the tracer must run on a single OS thread, so it gets a goroutine of its own
+		created := make(chan *tracer.Tracer, 1)
+		createErr := make(chan error, 1)
+		traced := make(chan error, 1)
+		go func() {
+			runtime.LockOSThread()
+			t, err := createTracer(args, nil)
+			if err != nil {
+				createErr <- err
+				return
+			}
+			t.SetSyscallExitHandler(app.HandleSyscallExit)
+			t.SetSignalHandler(app.HandleSignal)
+			t.SetExitHandler(app.HandleExit)
+			t.SetAttachHandler(app.HandleAttach)
+			t.SetDetachHandler(app.HandleDetach)
+			created <- t
+			traced <- t.Start()
+		}()
+		var t *tracer.Tracer
+		select {
+		case t = <-created:
+		case err := <-createErr:
+			return err
+		}
+
+		err := app.Run(os.Stdin, os.Stdout, t.Stop)
+		// the trace is stopped even if the interface failed, so that a command we started is killed and a process we
+		// attached to is detached from
+		t.Stop()
+		if traceErr := <-traced; err == nil {
+			err = traceErr
+		}
+		return err
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tuiCmd)
+}
@@ -0,0 +1,145 @@
+package tui
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// stripANSI removes escape sequences from a string
+func stripANSI(s string) string {
+	var out strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\x1b' {
+			i = skipEscape(s, i)
+			continue
+		}
+		out.WriteByte(s[i])
+	}
+	return out.String()
+}
+
+// truncateANSI truncates a string to the given number of visible characters, keeping any escape sequences intact
+func truncateANSI(s string, width int) string {
+	var out strings.Builder
+	visible := 0
+	for i := 0; i < len(s); {
+		if s[i] == '\x1b' {
+			end := skipEscape(s, i)
+			out.WriteString(s[i : end+1])
+			i = end + 1
+			continue
+		}
+		if visible == width {
+			// keep going, so that colour resets etc. after the cut-off point are still applied
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == '\t' {
+			r, size = ' ', 1
+		}
+		out.WriteRune(r)
+		visible++
+		i += size
+	}
+	return out.String()
+}
+
+// skipEscape returns the index of the final byte of the escape sequence which starts at index i
+func skipEscape(s string, i int) int {
+	if i+1 < len(s) && s[i+1] == '[' {
+		for j := i + 2; j < len(s); j++ {
+			if s[j] >= 0x40 && s[j] <= 0x7e {
+				return j
+			}
+		}
+		return len(s) - 1
+	}
+	return i
+}
+
+// key is a single key press
+type key string
+
+const (
+	keyUp       key = "up"
+	keyDown     key = "down"
+	keyPageUp   key = "pgup"
+	keyPageDown key = "pgdn"
+	keyHome     key = "home"
+	keyEnd      key = "end"
+	keyEnter    key = "enter"
+	keyEscape   key = "esc"
+	keyBack     key = "backspace"
+	keyTab      key = "tab"
+	keyBackTab  key = "backtab"
+	keyCtrlC    key = "ctrl-c"
+)
+
+var escapeSequences = map[string]key{
+	"\x1b[A":  keyUp,
+	"\x1b[B":  keyDown,
+	"\x1b[5~": keyPageUp,
+	"\x1b[6~": keyPageDown,
+	"\x1b[H":  keyHome,
+	"\x1b[1~": keyHome,
+	"\x1b[F":  keyEnd,
+	"\x1b[4~": keyEnd,
+	"\x1b[Z":  keyBackTab,
+	"\x1bOA":  keyUp,
+	"\x1bOB":  keyDown,
+	"\x1bOH":  keyHome,
+	"\x1bOF":  keyEnd,
+}
+
+// decodeKeys splits raw terminal input into key presses. Printable characters are returned as themselves.
+func decodeKeys(input []byte) []key {
+	var keys []key
+	s := string(input)
+	for len(s) > 0 {
+		if s[0] == '\x1b' {
+			if len(s) == 1 {
+				keys = append(keys, keyEscape)
+				break
+			}
+			matched := false
+			for seq, k := range escapeSequences {
+				if strings.HasPrefix(s, seq) {
+					keys = append(keys, k)
+					s = s[len(seq):]
+					matched = true
+					break
+				}
+			}
+			switch {
+			case matched:
+			case s[1] == '[':
+				// unknown sequence - skip it
+				s = s[skipEscape(s, 0)+1:]
+			default:
+				keys = append(keys, keyEscape)
+				s = s[1:]
+			}
+			continue
+		}
+		switch s[0] {
+		case '\r', '\n':
+			keys = append(keys, keyEnter)
+		case 0x7f, 0x08:
+			keys = append(keys, keyBack)
+		case '\t':
+			keys = append(keys, keyTab)
+		case 0x03:
+			keys = append(keys, keyCtrlC)
+		default:
+			r, size := utf8.DecodeRuneInString(s)
+			if r >= 0x20 {
+				keys = append(keys, key(string(r)))
+			}
+			s = s[size:]
+			continue
+		}
+		s = s[1:]
+	}
+	return keys
+}
@@ -0,0 +1,51 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruncateANSI(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		width int
+		want  string
+	}{
+		{name: "plain", input: "openat(...)", width: 6, want: "openat"},
+		{name: "short", input: "read", width: 10, want: "read"},
+		{name: "colours kept", input: "\x1b[32mopenat\x1b[0m(...)", width: 4, want: "\x1b[32mopen\x1b[0m"},
+		{name: "multibyte", input: "│ab", width: 2, want: "│a"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, truncateANSI(test.input, test.width))
+		})
+	}
+}
+
+func TestStripANSI(t *testing.T) {
+	assert.Equal(t, "close(3) = 0", stripANSI("\x1b[0mclose\x1b[2m(\x1b[0m3\x1b[2m)\x1b[0m = \x1b[32m0\x1b[0m"))
+}
+
+func TestDecodeKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []key
+	}{
+		{name: "characters", input: "q/", want: []key{"q", "/"}},
+		{name: "arrows", input: "\x1b[A\x1b[B", want: []key{keyUp, keyDown}},
+		{name: "page keys", input: "\x1b[5~\x1b[6~", want: []key{keyPageUp, keyPageDown}},
+		{name: "escape", input: "\x1b", want: []key{keyEscape}},
+		{name: "enter and backspace", input: "\r\x7f", want: []key{keyEnter, keyBack}},
+		{name: "unknown sequence", input: "\x1b[15~x", want: []key{"x"}},
+		{name: "ctrl-c", input: "\x03", want: []key{keyCtrlC}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, decodeKeys([]byte(test.input)))
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/liamg/grace/filter"
+	"golang.org/x/term"
+)
+
+// refreshInterval is how often the screen is redrawn to show new events
+const refreshInterval = 100 * time.Millisecond
+
+// App is a full-screen interface for a live trace
+type App struct {
+	store
+	title string
+
+	filter           *filter.Filter
+	filterText       string
+	filterGeneration int
+
+	paused      bool
+	pausedCount int // the number of events received (including dropped ones) when the list was paused
+	follow      bool
+	selected    *entry
+	top         int
+	pidView     int // 0 shows every process
+	showDetail  bool
+	showSummary bool
+	scroll      int // scroll position of the detail pane or summary table
+	editing     bool
+	editBuffer  string
+	message     string
+
+	detailFor   *entry
+	detailLines []string
+
+	view           []*entry // the entries shown in the list, which is extended as events arrive
+	viewPid        int      // the pid view the list was built for
+	viewGeneration int      // the filter generation the list was built for
+	viewed         int      // the number of events received (including dropped ones) which the list has considered
+}
+
+// New creates an interface with the given title, which is usually the traced command
+func New(title string) *App {
+	return &App{
+		title:  title,
+		follow: true,
+	}
+}
+
+// SetFilter sets the filter for the event list, using the same syntax as --filter
+func (a *App) SetFilter(input string) error {
+	if input == "" {
+		a.filter = nil
+		a.filterText = ""
+		a.filterGeneration++
+		return nil
+	}
+	f, err := filter.Parse(input)
+	if err != nil {
+		return err
+	}
+	a.filter = f
+	a.filterText = input
+	a.filterGeneration++
+	return nil
+}
+
+// Run takes over the terminal until the user quits, at which point stop is called
+func (a *App) Run(in *os.File, out *os.File, stop func()) error {
+	state, err := term.MakeRaw(int(in.Fd()))
+	if err != nil {
+		return fmt.Errorf("failed to set up terminal: %w", err)
+	}
+	defer func() { _ = term.Restore(int(in.Fd()), state) }()
+
+	// switch to the alternate screen, and hide the cursor
+	_, _ = fmt.Fprint(out, "\x1b[?1049h\x1b[?25l")
+	defer func() { _, _ = fmt.Fprint(out, "\x1b[?25h\x1b[?1049l") }()
+
+	input := make(chan []byte)
+	go func() {
+		buf := make([]byte, 256)
+		for {
+			n, err := in.Read(buf)
+			if err != nil {
+				close(input)
+				return
+			}
+			data := make([]byte, n)
+			copy(data, buf[:n])
+			input <- data
+		}
+	}()
+
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		width, height, err := term.GetSize(int(out.Fd()))
+		if err != nil {
+			width, height = 80, 24
+		}
+		a.draw(out, width, height)
+
+		select {
+		case data, ok := <-input:
+			if !ok {
+				stop()
+				return nil
+			}
+			for _, k := range decodeKeys(data) {
+				if a.handleKey(k, height) {
+					stop()
+					return nil
+				}
+			}
+		case <-ticker.C:
+		}
+	}
+}
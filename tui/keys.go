@@ -0,0 +1,177 @@
+package tui
+
+import "fmt"
+
+// handleKey updates the state of the interface for a key press, and returns true if the user wants to quit
+func (a *App) handleKey(k key, height int) bool {
+	if a.editing {
+		a.handleEditKey(k)
+		return false
+	}
+
+	a.message = ""
+
+	a.store.mu.Lock()
+	defer a.store.mu.Unlock()
+
+	visible := a.visible()
+	index := a.selectedIndex(visible)
+	page := height - 3
+	if page < 1 {
+		page = 1
+	}
+
+	switch k {
+	case "q", keyCtrlC:
+		return true
+	case " ", "p":
+		a.paused = !a.paused
+		if a.paused {
+			a.pausedCount = a.store.dropped + a.store.len()
+		}
+	case keyUp, "k":
+		a.moveTo(visible, index-1)
+	case keyDown, "j":
+		a.moveTo(visible, index+1)
+	case keyPageUp:
+		a.moveTo(visible, index-page)
+	case keyPageDown:
+		a.moveTo(visible, index+page)
+	case keyHome, "g":
+		a.moveTo(visible, 0)
+	case keyEnd, "G":
+		a.moveTo(visible, len(visible)-1)
+	case keyEnter, "d":
+		a.showDetail = !a.showDetail
+		a.scroll = 0
+	case "K":
+		if a.scroll > 0 {
+			a.scroll--
+		}
+	case "J":
+		a.scroll++
+	case "s":
+		a.showSummary = !a.showSummary
+		a.scroll = 0
+	case "/":
+		a.editing = true
+		a.editBuffer = a.filterText
+	case keyTab:
+		a.cyclePid(1)
+	case keyBackTab:
+		a.cyclePid(-1)
+	}
+	return false
+}
+
+func (a *App) handleEditKey(k key) {
+	switch k {
+	case keyEnter:
+		a.editing = false
+		if err := a.SetFilter(a.editBuffer); err != nil {
+			a.message = fmt.Sprintf("invalid filter: %s", err)
+		}
+	case keyEscape, keyCtrlC:
+		a.editing = false
+	case keyBack:
+		if len(a.editBuffer) > 0 {
+			runes := []rune(a.editBuffer)
+			a.editBuffer = string(runes[:len(runes)-1])
+		}
+	default:
+		if len([]rune(string(k))) == 1 {
+			a.editBuffer += string(k)
+		}
+	}
+}
+
+// moveTo selects the entry at the given index of the visible list. The list follows new events if the last entry
+// is selected.
+func (a *App) moveTo(visible []*entry, index int) {
+	if len(visible) == 0 {
+		return
+	}
+	if index < 0 {
+		index = 0
+	}
+	if index >= len(visible)-1 {
+		index = len(visible) - 1
+		a.follow = true
+	} else {
+		a.follow = false
+	}
+	a.selected = visible[index]
+}
+
+// cyclePid switches between showing every process and showing each process individually
+func (a *App) cyclePid(direction int) {
+	options := append([]int{0}, a.store.pids...)
+	current := 0
+	for i, pid := range options {
+		if pid == a.pidView {
+			current = i
+		}
+	}
+	a.pidView = options[(current+direction+len(options))%len(options)]
+	a.follow = true
+}
+
+// visible returns the entries which should be shown in the list - the store must be locked. The list is only rebuilt
+// when the filter or pid view changes, and is otherwise updated with the events received since it was last used.
+func (a *App) visible() []*entry {
+	if a.viewPid != a.pidView || a.viewGeneration != a.filterGeneration {
+		a.view = nil
+		a.viewed = a.store.dropped
+		a.viewPid = a.pidView
+		a.viewGeneration = a.filterGeneration
+	}
+
+	// forget entries which have been discarded by the store
+	var discarded int
+	for discarded < len(a.view) && a.view[discarded].seq < a.store.dropped {
+		discarded++
+	}
+	a.view = a.view[discarded:]
+	if a.viewed < a.store.dropped {
+		a.viewed = a.store.dropped
+	}
+
+	limit := a.store.dropped + a.store.len()
+	if a.paused && a.pausedCount < limit {
+		limit = a.pausedCount
+	}
+	for ; a.viewed < limit; a.viewed++ {
+		e := a.store.at(a.viewed - a.store.dropped)
+		if a.pidView != 0 && e.pid != a.pidView {
+			continue
+		}
+		if !a.match(e) {
+			continue
+		}
+		a.view = append(a.view, e)
+	}
+	return a.view
+}
+
+func (a *App) match(e *entry) bool {
+	if a.filter == nil || e.call == nil {
+		return true
+	}
+	if e.filterGeneration != a.filterGeneration {
+		e.matched = a.filter.Match(e.call, true)
+		e.filterGeneration = a.filterGeneration
+	}
+	return e.matched
+}
+
+// selectedIndex returns the index of the selected entry in the visible list
+func (a *App) selectedIndex(visible []*entry) int {
+	if !a.follow && a.selected != nil {
+		for i, e := range visible {
+			if e == a.selected {
+				return i
+			}
+		}
+	}
+	return len(visible) - 1
+}
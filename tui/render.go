@@ -0,0 +1,245 @@
+package tui
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/liamg/grace/printer"
+	"github.com/liamg/grace/tracer/annotation"
+)
+
+var pidColours = []printer.Colour{
+	printer.ColourCyan,
+	printer.ColourYellow,
+	printer.ColourMagenta,
+	printer.ColourGreen,
+	printer.ColourBlue,
+	printer.ColourRed,
+}
+
+const pidColumnWidth = 8
+
+// draw renders the whole screen
+func (a *App) draw(w io.Writer, width, height int) {
+	if height < 3 {
+		return
+	}
+
+	a.store.mu.Lock()
+	defer a.store.mu.Unlock()
+
+	visible := a.visible()
+
+	var lines []string
+	lines = append(lines, a.header(len(visible), width))
+
+	bodyHeight := height - 2
+	switch {
+	case a.showSummary:
+		lines = append(lines, a.summary(visible, width, bodyHeight)...)
+	case a.showDetail:
+		detailHeight := bodyHeight * 2 / 5
+		listHeight := bodyHeight - detailHeight - 1
+		lines = append(lines, a.list(visible, width, listHeight)...)
+		lines = append(lines, dim(strings.Repeat("─", width)))
+		lines = append(lines, a.detail(visible, width, detailHeight)...)
+	default:
+		lines = append(lines, a.list(visible, width, bodyHeight)...)
+	}
+	for len(lines) < height-1 {
+		lines = append(lines, "")
+	}
+	lines = append(lines[:height-1], a.footer())
+
+	buffer := bytes.NewBuffer(nil)
+	for i, line := range lines {
+		fmt.Fprintf(buffer, "\x1b[%d;1H\x1b[2K%s\x1b[0m", i+1, truncateANSI(line, width))
+	}
+	_, _ = w.Write(buffer.Bytes())
+}
+
+func (a *App) header(count int, width int) string {
+	state := "LIVE"
+	switch {
+	case a.paused:
+		state = "PAUSED"
+	case a.store.done:
+		state = "FINISHED"
+	}
+	view := "all"
+	if a.pidView != 0 {
+		view = fmt.Sprintf("%d", a.pidView)
+	}
+	text := fmt.Sprintf(" grace: %s │ %s │ events: %d │ pid: %s", a.title, state, count, view)
+	if a.filterText != "" {
+		text += fmt.Sprintf(" │ filter: %s", a.filterText)
+	}
+	return reverse(text + strings.Repeat(" ", width))
+}
+
+func (a *App) footer() string {
+	if a.editing {
+		return fmt.Sprintf(" filter: %s\x1b[7m \x1b[0m", a.editBuffer)
+	}
+	if a.message != "" {
+		return colour(printer.ColourRed, " "+a.message)
+	}
+	return dim(" q quit │ space pause │ ↑↓ pgup pgdn home end scroll │ enter details │ J/K scroll details │ / filter │ tab process │ s summary")
+}
+
+func (a *App) list(visible []*entry, width, height int) []string {
+	if height <= 0 {
+		return nil
+	}
+	index := a.selectedIndex(visible)
+
+	if a.follow {
+		a.top = len(visible) - height
+	}
+	if index < a.top {
+		a.top = index
+	}
+	if index >= a.top+height {
+		a.top = index - height + 1
+	}
+	if a.top > len(visible)-height {
+		a.top = len(visible) - height
+	}
+	if a.top < 0 {
+		a.top = 0
+	}
+
+	var lines []string
+	for i := a.top; i < len(visible) && i < a.top+height; i++ {
+		e := visible[i]
+		pid := fmt.Sprintf("%-*d", pidColumnWidth, e.pid)
+		if i == index {
+			text := pid + stripANSI(e.line)
+			lines = append(lines, reverse(text+strings.Repeat(" ", width)))
+			continue
+		}
+		lines = append(lines, colour(a.pidColour(e.pid), pid)+e.line)
+	}
+	return lines
+}
+
+func (a *App) pidColour(pid int) printer.Colour {
+	if i, ok := a.store.pidIndex[pid]; ok {
+		return pidColours[i%len(pidColours)]
+	}
+	return printer.ColourDefault
+}
+
+func (a *App) detail(visible []*entry, width, height int) []string {
+	index := a.selectedIndex(visible)
+	if index < 0 || height <= 0 {
+		return nil
+	}
+	e := visible[index]
+	if e.call == nil {
+		return []string{e.line}
+	}
+
+	if a.detailFor != e {
+		proc := e.call.Process()
+		a.detailLines = []string{
+			dim(fmt.Sprintf("pid %d │ tid %d │ comm %s │ exe %s │ duration %s", proc.Pid, proc.Tid, proc.Comm, proc.Exe, e.call.Duration())),
+		}
+		if errno := e.call.Errno(); errno != 0 {
+			a.detailLines = append(a.detailLines, colour(printer.ColourRed, fmt.Sprintf("failed with %s", annotation.ErrNoToString(errno))))
+		}
+		a.detailLines = append(a.detailLines, formatDetail(e.call)...)
+		a.detailFor = e
+	}
+
+	if a.scroll > len(a.detailLines)-height {
+		a.scroll = len(a.detailLines) - height
+	}
+	if a.scroll < 0 {
+		a.scroll = 0
+	}
+	end := a.scroll + height
+	if end > len(a.detailLines) {
+		end = len(a.detailLines)
+	}
+	return a.detailLines[a.scroll:end]
+}
+
+type summaryRow struct {
+	name   string
+	calls  int
+	errors int
+	total  time.Duration
+}
+
+// summary renders a table of syscall counts and timings for the visible entries
+func (a *App) summary(visible []*entry, width, height int) []string {
+	rows := make(map[string]*summaryRow)
+	var total time.Duration
+	for _, e := range visible {
+		if e.call == nil {
+			continue
+		}
+		row, ok := rows[e.call.Name()]
+		if !ok {
+			row = &summaryRow{name: e.call.Name()}
+			rows[row.name] = row
+		}
+		row.calls++
+		if e.call.Errno() != 0 {
+			row.errors++
+		}
+		row.total += e.call.Duration()
+		total += e.call.Duration()
+	}
+
+	sorted := make([]*summaryRow, 0, len(rows))
+	for _, row := range rows {
+		sorted = append(sorted, row)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].total == sorted[j].total {
+			return sorted[i].name < sorted[j].name
+		}
+		return sorted[i].total > sorted[j].total
+	})
+
+	lines := []string{
+		reverse(fmt.Sprintf(" %-24s %10s %10s %14s %14s %8s", "syscall", "calls", "errors", "total", "avg", "% time") + strings.Repeat(" ", width)),
+	}
+	if a.scroll > len(sorted)-(height-1) {
+		a.scroll = len(sorted) - (height - 1)
+	}
+	if a.scroll < 0 {
+		a.scroll = 0
+	}
+	for i := a.scroll; i < len(sorted) && len(lines) < height; i++ {
+		row := sorted[i]
+		var percent float64
+		if total > 0 {
+			percent = float64(row.total) * 100 / float64(total)
+		}
+		errors := fmt.Sprintf("%10d", row.errors)
+		if row.errors > 0 {
+			errors = colour(printer.ColourRed, errors)
+		}
+		lines = append(lines, fmt.Sprintf(" %-24s %10d %s %14s %14s %7.2f%%", row.name, row.calls, errors, row.total, row.total/time.Duration(row.calls), percent))
+	}
+	return lines
+}
+
+func colour(c printer.Colour, text string) string {
+	return fmt.Sprintf("\x1b[%dm%s\x1b[0m", c, text)
+}
+
+func dim(text string) string {
+	return colour(printer.ColourDim, text)
+}
+
+func reverse(text string) string {
+	return colour(7, text)
+}
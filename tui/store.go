@@ -0,0 +1,158 @@
+package tui
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+
+	"github.com/liamg/grace/printer"
+	"github.com/liamg/grace/tracer"
+)
+
+// maxEntries is the number of events kept for scrollback - older events are discarded
+const maxEntries = 100000
+
+// entry is a single event in the list
+type entry struct {
+	seq  int // the number of events received before this one
+	pid  int
+	tid  int
+	call *tracer.Syscall // nil for events other than syscalls
+	line string          // the event formatted on a single line, with colours
+
+	// the result of the current filter, which is cached as some filters are stateful
+	filterGeneration int
+	matched          bool
+}
+
+// store holds the events received from the tracer, which runs on a separate goroutine
+type store struct {
+	mu       sync.Mutex
+	entries  []*entry    // a ring buffer, which is full once it holds maxEntries
+	head     int         // index of the oldest entry once the ring buffer is full
+	dropped  int         // number of entries discarded from the start of the list
+	pids     []int       // in the order they were first seen
+	pidIndex map[int]int // the index of each pid in pids
+	pid      int         // the pid of the tracee, for events which don't carry one
+	threads  map[int]int // the pid of each tid which has made a syscall
+	done     bool
+}
+
+func (s *store) add(e *entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e.seq = s.dropped + len(s.entries)
+	if len(s.entries) == maxEntries {
+		s.entries[s.head] = e
+		s.head = (s.head + 1) % maxEntries
+		s.dropped++
+	} else {
+		s.entries = append(s.entries, e)
+	}
+	if s.pidIndex == nil {
+		s.pidIndex = make(map[int]int)
+	}
+	if _, ok := s.pidIndex[e.pid]; !ok {
+		s.pidIndex[e.pid] = len(s.pids)
+		s.pids = append(s.pids, e.pid)
+	}
+}
+
+// len returns the number of entries held - the store must be locked
+func (s *store) len() int {
+	return len(s.entries)
+}
+
+// at returns the entry at the given index, where zero is the oldest entry held - the store must be locked
+func (s *store) at(i int) *entry {
+	return s.entries[(s.head+i)%len(s.entries)]
+}
+
+// HandleSyscallExit adds a completed syscall to the list
+func (s *store) HandleSyscallExit(call *tracer.Syscall) {
+	s.mu.Lock()
+	if s.threads == nil {
+		s.threads = make(map[int]int)
+	}
+	s.threads[call.Tid()] = call.Pid()
+	s.mu.Unlock()
+	s.add(&entry{
+		pid:  call.Pid(),
+		tid:  call.Tid(),
+		call: call,
+		line: formatLine(func(p *printer.Printer) { p.PrintSyscallExit(call) }),
+	})
+}
+
+// HandleSignal adds a signal received by a thread to the list
+func (s *store) HandleSignal(tid int, info *tracer.SigInfo) {
+	s.add(&entry{
+		pid:  s.pidOf(tid),
+		tid:  tid,
+		line: formatLine(func(p *printer.Printer) { p.PrintSignal(tid, info) }),
+	})
+}
+
+// HandleExit adds the exit of a traced process to the list - exits of individual threads are not shown
+func (s *store) HandleExit(exit *tracer.Exit) {
+	s.mu.Lock()
+	pid, ok := s.threads[exit.Pid]
+	s.mu.Unlock()
+	if ok && pid != exit.Pid {
+		return
+	}
+	s.add(&entry{
+		pid:  exit.Pid,
+		tid:  exit.Pid,
+		line: formatLine(func(p *printer.Printer) { p.PrintExit(exit) }),
+	})
+}
+
+// HandleAttach records the pid of the tracee
+func (s *store) HandleAttach(pid int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pid = pid
+}
+
+// HandleDetach marks the trace as finished
+func (s *store) HandleDetach(int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.done = true
+}
+
+// pidOf returns the pid of the given thread, or the pid of the tracee if the thread is unknown
+func (s *store) pidOf(tid int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if pid, ok := s.threads[tid]; ok {
+		return pid
+	}
+	if s.pid != 0 {
+		return s.pid
+	}
+	return tid
+}
+
+// formatLine uses a printer to format an event as a single line
+func formatLine(print func(p *printer.Printer)) string {
+	buffer := bytes.NewBuffer(nil)
+	p := printer.New(buffer)
+	p.SetMaxStringLen(64)
+	p.SetHexDumpLongStrings(false)
+	print(p)
+	return strings.TrimSpace(strings.ReplaceAll(buffer.String(), "\n", " "))
+}
+
+// formatDetail uses a printer to format a syscall with every argument fully expanded
+func formatDetail(call *tracer.Syscall) []string {
+	buffer := bytes.NewBuffer(nil)
+	p := printer.New(buffer)
+	p.SetMultiLine(true)
+	p.SetMaxObjectProperties(0)
+	p.SetMaxStringLen(4096)
+	p.SetHexDumpLongStrings(true)
+	p.PrintSyscallExit(call)
+	return strings.Split(strings.TrimRight(buffer.String(), "\n"), "\n")
+}
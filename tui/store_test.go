@@ -0,0 +1,66 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_StoreDiscardsOldestEntries(t *testing.T) {
+	s := &store{}
+	for i := 0; i < maxEntries+5; i++ {
+		s.add(&entry{pid: 100, tid: i})
+	}
+	require.Equal(t, maxEntries, s.len())
+	assert.Equal(t, 5, s.dropped)
+	for i := 0; i < s.len(); i++ {
+		if !assert.Equal(t, i+5, s.at(i).tid) {
+			break
+		}
+	}
+}
+
+func Test_StorePids(t *testing.T) {
+	s := &store{}
+	for _, pid := range []int{300, 100, 300, 200, 100} {
+		s.add(&entry{pid: pid})
+	}
+	assert.Equal(t, []int{300, 100, 200}, s.pids)
+	assert.Equal(t, map[int]int{300: 0, 100: 1, 200: 2}, s.pidIndex)
+}
+
+func Test_VisibleIsUpdatedAsEventsArrive(t *testing.T) {
+	a := New("test")
+	a.add(&entry{pid: 100, tid: 0})
+	a.add(&entry{pid: 200, tid: 1})
+	require.Len(t, a.visible(), 2)
+
+	a.add(&entry{pid: 100, tid: 2})
+	visible := a.visible()
+	require.Len(t, visible, 3)
+	assert.Equal(t, 2, visible[2].tid)
+
+	a.pidView = 100
+	visible = a.visible()
+	require.Len(t, visible, 2)
+	assert.Equal(t, 0, visible[0].tid)
+	assert.Equal(t, 2, visible[1].tid)
+
+	a.paused = true
+	a.pausedCount = a.dropped + a.len()
+	a.add(&entry{pid: 100, tid: 3})
+	assert.Len(t, a.visible(), 2)
+	a.paused = false
+	assert.Len(t, a.visible(), 3)
+
+	// entries discarded by the store are no longer shown
+	for i := 0; i < maxEntries; i++ {
+		a.add(&entry{pid: 200, tid: 4 + i})
+	}
+	assert.Empty(t, a.visible())
+	a.pidView = 0
+	visible = a.visible()
+	require.Len(t, visible, maxEntries)
+	assert.Equal(t, 4, visible[0].tid)
+}